@@ -6,14 +6,13 @@ import (
 	"companies-service/pkg/db/postgres"
 	"companies-service/pkg/db/redis"
 	"companies-service/pkg/logger"
+	"companies-service/pkg/metric"
+	"companies-service/pkg/tracing"
+	"context"
 	"log"
 	"os"
 
-	"github.com/opentracing/opentracing-go"
-	jaegerlog "github.com/uber/jaeger-client-go/log"
-	"github.com/uber/jaeger-lib/metrics"
-
-	jaegercfg "github.com/uber/jaeger-client-go/config"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 )
 
 // @title Company REST API
@@ -44,44 +43,48 @@ func main() {
 	appLogger.Infof("AppVersion: %s, LogLevel: %s, Mode: %s, SSL: %v",
 		cfg.Server.AppVersion, cfg.Logger.Level, cfg.Server.Mode, cfg.Server.SSL)
 
-	psqlDB, err := postgres.NewPsqlDB(cfg)
+	registry := metric.NewRegistry()
+
+	storeMetrics, err := metric.CreateStoreMetrics(registry, cfg.Metrics.ServiceName)
+	if err != nil {
+		appLogger.Fatalf("CreateStoreMetrics: %s", err)
+	}
+
+	psqlDB, err := postgres.NewPsqlDB(cfg, storeMetrics, appLogger)
 	if err != nil {
 		appLogger.Fatalf("Postgresql init: %s", err)
 	} else {
 		appLogger.Infof("Postgres connected, Status: %#v", psqlDB.Stats())
 	}
-	defer psqlDB.Close()
+	if err = registry.Register(collectors.NewDBStatsCollector(psqlDB.DB,
+		cfg.Postgres.PostgresqlDbname)); err != nil {
+		appLogger.Fatalf("Register postgres stats collector: %s", err)
+	}
 
-	redisClient := redis.NewRedisClient(cfg)
-	defer redisClient.Close()
+	redisClient := redis.NewRedisClient(cfg, storeMetrics, appLogger)
 	appLogger.Info("Redis connected")
-
-	jaegerCfgInstance := jaegercfg.Configuration{
-		ServiceName: cfg.Jaeger.ServiceName,
-		Sampler: &jaegercfg.SamplerConfig{
-			Type:  "const",
-			Param: 1,
-		},
-		Reporter: &jaegercfg.ReporterConfig{
-			LogSpans:           cfg.Jaeger.LogSpans,
-			LocalAgentHostPort: cfg.Jaeger.Host,
-		},
+	if err = registry.Register(redis.NewPoolStatsCollector(cfg.Metrics.ServiceName,
+		redisClient)); err != nil {
+		appLogger.Fatalf("Register redis pool stats collector: %s", err)
 	}
 
-	tracer, closer, err := jaegerCfgInstance.NewTracer(
-		jaegercfg.Logger(jaegerlog.StdLogger),
-		jaegercfg.Metrics(metrics.NullFactory),
-	)
+	tracerProvider, err := tracing.NewTracerProvider(context.Background(), cfg)
 	if err != nil {
-		log.Fatal("cannot create tracer", err)
+		appLogger.Fatalf("NewTracerProvider: %s", err)
 	}
-	appLogger.Info("Jaeger connected")
-
-	opentracing.SetGlobalTracer(tracer)
-	defer closer.Close()
-	appLogger.Info("Opentracing connected")
+	defer func() {
+		// Flush the pending spans before exiting
+		ctx, cancel := context.WithTimeout(context.Background(),
+			config.ShutdownTimeout(cfg.Shutdown.StoresTimeout))
+		defer cancel()
+		if err := tracerProvider.Shutdown(ctx); err != nil {
+			appLogger.Errorf("tracerProvider.Shutdown: %s", err)
+		}
+	}()
+	appLogger.Infof("OpenTelemetry tracing enabled, Exporter: %s, SampleRatio: %v",
+		cfg.Tracing.Exporter, cfg.Tracing.SampleRatio)
 
-	s := server.NewServer(cfg, psqlDB, redisClient, appLogger)
+	s := server.NewServer(cfg, psqlDB, redisClient, registry, appLogger)
 	if err = s.Run(); err != nil {
 		log.Fatal(err)
 	}
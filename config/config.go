@@ -11,13 +11,24 @@ import (
 // App config struct
 type Config struct {
 	Server      ServerConfig
+	TLS         TLS
+	Shutdown    Shutdown
+	Health      Health
 	Postgres    PostgresConfig
 	Redis       RedisConfig
+	Cache       Cache
+	RateLimit   RateLimit
 	Cookie      Cookie
+	CORS        CORS
+	Security    SecurityHeaders
+	CSRF        CSRF
+	Compression Compression
 	Metrics     Metrics
 	Logger      Logger
-	Jaeger      Jaeger
+	AccessLog   AccessLog
+	Tracing     Tracing
 	KafkaTopics KafkaTopics
+	Events      Events
 	Kafka       *Kafka
 }
 
@@ -29,14 +40,87 @@ type ServerConfig struct {
 	Mode                 string
 	JwtSecretKey         string
 	CookieName           string
+	AdminToken           string
 	ReadTimeout          time.Duration
 	WriteTimeout         time.Duration
 	SSL                  bool
 	CtxDefaultTimeout    time.Duration
+	RouteTimeouts        []RouteTimeout
 	Debug                bool
 	CheckIntervalSeconds int
 }
 
+// RouteTimeout overrides the default request timeout of a route, Timeout is in seconds
+type RouteTimeout struct {
+	Method  string
+	Route   string
+	Timeout int
+}
+
+// TLS config of the api server, it is enabled by ServerConfig.SSL.
+// ClientAuth is one of none, request, verify_if_given and require_and_verify,
+// CipherSuites are the Go names of the TLS 1.2 suites and ReloadInterval is in seconds.
+type TLS struct {
+	CertFile       string
+	KeyFile        string
+	MinVersion     string
+	CipherSuites   []string
+	ClientCAFile   string
+	ClientAuth     string
+	ReloadInterval int
+}
+
+// Shutdown config, the drain period and the timeouts of the shutdown steps are in seconds
+type Shutdown struct {
+	DrainPeriod    int
+	HTTPTimeout    int
+	KafkaTimeout   int
+	WorkersTimeout int
+	StoresTimeout  int
+}
+
+// DefaultShutdownTimeout is the timeout of the shutdown steps configured without a timeout
+const DefaultShutdownTimeout = 5 * time.Second
+
+// ShutdownTimeout returns the duration of a shutdown step timeout in seconds, the timeouts
+// that are not positive default to DefaultShutdownTimeout
+func ShutdownTimeout(seconds int) time.Duration {
+	if seconds <= 0 {
+		return DefaultShutdownTimeout
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// Health config, durations are in seconds
+type Health struct {
+	CheckTimeout int
+	CacheTTL     int
+}
+
+// Defaults of the health config values that are not configured
+const (
+	DefaultHealthCheckTimeout = 2 * time.Second
+	DefaultHealthCacheTTL     = 5 * time.Second
+)
+
+// HealthCheckTimeout returns the timeout of the dependency checks in seconds, the timeouts
+// that are not positive default to DefaultHealthCheckTimeout
+func HealthCheckTimeout(seconds int) time.Duration {
+	if seconds <= 0 {
+		return DefaultHealthCheckTimeout
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// HealthCacheTTL returns the ttl of the cached check results in seconds, the ttls
+// that are not positive default to DefaultHealthCacheTTL
+func HealthCacheTTL(seconds int) time.Duration {
+	if seconds <= 0 {
+		return DefaultHealthCacheTTL
+	}
+	return time.Duration(seconds) * time.Second
+}
+
 // Logger config
 type Logger struct {
 	Development       bool
@@ -46,6 +130,16 @@ type Logger struct {
 	Level             string
 }
 
+// AccessLog config, the successful requests are logged with the SampleRate probability
+// and the failed ones are always logged. SkipPaths are paths or routes, a trailing * matches
+// any suffix, RedactParams are the query params whose values are never logged.
+type AccessLog struct {
+	Enabled      bool
+	SampleRate   float64
+	SkipPaths    []string
+	RedactParams []string
+}
+
 // Postgresql config
 type PostgresConfig struct {
 	PostgresqlHost     string
@@ -55,6 +149,10 @@ type PostgresConfig struct {
 	PostgresqlDbname   string
 	PostgresqlSSLMode  bool
 	PgDriver           string
+	// MigrationVersion is the schema version the service requires
+	MigrationVersion int
+	// SlowQueryThreshold in milliseconds, slower queries are logged, 0 disables the log
+	SlowQueryThreshold int
 }
 
 // Redis config
@@ -68,6 +166,40 @@ type RedisConfig struct {
 	PoolTimeout    int
 	Password       string
 	DB             int
+	// SlowCommandThreshold in milliseconds, slower commands are logged, 0 disables the log
+	SlowCommandThreshold int
+}
+
+// Cache config
+type Cache struct {
+	InvalidationChannel string
+	Users               CacheConfig
+	Companies           CacheConfig
+}
+
+// CacheConfig of a single cache, durations are in seconds
+type CacheConfig struct {
+	TTL           int
+	NegativeTTL   int
+	JitterPercent int
+	LocalSize     int
+	LocalTTL      int
+}
+
+// Rate limit config
+type RateLimit struct {
+	Enabled  bool
+	Default  RateLimitPolicy
+	Policies []RateLimitPolicy
+}
+
+// RateLimitPolicy of a route, Requests per Period seconds with bursts up to Burst requests
+type RateLimitPolicy struct {
+	Method   string
+	Route    string
+	Requests int
+	Period   int
+	Burst    int
 }
 
 // Cookie config
@@ -78,17 +210,56 @@ type Cookie struct {
 	HTTPOnly bool
 }
 
+// CORS config, MaxAge is in seconds
+type CORS struct {
+	AllowOrigins     []string
+	AllowMethods     []string
+	AllowHeaders     []string
+	ExposeHeaders    []string
+	AllowCredentials bool
+	MaxAge           int
+}
+
+// SecurityHeaders config, HSTS is only sent over TLS and HSTSMaxAge is in seconds
+type SecurityHeaders struct {
+	HSTSMaxAge            int
+	HSTSIncludeSubdomains bool
+	ContentSecurityPolicy string
+	FrameOptions          string
+	ReferrerPolicy        string
+}
+
+// CSRF config of the cookie auth, a double submit cookie checked against a request header
+type CSRF struct {
+	Enabled    bool
+	CookieName string
+	HeaderName string
+}
+
+// Compression config, the responses smaller than MinSize bytes are not compressed
+type Compression struct {
+	Enabled     bool
+	MinSize     int
+	GzipLevel   int
+	BrotliLevel int
+}
+
 // Metrics config
 type Metrics struct {
-	URL         string
 	ServiceName string
 }
 
-// Jaeger
-type Jaeger struct {
-	Host        string
+// Tracing config, Exporter is one of otlp, stdout, file and none. The otlp Endpoint is
+// overridden by OTEL_EXPORTER_OTLP_ENDPOINT, FilePath is the output of the file exporter
+// and SampleRatio is the ratio in (0, 1] of the new traces sampled, 1 when it is not set.
+// The callers decision is kept.
+type Tracing struct {
 	ServiceName string
-	LogSpans    bool
+	Exporter    string
+	Endpoint    string
+	Insecure    bool
+	FilePath    string
+	SampleRatio float64
 }
 
 // KafkaTopics
@@ -98,6 +269,27 @@ type KafkaTopics struct {
 	CompanyDeleted TopicConfig
 }
 
+// Events config of the published company events, Mode is envelope, binary, structured,
+// protobuf or avro. The binary and structured cloudevents source and type are derived from
+// Source, TypePrefix and the topic, the protobuf and avro schemas are kept in the registry
+type Events struct {
+	Mode           string
+	Source         string
+	TypePrefix     string
+	SchemaRegistry SchemaRegistry
+}
+
+// SchemaRegistry config of the protobuf and avro events, Type is confluent or file,
+// Timeout is in seconds
+type SchemaRegistry struct {
+	Type     string
+	URL      string
+	Username string
+	Password string
+	FilePath string
+	Timeout  int
+}
+
 // TopicConfig kafka topic config
 type TopicConfig struct {
 	TopicName         string
@@ -109,6 +301,14 @@ type TopicConfig struct {
 type Kafka struct {
 	Brokers    []string
 	InitTopics bool
+	Consumer   KafkaConsumer
+}
+
+// KafkaConsumer config of the consumer group of the company events, the consumer is disabled
+// without a group id. The messages that fail are published to the dead letter topic
+type KafkaConsumer struct {
+	GroupID         string
+	DeadLetterTopic TopicConfig
 }
 
 // Load config file from given path
@@ -128,10 +328,18 @@ func LoadConfig(filename string) (*viper.Viper, error) {
 	return v, nil
 }
 
+// AdminTokenEnv is the environment variable of the admin token, it is never kept in the
+// config files
+const AdminTokenEnv = "ADMIN_TOKEN"
+
 // Parse config file
 func ParseConfig(v *viper.Viper) (*Config, error) {
 	var c Config
 
+	if err := v.BindEnv("server.adminToken", AdminTokenEnv); err != nil {
+		return nil, err
+	}
+
 	err := v.Unmarshal(&c)
 	if err != nil {
 		log.Printf("unable to decode into struct, %v", err)
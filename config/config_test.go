@@ -0,0 +1,35 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShutdownTimeout(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 10*time.Second, ShutdownTimeout(10))
+	assert.Equal(t, DefaultShutdownTimeout, ShutdownTimeout(0))
+	assert.Equal(t, DefaultShutdownTimeout, ShutdownTimeout(-1))
+}
+
+func TestHealthDefaults(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 3*time.Second, HealthCheckTimeout(3))
+	assert.Equal(t, DefaultHealthCheckTimeout, HealthCheckTimeout(0))
+	assert.Equal(t, 10*time.Second, HealthCacheTTL(10))
+	assert.Equal(t, DefaultHealthCacheTTL, HealthCacheTTL(-1))
+}
+
+func TestParseConfig_AdminToken(t *testing.T) {
+	t.Setenv(AdminTokenEnv, "admintoken")
+
+	cfg, err := ParseConfig(viper.New())
+	require.NoError(t, err)
+	assert.Equal(t, "admintoken", cfg.Server.AdminToken)
+}
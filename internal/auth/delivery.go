@@ -10,4 +10,7 @@ type Handlers interface {
 	Delete(c *gin.Context)
 	GetUserByID(c *gin.Context)
 	GetMe(c *gin.Context)
+	GetMyLogins(c *gin.Context)
+	GetUserLogins(c *gin.Context)
+	GetCSRFToken(c *gin.Context)
 }
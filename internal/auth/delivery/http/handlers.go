@@ -4,13 +4,13 @@ import (
 	"companies-service/config"
 	"companies-service/internal/auth"
 	"companies-service/internal/models"
+	"companies-service/pkg/authn"
 	"companies-service/pkg/httphelper"
 	"companies-service/pkg/logger"
+	"companies-service/pkg/tracing"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
-	"github.com/opentracing/opentracing-go"
 )
 
 // Auth handlers
@@ -36,8 +36,8 @@ func NewAuthHandlers(
 // @Success 201 {object} models.User
 // @Router /auth/register [post]
 func (h *authHandlers) Register(c *gin.Context) {
-	span, ctx := opentracing.StartSpanFromContext(c, "auth.Register")
-	defer span.Finish()
+	ctx, span := tracing.StartSpan(c, "auth.Register")
+	defer span.End()
 
 	user := &models.User{}
 	if err := httphelper.ReadRequest(c, user); err != nil {
@@ -67,8 +67,8 @@ func (h *authHandlers) Login(c *gin.Context) {
 		Email    string `json:"email" db:"email" validate:"omitempty,lte=60,email"`
 		Password string `json:"password,omitempty" db:"password" validate:"required,gte=6"`
 	}
-	span, ctx := opentracing.StartSpanFromContext(c, "auth.Login")
-	defer span.Finish()
+	ctx, span := tracing.StartSpan(c, "auth.Login")
+	defer span.End()
 
 	login := &Login{}
 	if err := httphelper.ReadRequest(c, login); err != nil {
@@ -79,6 +79,9 @@ func (h *authHandlers) Login(c *gin.Context) {
 	userWithToken, err := h.authService.Login(ctx, &models.User{
 		Email:    login.Email,
 		Password: login.Password,
+	}, &models.LoginClient{
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
 	})
 	if err != nil {
 		httphelper.ErrResponseWithLog(c, h.logger, err)
@@ -98,17 +101,17 @@ func (h *authHandlers) Login(c *gin.Context) {
 // @Success 200 {object} models.User
 // @Router /auth/{id} [put]
 func (h *authHandlers) Update(c *gin.Context) {
-	span, ctx := opentracing.StartSpanFromContext(c, "authHandlers.Update")
-	defer span.Finish()
+	ctx, span := tracing.StartSpan(c, "authHandlers.Update")
+	defer span.End()
 
-	uuid, err := uuid.Parse(c.Param("user_id"))
+	userID, err := httphelper.ParseUUIDParam(c, "user_id")
 	if err != nil {
 		httphelper.ErrResponseWithLog(c, h.logger, err)
 		return
 	}
 
 	user := &models.User{}
-	user.UserID = uuid
+	user.UserID = userID
 
 	if err = httphelper.ReadRequest(c, user); err != nil {
 		httphelper.ErrResponseWithLog(c, h.logger, err)
@@ -132,13 +135,13 @@ func (h *authHandlers) Update(c *gin.Context) {
 // @Produce  json
 // @Param id path int true "user_id"
 // @Success 200 {object} models.User
-// @Failure 500 {object} httphelper.RestError
+// @Failure 500 {object} httphelper.ProblemDetails
 // @Router /auth/{id} [get]
 func (h *authHandlers) GetUserByID(c *gin.Context) {
-	span, ctx := opentracing.StartSpanFromContext(c, "authHandlers.GetUserByID")
-	defer span.Finish()
+	ctx, span := tracing.StartSpan(c, "authHandlers.GetUserByID")
+	defer span.End()
 
-	uID, err := uuid.Parse(c.Param("user_id"))
+	uID, err := httphelper.ParseUUIDParam(c, "user_id")
 	if err != nil {
 		httphelper.ErrResponseWithLog(c, h.logger, err)
 		return
@@ -161,25 +164,25 @@ func (h *authHandlers) GetUserByID(c *gin.Context) {
 // @Param id path int true "user_id"
 // @Produce json
 // @Success 200 {string} string	"ok"
-// @Failure 500 {object} httphelper.RestError
+// @Failure 500 {object} httphelper.ProblemDetails
 // @Router /auth/{id} [delete]
 func (h *authHandlers) Delete(c *gin.Context) {
-	span, ctx := opentracing.StartSpanFromContext(c, "authHandlers.Delete")
-	defer span.Finish()
+	ctx, span := tracing.StartSpan(c, "authHandlers.Delete")
+	defer span.End()
 
-	uuid, err := uuid.Parse(c.Param("user_id"))
+	userID, err := httphelper.ParseUUIDParam(c, "user_id")
 	if err != nil {
 		httphelper.ErrResponseWithLog(c, h.logger, err)
 		return
 	}
 
-	if err = h.authService.Delete(ctx, uuid); err != nil {
+	if err = h.authService.Delete(ctx, userID); err != nil {
 		httphelper.ErrResponseWithLog(c, h.logger, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		uuid.String(): "Deleted",
+		userID.String(): "Deleted",
 	})
 }
 
@@ -190,11 +193,11 @@ func (h *authHandlers) Delete(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Success 200 {object} models.User
-// @Failure 500 {object} httphelper.RestError
+// @Failure 500 {object} httphelper.ProblemDetails
 // @Router /auth/me [get]
 func (h *authHandlers) GetMe(c *gin.Context) {
-	span, _ := opentracing.StartSpanFromContext(c, "authHandlers.GetMe")
-	defer span.Finish()
+	_, span := tracing.StartSpan(c, "authHandlers.GetMe")
+	defer span.End()
 
 	user, ok := c.MustGet("user").(*models.User)
 	if !ok {
@@ -204,3 +207,83 @@ func (h *authHandlers) GetMe(c *gin.Context) {
 
 	c.JSON(http.StatusOK, user)
 }
+
+// GetMyLogins godoc
+// @Summary Get login history of current user
+// @Description Get the latest login attempts of the current user
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.LoginEvent
+// @Failure 500 {object} httphelper.ProblemDetails
+// @Router /auth/me/logins [get]
+func (h *authHandlers) GetMyLogins(c *gin.Context) {
+	ctx, span := tracing.StartSpan(c, "authHandlers.GetMyLogins")
+	defer span.End()
+
+	user, ok := c.MustGet("user").(*models.User)
+	if !ok {
+		httphelper.ErrResponseWithLog(c, h.logger, httphelper.ErrUnauthorized)
+		return
+	}
+
+	loginEvents, err := h.authService.GetLoginEvents(ctx, user.UserID)
+	if err != nil {
+		httphelper.ErrResponseWithLog(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, loginEvents)
+}
+
+// GetUserLogins godoc
+// @Summary Get login history of a user
+// @Description Get the latest login attempts of a user, admin only
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param id path int true "user_id"
+// @Success 200 {array} models.LoginEvent
+// @Failure 403 {object} httphelper.ProblemDetails
+// @Failure 500 {object} httphelper.ProblemDetails
+// @Router /auth/{id}/logins [get]
+func (h *authHandlers) GetUserLogins(c *gin.Context) {
+	ctx, span := tracing.StartSpan(c, "authHandlers.GetUserLogins")
+	defer span.End()
+
+	uID, err := httphelper.ParseUUIDParam(c, "user_id")
+	if err != nil {
+		httphelper.ErrResponseWithLog(c, h.logger, err)
+		return
+	}
+
+	loginEvents, err := h.authService.GetLoginEvents(ctx, uID)
+	if err != nil {
+		httphelper.ErrResponseWithLog(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, loginEvents)
+}
+
+// GetCSRFToken godoc
+// @Summary Get CSRF token
+// @Description Issue the CSRF token the cookie authenticated requests must send in the X-CSRF-Token header
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Failure 500 {object} httphelper.ProblemDetails
+// @Router /auth/csrf [get]
+func (h *authHandlers) GetCSRFToken(c *gin.Context) {
+	_, span := tracing.StartSpan(c, "authHandlers.GetCSRFToken")
+	defer span.End()
+
+	csrfToken, err := authn.GenerateCSRFToken()
+	if err != nil {
+		httphelper.ErrResponseWithLog(c, h.logger, err)
+		return
+	}
+
+	http.SetCookie(c.Writer, httphelper.ConfigureCSRFCookie(h.cfg, csrfToken))
+	c.JSON(http.StatusOK, gin.H{"csrf_token": csrfToken})
+}
@@ -5,6 +5,7 @@ import (
 	"companies-service/internal/auth/mock"
 	"companies-service/internal/models"
 	"companies-service/pkg/converter"
+	"companies-service/pkg/httphelper"
 	"companies-service/pkg/logger"
 	"encoding/json"
 	"fmt"
@@ -77,6 +78,72 @@ func TestAuthHandlers_Register(t *testing.T) {
 	assert.Equal(t, http.StatusCreated, w.Code)
 }
 
+func TestAuthHandlers_Register_ValidationErrors(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Mode: "Development",
+		},
+		Logger: config.Logger{
+			Development: true,
+		},
+	}
+
+	apiLogger := logger.NewApiLogger(cfg)
+	apiLogger.InitLogger()
+	mockAuthService := mock.NewMockService(ctrl)
+	handlers := NewAuthHandlers(cfg, mockAuthService, apiLogger)
+
+	// Define the test route
+	router := gin.Default()
+	router.POST("/api/v1/auth/register", handlers.Register)
+
+	body := `{"first_name":"Nick","email":"not-an-email","password":"123"}`
+
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		emailMessage   string
+	}{
+		{name: "default language", acceptLanguage: "", emailMessage: "email must be a valid email address"},
+		{name: "supported language", acceptLanguage: "de-DE,es;q=0.8",
+			emailMessage: "email debe ser una dirección de correo electrónico válida"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest("POST", "/api/v1/auth/register", strings.NewReader(body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", gin.MIMEJSON)
+			req.Header.Set("Accept-Language", tt.acceptLanguage)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert the response status code (HTTP 400 Bad Request)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var problem httphelper.ProblemDetails
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			assert.Equal(t, httphelper.CodeValidationFailed, problem.Code)
+
+			fieldErrors := make(map[string]httphelper.FieldError, len(problem.Errors))
+			for _, fieldError := range problem.Errors {
+				fieldErrors[fieldError.Field] = fieldError
+			}
+			require.Len(t, fieldErrors, 3)
+			assert.Equal(t, "required", fieldErrors["last_name"].Rule)
+			assert.Equal(t, "gte", fieldErrors["password"].Rule)
+			assert.Equal(t, "6", fieldErrors["password"].Param)
+			assert.Equal(t, "email", fieldErrors["email"].Rule)
+			assert.Equal(t, tt.emailMessage, fieldErrors["email"].Message)
+		})
+	}
+}
+
 func TestAuthHandlers_Login(t *testing.T) {
 	t.Parallel()
 
@@ -118,7 +185,8 @@ func TestAuthHandlers_Login(t *testing.T) {
 	}
 
 	// Expect the AuthService's Login method to be called with the mockUser
-	mockAuthService.EXPECT().Login(gomock.Any(), gomock.Eq(mockUser)).Return(mockUserWithToken, nil)
+	mockAuthService.EXPECT().Login(gomock.Any(), gomock.Eq(mockUser),
+		gomock.Any()).Return(mockUserWithToken, nil)
 
 	// Define the test route
 	router := gin.Default()
@@ -278,3 +346,94 @@ func TestAuthHandlers_Delete(t *testing.T) {
 	// Perform assertions on the response, if needed
 	assert.Equal(t, "Deleted", response[userID.String()])
 }
+
+func TestAuthHandlers_GetMyLogins(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		Logger: config.Logger{
+			Development: true,
+		},
+	}
+
+	apiLogger := logger.NewApiLogger(cfg)
+	mockAuthService := mock.NewMockService(ctrl)
+	handlers := NewAuthHandlers(cfg, mockAuthService, apiLogger)
+
+	// Define a mock current user and its login history
+	user := &models.User{
+		UserID: uuid.New(),
+	}
+	loginEvents := []*models.LoginEvent{
+		{
+			LoginEventID: uuid.New(),
+			UserID:       &user.UserID,
+			IPAddress:    "127.0.0.1",
+			Success:      true,
+		},
+	}
+
+	// Expect the AuthService's GetLoginEvents method to be called with the current user ID
+	mockAuthService.EXPECT().GetLoginEvents(gomock.Any(),
+		gomock.Eq(user.UserID)).Return(loginEvents, nil)
+
+	// Define the test route, setting the current user as the auth middleware does
+	router := gin.Default()
+	router.GET("/api/v1/auth/me/logins", func(c *gin.Context) {
+		c.Set("user", user)
+	}, handlers.GetMyLogins)
+
+	w := performRequest(router, "GET", "/api/v1/auth/me/logins", "")
+
+	// Assert the response status code (HTTP 200 OK in this case)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Parse the JSON response body
+	var response []*models.LoginEvent
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Nil(t, err)
+	assert.Len(t, response, 1)
+	assert.Equal(t, loginEvents[0].IPAddress, response[0].IPAddress)
+}
+
+func TestAuthHandlers_GetCSRFToken(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		CSRF: config.CSRF{
+			Enabled:    true,
+			CookieName: "csrf-token",
+			HeaderName: "X-CSRF-Token",
+		},
+	}
+
+	apiLogger := logger.NewApiLogger(cfg)
+	mockAuthService := mock.NewMockService(ctrl)
+	handlers := NewAuthHandlers(cfg, mockAuthService, apiLogger)
+
+	// Define the test route
+	router := gin.Default()
+	router.GET("/api/v1/auth/csrf", handlers.GetCSRFToken)
+
+	w := performRequest(router, "GET", "/api/v1/auth/csrf", "")
+
+	// Assert the response status code (HTTP 200 OK)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]string
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.NotEmpty(t, response["csrf_token"])
+
+	// The cookie carries the same token
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "csrf-token", cookies[0].Name)
+	assert.Equal(t, response["csrf_token"], cookies[0].Value)
+	assert.Equal(t, http.SameSiteStrictMode, cookies[0].SameSite)
+}
@@ -13,8 +13,12 @@ func MapAuthRoutes(authGroup *gin.RouterGroup, h auth.Handlers, mw *middleware.M
 	cfg *config.Config) {
 	authGroup.POST("/register", h.Register)
 	authGroup.POST("/login", h.Login)
-	authGroup.GET("/:user_id", h.GetUserByID)
-	authGroup.GET("/me", mw.AuthJWTMiddleware(cfg), h.GetMe)
+	authGroup.GET("/csrf", h.GetCSRFToken)
+	authGroup.GET("/:user_id", mw.ETagMiddleware(), h.GetUserByID)
+	authGroup.GET("/me", mw.AuthJWTMiddleware(cfg), mw.ETagMiddleware(), h.GetMe)
+	authGroup.GET("/me/logins", mw.AuthJWTMiddleware(cfg), mw.ETagMiddleware(), h.GetMyLogins)
+	authGroup.GET("/:user_id/logins", mw.AuthJWTMiddleware(cfg), mw.AdminMiddleware(),
+		mw.ETagMiddleware(), h.GetUserLogins)
 	authGroup.PUT("/:user_id", mw.AuthJWTMiddleware(cfg), h.Update)
 	authGroup.DELETE("/:user_id", mw.AuthJWTMiddleware(cfg), h.Delete)
 }
@@ -4,6 +4,7 @@ import (
 	"companies-service/internal/models"
 	"context"
 	"reflect"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
@@ -110,3 +111,53 @@ func (mr *MockRepositoryRecorder) FindByEmail(ctx, user interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock,
 		"FindByEmail", reflect.TypeOf((*MockRepository)(nil).FindByEmail), ctx, user)
 }
+
+// UpdateLoginDate mocks base method
+func (m *MockRepository) UpdateLoginDate(ctx context.Context, userID uuid.UUID) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateLoginDate", ctx, userID)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateLoginDate indicates an expected call of UpdateLoginDate
+func (mr *MockRepositoryRecorder) UpdateLoginDate(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock,
+		"UpdateLoginDate", reflect.TypeOf((*MockRepository)(nil).UpdateLoginDate), ctx, userID)
+}
+
+// CreateLoginEvent mocks base method
+func (m *MockRepository) CreateLoginEvent(ctx context.Context, event *models.LoginEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLoginEvent", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateLoginEvent indicates an expected call of CreateLoginEvent
+func (mr *MockRepositoryRecorder) CreateLoginEvent(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock,
+		"CreateLoginEvent", reflect.TypeOf((*MockRepository)(nil).CreateLoginEvent), ctx, event)
+}
+
+// GetLoginEvents mocks base method
+func (m *MockRepository) GetLoginEvents(
+	ctx context.Context, userID uuid.UUID, limit int,
+) ([]*models.LoginEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginEvents", ctx, userID, limit)
+	ret0, _ := ret[0].([]*models.LoginEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginEvents indicates an expected call of GetLoginEvents
+func (mr *MockRepositoryRecorder) GetLoginEvents(ctx, userID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock,
+		"GetLoginEvents", reflect.TypeOf((*MockRepository)(nil).GetLoginEvents), ctx, userID,
+		limit)
+}
@@ -2,10 +2,12 @@ package mock
 
 import (
 	"companies-service/internal/models"
+	"companies-service/pkg/cache"
 	"context"
 	"reflect"
 
 	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
 )
 
 // MockRedisRepository is a mock of RedisRepository interface
@@ -32,52 +34,34 @@ func (m *MockRedisRepository) EXPECT() *MockRedisRepositoryRecorder {
 }
 
 // GetByIDCtx mocks base method
-func (m *MockRedisRepository) GetByIDCtx(ctx context.Context, key string) (*models.User, error) {
+func (m *MockRedisRepository) GetByIDCtx(
+	ctx context.Context, userID uuid.UUID, load cache.Loader[models.User],
+) (*models.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetByIDCtx", ctx, key)
+	ret := m.ctrl.Call(m, "GetByIDCtx", ctx, userID, load)
 	ret0, _ := ret[0].(*models.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetByIDCtx indicates an expected call of GetByIDCtx
-func (mr *MockRedisRepositoryRecorder) GetByIDCtx(ctx, key interface{}) *gomock.Call {
+func (mr *MockRedisRepositoryRecorder) GetByIDCtx(ctx, userID, load interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock,
-		"GetByIDCtx", reflect.TypeOf((*MockRedisRepository)(nil).GetByIDCtx), ctx, key)
-}
-
-// SetUserCtx mocks base method
-func (m *MockRedisRepository) SetUserCtx(
-	ctx context.Context, key string, seconds int, user *models.User,
-) error {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetUserCtx", ctx, key, seconds, user)
-	ret0, _ := ret[0].(error)
-	return ret0
-}
-
-// SetUserCtx indicates an expected call of SetUserCtx
-func (mr *MockRedisRepositoryRecorder) SetUserCtx(
-	ctx, key, seconds, user interface{},
-) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock,
-		"SetUserCtx", reflect.TypeOf((*MockRedisRepository)(nil).SetUserCtx),
-		ctx, key, seconds, user)
+		"GetByIDCtx", reflect.TypeOf((*MockRedisRepository)(nil).GetByIDCtx), ctx, userID, load)
 }
 
 // DeleteUserCtx mocks base method
-func (m *MockRedisRepository) DeleteUserCtx(ctx context.Context, key string) error {
+func (m *MockRedisRepository) DeleteUserCtx(ctx context.Context, userID uuid.UUID) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteUserCtx", ctx, key)
+	ret := m.ctrl.Call(m, "DeleteUserCtx", ctx, userID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteUserCtx indicates an expected call of DeleteUserCtx
-func (mr *MockRedisRepositoryRecorder) DeleteUserCtx(ctx, key interface{}) *gomock.Call {
+func (mr *MockRedisRepositoryRecorder) DeleteUserCtx(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock,
-		"DeleteUserCtx", reflect.TypeOf((*MockRedisRepository)(nil).DeleteUserCtx), ctx, key)
+		"DeleteUserCtx", reflect.TypeOf((*MockRedisRepository)(nil).DeleteUserCtx), ctx, userID)
 }
@@ -52,20 +52,20 @@ func (mr *MockServiceRecorder) Register(ctx, user interface{}) *gomock.Call {
 
 // Login mocks base method
 func (m *MockService) Login(
-	ctx context.Context, user *models.User,
+	ctx context.Context, user *models.User, client *models.LoginClient,
 ) (*models.UserWithToken, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Login", ctx, user)
+	ret := m.ctrl.Call(m, "Login", ctx, user, client)
 	ret0, _ := ret[0].(*models.UserWithToken)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Login indicates an expected call of Login
-func (mr *MockServiceRecorder) Login(ctx, user interface{}) *gomock.Call {
+func (mr *MockServiceRecorder) Login(ctx, user, client interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock,
-		"Login", reflect.TypeOf((*MockService)(nil).Login), ctx, user)
+		"Login", reflect.TypeOf((*MockService)(nil).Login), ctx, user, client)
 }
 
 // Update mocks base method
@@ -114,3 +114,21 @@ func (mr *MockServiceRecorder) GetByID(ctx, userID interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock,
 		"GetByID", reflect.TypeOf((*MockService)(nil).GetByID), ctx, userID)
 }
+
+// GetLoginEvents mocks base method
+func (m *MockService) GetLoginEvents(
+	ctx context.Context, userID uuid.UUID,
+) ([]*models.LoginEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginEvents", ctx, userID)
+	ret0, _ := ret[0].([]*models.LoginEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginEvents indicates an expected call of GetLoginEvents
+func (mr *MockServiceRecorder) GetLoginEvents(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock,
+		"GetLoginEvents", reflect.TypeOf((*MockService)(nil).GetLoginEvents), ctx, userID)
+}
@@ -3,6 +3,7 @@ package auth
 import (
 	"companies-service/internal/models"
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -14,4 +15,7 @@ type Repository interface {
 	Delete(ctx context.Context, userID uuid.UUID) error
 	GetByID(ctx context.Context, userID uuid.UUID) (*models.User, error)
 	FindByEmail(ctx context.Context, user *models.User) (*models.User, error)
+	UpdateLoginDate(ctx context.Context, userID uuid.UUID) (time.Time, error)
+	CreateLoginEvent(ctx context.Context, event *models.LoginEvent) error
+	GetLoginEvents(ctx context.Context, userID uuid.UUID, limit int) ([]*models.LoginEvent, error)
 }
@@ -2,12 +2,16 @@ package auth
 
 import (
 	"companies-service/internal/models"
+	"companies-service/pkg/cache"
 	"context"
+
+	"github.com/google/uuid"
 )
 
 // Auth Redis repository interface
 type RedisRepository interface {
-	GetByIDCtx(ctx context.Context, key string) (*models.User, error)
-	SetUserCtx(ctx context.Context, key string, seconds int, user *models.User) error
-	DeleteUserCtx(ctx context.Context, key string) error
+	GetByIDCtx(
+		ctx context.Context, userID uuid.UUID, load cache.Loader[models.User],
+	) (*models.User, error)
+	DeleteUserCtx(ctx context.Context, userID uuid.UUID) error
 }
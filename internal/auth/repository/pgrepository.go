@@ -3,22 +3,25 @@ package repository
 import (
 	"companies-service/internal/auth"
 	"companies-service/internal/models"
+	"companies-service/pkg/tracing"
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
-	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 )
 
 const (
-	createUserQuery = `INSERT INTO users (first_name, last_name, email, password, role, about, phone_number, address,
+	createUserQuery = `-- name: CreateUser
+	INSERT INTO users (first_name, last_name, email, password, role, about, phone_number, address,
 	               		city, gender, postcode, birthday, created_at, updated_at, login_date)
 						VALUES ($1, $2, $3, $4, COALESCE(NULLIF($5, ''), 'user'), $6, $7, $8, $9, $10, $11, $12, now(), now(), now()) 
 						RETURNING *`
 
-	updateUserQuery = `UPDATE users 
+	updateUserQuery = `-- name: UpdateUser
+	UPDATE users 
 						SET first_name = COALESCE(NULLIF($1, ''), first_name),
 						    last_name = COALESCE(NULLIF($2, ''), last_name),
 						    email = COALESCE(NULLIF($3, ''), email),
@@ -35,17 +38,34 @@ const (
 						RETURNING *
 						`
 
-	deleteUserQuery = `DELETE FROM users WHERE user_id = $1`
+	deleteUserQuery = `-- name: DeleteUser
+	DELETE FROM users WHERE user_id = $1`
 
-	getUserQuery = `SELECT user_id, first_name, last_name, email, role, about, phone_number, 
+	getUserQuery = `-- name: GetUser
+	SELECT user_id, first_name, last_name, email, role, about, phone_number, 
        				 address, city, gender, postcode, birthday, created_at, updated_at, login_date  
 					 FROM users 
 					 WHERE user_id = $1`
 
-	findUserByEmail = `SELECT user_id, first_name, last_name, email, role, about, phone_number, 
+	findUserByEmail = `-- name: FindUserByEmail
+	SELECT user_id, first_name, last_name, email, role, about, phone_number, 
        			 		address, city, gender, postcode, birthday, created_at, updated_at, login_date, password
 				 		FROM users 
 				 		WHERE email = $1`
+
+	updateLoginDateQuery = `-- name: UpdateLoginDate
+	UPDATE users SET login_date = now() WHERE user_id = $1 RETURNING login_date`
+
+	createLoginEventQuery = `-- name: CreateLoginEvent
+	INSERT INTO login_events (user_id, email, ip_address, user_agent, success, failure_reason)
+							VALUES ($1, $2, $3, $4, $5, $6)`
+
+	getLoginEventsQuery = `-- name: GetLoginEvents
+	SELECT login_event_id, user_id, email, ip_address, user_agent, success, failure_reason, created_at
+							FROM login_events
+							WHERE user_id = $1
+							ORDER BY created_at DESC
+							LIMIT $2`
 )
 
 // Auth Repository
@@ -60,8 +80,8 @@ func NewAuthRepository(db *sqlx.DB) auth.Repository {
 
 // Register creates new user
 func (r *authRepo) Register(ctx context.Context, user *models.User) (*models.User, error) {
-	span, ctx := opentracing.StartSpanFromContext(ctx, "authPGRepo.Register")
-	defer span.Finish()
+	ctx, span := tracing.StartSpan(ctx, "authPGRepo.Register")
+	defer span.End()
 
 	u := &models.User{}
 	if err := r.db.QueryRowxContext(ctx, createUserQuery, &user.FirstName, &user.LastName,
@@ -76,8 +96,8 @@ func (r *authRepo) Register(ctx context.Context, user *models.User) (*models.Use
 
 // Update updates existing user
 func (r *authRepo) Update(ctx context.Context, user *models.User) (*models.User, error) {
-	span, ctx := opentracing.StartSpanFromContext(ctx, "authPGRepo.Update")
-	defer span.Finish()
+	ctx, span := tracing.StartSpan(ctx, "authPGRepo.Update")
+	defer span.End()
 
 	u := &models.User{}
 	if err := r.db.GetContext(ctx, u, updateUserQuery, &user.FirstName, &user.LastName,
@@ -92,8 +112,8 @@ func (r *authRepo) Update(ctx context.Context, user *models.User) (*models.User,
 
 // Delete removes existing user
 func (r *authRepo) Delete(ctx context.Context, userID uuid.UUID) error {
-	span, ctx := opentracing.StartSpanFromContext(ctx, "authPGRepo.Delete")
-	defer span.Finish()
+	ctx, span := tracing.StartSpan(ctx, "authPGRepo.Delete")
+	defer span.End()
 
 	result, err := r.db.ExecContext(ctx, deleteUserQuery, userID)
 	if err != nil {
@@ -112,8 +132,8 @@ func (r *authRepo) Delete(ctx context.Context, userID uuid.UUID) error {
 
 // GetByID retreives user by id
 func (r *authRepo) GetByID(ctx context.Context, userID uuid.UUID) (*models.User, error) {
-	span, ctx := opentracing.StartSpanFromContext(ctx, "authPGRepo.GetByID")
-	defer span.Finish()
+	ctx, span := tracing.StartSpan(ctx, "authPGRepo.GetByID")
+	defer span.End()
 
 	user := &models.User{}
 	if err := r.db.QueryRowxContext(ctx, getUserQuery, userID).StructScan(user); err != nil {
@@ -124,8 +144,8 @@ func (r *authRepo) GetByID(ctx context.Context, userID uuid.UUID) (*models.User,
 
 // FindByEmail searches user by email
 func (r *authRepo) FindByEmail(ctx context.Context, user *models.User) (*models.User, error) {
-	span, ctx := opentracing.StartSpanFromContext(ctx, "authPGRepo.FindByEmail")
-	defer span.Finish()
+	ctx, span := tracing.StartSpan(ctx, "authPGRepo.FindByEmail")
+	defer span.End()
 
 	foundUser := &models.User{}
 	if err := r.db.QueryRowxContext(ctx, findUserByEmail,
@@ -134,3 +154,42 @@ func (r *authRepo) FindByEmail(ctx context.Context, user *models.User) (*models.
 	}
 	return foundUser, nil
 }
+
+// UpdateLoginDate sets the user login date to now
+func (r *authRepo) UpdateLoginDate(ctx context.Context, userID uuid.UUID) (time.Time, error) {
+	ctx, span := tracing.StartSpan(ctx, "authPGRepo.UpdateLoginDate")
+	defer span.End()
+
+	var loginDate time.Time
+	if err := r.db.QueryRowxContext(ctx, updateLoginDateQuery, userID).Scan(&loginDate); err != nil {
+		return time.Time{}, errors.Wrap(err, "authPGRepo.UpdateLoginDate.QueryRowxContext")
+	}
+	return loginDate, nil
+}
+
+// CreateLoginEvent stores a login attempt
+func (r *authRepo) CreateLoginEvent(ctx context.Context, event *models.LoginEvent) error {
+	ctx, span := tracing.StartSpan(ctx, "authPGRepo.CreateLoginEvent")
+	defer span.End()
+
+	if _, err := r.db.ExecContext(ctx, createLoginEventQuery, event.UserID, event.Email,
+		event.IPAddress, event.UserAgent, event.Success, event.FailureReason,
+	); err != nil {
+		return errors.Wrap(err, "authPGRepo.CreateLoginEvent.ExecContext")
+	}
+	return nil
+}
+
+// GetLoginEvents retrieves the latest login attempts of a user
+func (r *authRepo) GetLoginEvents(
+	ctx context.Context, userID uuid.UUID, limit int,
+) ([]*models.LoginEvent, error) {
+	ctx, span := tracing.StartSpan(ctx, "authPGRepo.GetLoginEvents")
+	defer span.End()
+
+	events := make([]*models.LoginEvent, 0, limit)
+	if err := r.db.SelectContext(ctx, &events, getLoginEventsQuery, userID, limit); err != nil {
+		return nil, errors.Wrap(err, "authPGRepo.GetLoginEvents.SelectContext")
+	}
+	return events, nil
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
@@ -196,3 +197,92 @@ func TestAuthRepo_FindByEmail(t *testing.T) {
 		require.Equal(t, foundUser.FirstName, testUser.FirstName)
 	})
 }
+
+func TestAuthRepo_UpdateLoginDate(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	authRepo := NewAuthRepository(sqlxDB)
+
+	t.Run("UpdateLoginDate", func(t *testing.T) {
+		uid := uuid.New()
+		loginDate := time.Now()
+
+		rows := sqlmock.NewRows([]string{"login_date"}).AddRow(loginDate)
+
+		mock.ExpectQuery(updateLoginDateQuery).WithArgs(uid).WillReturnRows(rows)
+
+		updatedLoginDate, err := authRepo.UpdateLoginDate(context.Background(), uid)
+		require.NoError(t, err)
+		require.Equal(t, loginDate, updatedLoginDate)
+	})
+}
+
+func TestAuthRepo_CreateLoginEvent(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	authRepo := NewAuthRepository(sqlxDB)
+
+	t.Run("CreateLoginEvent", func(t *testing.T) {
+		uid := uuid.New()
+		failureReason := models.LoginFailureInvalidPassword
+		event := &models.LoginEvent{
+			UserID:        &uid,
+			Email:         "nick.pap@gmail.com",
+			IPAddress:     "127.0.0.1",
+			UserAgent:     "Go-http-client/1.1",
+			Success:       false,
+			FailureReason: &failureReason,
+		}
+
+		mock.ExpectExec(createLoginEventQuery).WithArgs(event.UserID, event.Email,
+			event.IPAddress, event.UserAgent, event.Success, event.FailureReason).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		err := authRepo.CreateLoginEvent(context.Background(), event)
+		require.NoError(t, err)
+	})
+}
+
+func TestAuthRepo_GetLoginEvents(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	authRepo := NewAuthRepository(sqlxDB)
+
+	t.Run("GetLoginEvents", func(t *testing.T) {
+		uid := uuid.New()
+
+		rows := sqlmock.NewRows([]string{
+			"login_event_id", "user_id", "email", "ip_address", "user_agent", "success",
+		}).AddRow(uuid.New(), uid, "nick.pap@gmail.com", "127.0.0.1", "curl/8.0", true).
+			AddRow(uuid.New(), uid, "nick.pap@gmail.com", "127.0.0.1", "curl/8.0", false)
+
+		mock.ExpectQuery(getLoginEventsQuery).WithArgs(uid, 10).WillReturnRows(rows)
+
+		events, err := authRepo.GetLoginEvents(context.Background(), uid, 10)
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		require.True(t, events[0].Success)
+		require.False(t, events[1].Success)
+	})
+}
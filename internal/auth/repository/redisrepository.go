@@ -1,70 +1,61 @@
 package repository
 
 import (
+	"companies-service/config"
 	"companies-service/internal/auth"
 	"companies-service/internal/models"
+	"companies-service/pkg/cache"
+	"companies-service/pkg/logger"
+	"companies-service/pkg/metric"
+	"companies-service/pkg/tracing"
 	"context"
-	"encoding/json"
-	"time"
+	"database/sql"
 
-	"github.com/opentracing/opentracing-go"
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"github.com/redis/go-redis/v9"
 )
 
+const basePrefix = "api-auth"
+
 // Auth redis repository
 type authRedisRepo struct {
-	redisClient *redis.Client
+	cache *cache.Cache[models.User]
 }
 
 // Auth Redis Repository constructor
-func NewAuthRedisRepo(redisClient *redis.Client) auth.RedisRepository {
-	return &authRedisRepo{redisClient: redisClient}
+func NewAuthRedisRepo(
+	redisClient *redis.Client,
+	cfg *config.Config,
+	metrics metric.CacheMetrics,
+	invalidator *cache.Invalidator,
+	logger logger.Logger,
+) auth.RedisRepository {
+	opts := cache.OptionsFromConfig(basePrefix, cfg.Cache.Users, sql.ErrNoRows, invalidator)
+	return &authRedisRepo{cache: cache.New[models.User](redisClient, opts, metrics, logger)}
 }
 
-// GetByIDCtx gets user by id
-func (a *authRedisRepo) GetByIDCtx(ctx context.Context, key string) (*models.User, error) {
-	span, ctx := opentracing.StartSpanFromContext(ctx, "authRedisRepo.GetByIDCtx")
-	defer span.Finish()
+// GetByIDCtx gets user by id, loading and caching it on a miss
+func (a *authRedisRepo) GetByIDCtx(
+	ctx context.Context, userID uuid.UUID, load cache.Loader[models.User],
+) (*models.User, error) {
+	ctx, span := tracing.StartSpan(ctx, "authRedisRepo.GetByIDCtx")
+	defer span.End()
 
-	userBytes, err := a.redisClient.Get(ctx, key).Bytes()
+	user, err := a.cache.GetOrLoad(ctx, userID.String(), load)
 	if err != nil {
-		return nil, errors.Wrap(err, "authRedisRepo.GetByIDCtx.redisClient.Get")
-	}
-	user := &models.User{}
-	if err = json.Unmarshal(userBytes, user); err != nil {
-		return nil, errors.Wrap(err, "authRedisRepo.GetByIDCtx.json.Unmarshal")
+		return nil, errors.Wrap(err, "authRedisRepo.GetByIDCtx.cache.GetOrLoad")
 	}
 	return user, nil
 }
 
-// SetUserCtx caches user with duration in seconds
-func (a *authRedisRepo) SetUserCtx(
-	ctx context.Context, key string, seconds int, user *models.User,
-) error {
-	span, ctx := opentracing.StartSpanFromContext(ctx, "authRedisRepo.SetUserCtx")
-	defer span.Finish()
-
-	userBytes, err := json.Marshal(user)
-	if err != nil {
-		return errors.Wrap(err, "authRedisRepo.SetUserCtx.json.Unmarshal")
-	}
-
-	if err = a.redisClient.Set(ctx, key, userBytes,
-		time.Second*time.Duration(seconds)).Err(); err != nil {
-		return errors.Wrap(err, "authRedisRepo.SetUserCtx.redisClient.Set")
-	}
-
-	return nil
-}
-
-// DeleteUserCtx deletes user by key
-func (a *authRedisRepo) DeleteUserCtx(ctx context.Context, key string) error {
-	span, ctx := opentracing.StartSpanFromContext(ctx, "authRedisRepo.DeleteUserCtx")
-	defer span.Finish()
+// DeleteUserCtx deletes cached user by id
+func (a *authRedisRepo) DeleteUserCtx(ctx context.Context, userID uuid.UUID) error {
+	ctx, span := tracing.StartSpan(ctx, "authRedisRepo.DeleteUserCtx")
+	defer span.End()
 
-	if err := a.redisClient.Del(ctx, key).Err(); err != nil {
-		return errors.Wrap(err, "authRedisRepo.DeleteUserCtx.redisClient.Del")
+	if err := a.cache.Delete(ctx, userID.String()); err != nil {
+		return errors.Wrap(err, "authRedisRepo.DeleteUserCtx.cache.Delete")
 	}
 
 	return nil
@@ -1,14 +1,22 @@
 package repository
 
 import (
+	"companies-service/config"
 	"companies-service/internal/auth"
 	"companies-service/internal/models"
+	"companies-service/pkg/cache"
+	"companies-service/pkg/logger"
 	"context"
+	"database/sql"
 	"log"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
-	"github.com/alicebob/miniredis"
+	"github.com/alicebob/miniredis/v2"
 	"github.com/google/uuid"
+	"github.com/pkg/errors"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/require"
 )
@@ -22,7 +30,17 @@ func SetupRedis() auth.RedisRepository {
 		Addr: mr.Addr(),
 	})
 
-	authRedisRepo := NewAuthRedisRepo(client)
+	cfg := &config.Config{
+		Cache: config.Cache{
+			Users: config.CacheConfig{
+				TTL:           10,
+				NegativeTTL:   10,
+				JitterPercent: 10,
+			},
+		},
+	}
+
+	authRedisRepo := NewAuthRedisRepo(client, cfg, nil, nil, logger.NewApiLogger(cfg))
 	return authRedisRepo
 }
 
@@ -32,7 +50,6 @@ func TestAuthRedisRepo_GetByIDCtx(t *testing.T) {
 	authRedisRepo := SetupRedis()
 
 	t.Run("GetByIDCtx", func(t *testing.T) {
-		key := uuid.New().String()
 		userID := uuid.New()
 		u := &models.User{
 			UserID:    userID,
@@ -40,33 +57,64 @@ func TestAuthRedisRepo_GetByIDCtx(t *testing.T) {
 			LastName:  "Bryksin",
 		}
 
-		err := authRedisRepo.SetUserCtx(context.Background(), key, 10, u)
+		var loads int32
+		load := func(ctx context.Context) (*models.User, error) {
+			atomic.AddInt32(&loads, 1)
+			return u, nil
+		}
+
+		user, err := authRedisRepo.GetByIDCtx(context.Background(), userID, load)
 		require.NoError(t, err)
-		require.Nil(t, err)
+		require.Equal(t, u.FirstName, user.FirstName)
 
-		user, err := authRedisRepo.GetByIDCtx(context.Background(), key)
+		// The second lookup is served from the cache
+		user, err = authRedisRepo.GetByIDCtx(context.Background(), userID, load)
 		require.NoError(t, err)
-		require.NotNil(t, user)
+		require.Equal(t, u.FirstName, user.FirstName)
+		require.Equal(t, int32(1), atomic.LoadInt32(&loads))
 	})
-}
 
-func TestAuthRedisRepo_SetUserCtx(t *testing.T) {
-	t.Parallel()
+	t.Run("GetByIDCtx not found", func(t *testing.T) {
+		userID := uuid.New()
 
-	authRedisRepo := SetupRedis()
+		var loads int32
+		load := func(ctx context.Context) (*models.User, error) {
+			atomic.AddInt32(&loads, 1)
+			return nil, errors.Wrap(sql.ErrNoRows, "authPGRepo.GetByID.QueryRowxContext")
+		}
 
-	t.Run("SetUserCtx", func(t *testing.T) {
-		key := uuid.New().String()
+		_, err := authRedisRepo.GetByIDCtx(context.Background(), userID, load)
+		require.True(t, errors.Is(err, sql.ErrNoRows))
+
+		// The not found result is cached
+		_, err = authRedisRepo.GetByIDCtx(context.Background(), userID, load)
+		require.True(t, errors.Is(err, sql.ErrNoRows))
+		require.Equal(t, int32(1), atomic.LoadInt32(&loads))
+	})
+
+	t.Run("GetByIDCtx concurrent misses", func(t *testing.T) {
 		userID := uuid.New()
-		u := &models.User{
-			UserID:    userID,
-			FirstName: "Alex",
-			LastName:  "Bryksin",
+
+		var loads int32
+		load := func(ctx context.Context) (*models.User, error) {
+			atomic.AddInt32(&loads, 1)
+			time.Sleep(50 * time.Millisecond)
+			return &models.User{UserID: userID}, nil
 		}
 
-		err := authRedisRepo.SetUserCtx(context.Background(), key, 10, u)
-		require.NoError(t, err)
-		require.Nil(t, err)
+		wg := &sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				user, err := authRedisRepo.GetByIDCtx(context.Background(), userID, load)
+				require.NoError(t, err)
+				require.Equal(t, userID, user.UserID)
+			}()
+		}
+		wg.Wait()
+
+		require.Equal(t, int32(1), atomic.LoadInt32(&loads))
 	})
 }
 
@@ -76,10 +124,89 @@ func TestAuthRedisRepo_DeleteUserCtx(t *testing.T) {
 	authRedisRepo := SetupRedis()
 
 	t.Run("DeleteUserCtx", func(t *testing.T) {
-		key := uuid.New().String()
+		userID := uuid.New()
+
+		var loads int32
+		load := func(ctx context.Context) (*models.User, error) {
+			atomic.AddInt32(&loads, 1)
+			return &models.User{UserID: userID}, nil
+		}
 
-		err := authRedisRepo.DeleteUserCtx(context.Background(), key)
+		_, err := authRedisRepo.GetByIDCtx(context.Background(), userID, load)
+		require.NoError(t, err)
+
+		err = authRedisRepo.DeleteUserCtx(context.Background(), userID)
 		require.NoError(t, err)
 		require.Nil(t, err)
+
+		// The user is loaded again after the deletion
+		_, err = authRedisRepo.GetByIDCtx(context.Background(), userID, load)
+		require.NoError(t, err)
+		require.Equal(t, int32(2), atomic.LoadInt32(&loads))
 	})
 }
+
+func TestAuthRedisRepo_Invalidation(t *testing.T) {
+	t.Parallel()
+
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Mode: "Development",
+		},
+		Cache: config.Cache{
+			InvalidationChannel: "api-cache-invalidations",
+			Users: config.CacheConfig{
+				TTL:       10,
+				LocalSize: 10,
+				LocalTTL:  10,
+			},
+		},
+	}
+	apiLogger := logger.NewApiLogger(cfg)
+	apiLogger.InitLogger()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Two instances sharing the same redis
+	newInstance := func() auth.RedisRepository {
+		client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+		invalidator := cache.NewInvalidator(client, cfg.Cache.InvalidationChannel, apiLogger)
+		go invalidator.Run(ctx)
+		return NewAuthRedisRepo(client, cfg, nil, invalidator, apiLogger)
+	}
+	firstInstance := newInstance()
+	secondInstance := newInstance()
+
+	// Wait for both subscriptions
+	require.Eventually(t, func() bool {
+		return mr.PubSubNumSub(cfg.Cache.InvalidationChannel)[cfg.Cache.InvalidationChannel] == 2
+	}, time.Second, 10*time.Millisecond)
+
+	userID := uuid.New()
+	load := func(firstName string) func(ctx context.Context) (*models.User, error) {
+		return func(ctx context.Context) (*models.User, error) {
+			return &models.User{UserID: userID, FirstName: firstName}, nil
+		}
+	}
+
+	// Both instances hold the user in their in-process tier
+	user, err := firstInstance.GetByIDCtx(context.Background(), userID, load("Alex"))
+	require.NoError(t, err)
+	require.Equal(t, "Alex", user.FirstName)
+	user, err = secondInstance.GetByIDCtx(context.Background(), userID, load("Alex"))
+	require.NoError(t, err)
+	require.Equal(t, "Alex", user.FirstName)
+
+	// The first instance updates the user, the second one drops its stale entry
+	require.NoError(t, firstInstance.DeleteUserCtx(context.Background(), userID))
+
+	require.Eventually(t, func() bool {
+		user, err := secondInstance.GetByIDCtx(context.Background(), userID, load("Alexander"))
+		return err == nil && user.FirstName == "Alexander"
+	}, time.Second, 10*time.Millisecond)
+}
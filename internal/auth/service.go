@@ -10,8 +10,11 @@ import (
 // Auth service interface
 type Service interface {
 	Register(ctx context.Context, user *models.User) (*models.UserWithToken, error)
-	Login(ctx context.Context, user *models.User) (*models.UserWithToken, error)
+	Login(
+		ctx context.Context, user *models.User, client *models.LoginClient,
+	) (*models.UserWithToken, error)
 	Update(ctx context.Context, user *models.User) (*models.User, error)
 	Delete(ctx context.Context, userID uuid.UUID) error
 	GetByID(ctx context.Context, userID uuid.UUID) (*models.User, error)
+	GetLoginEvents(ctx context.Context, userID uuid.UUID) ([]*models.LoginEvent, error)
 }
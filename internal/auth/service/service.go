@@ -7,25 +7,24 @@ import (
 	"companies-service/pkg/authn"
 	"companies-service/pkg/httphelper"
 	"companies-service/pkg/logger"
+	"companies-service/pkg/metric"
+	"companies-service/pkg/tracing"
 	"context"
-	"fmt"
+	"database/sql"
 	"net/http"
 
 	"github.com/google/uuid"
-	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 )
 
-const (
-	basePrefix    = "api-auth:"
-	cacheDuration = 3600
-)
+const loginEventsLimit = 50
 
 // Auth Service
 type authService struct {
 	cfg       *config.Config
 	authRepo  auth.Repository
 	redisRepo auth.RedisRepository
+	metrics   metric.BusinessMetrics
 	logger    logger.Logger
 }
 
@@ -34,35 +33,41 @@ func NewAuthService(
 	cfg *config.Config,
 	authRepo auth.Repository,
 	redisRepo auth.RedisRepository,
+	metrics metric.BusinessMetrics,
 	log logger.Logger,
 ) auth.Service {
-	return &authService{cfg: cfg, authRepo: authRepo, redisRepo: redisRepo, logger: log}
+	return &authService{cfg: cfg, authRepo: authRepo, redisRepo: redisRepo, metrics: metrics,
+		logger: log}
 }
 
 // Create new user
 func (s *authService) Register(
 	ctx context.Context, user *models.User,
 ) (*models.UserWithToken, error) {
-	span, ctx := opentracing.StartSpanFromContext(ctx, "authService.Register")
-	defer span.Finish()
+	ctx, span := tracing.StartSpan(ctx, "authService.Register")
+	defer span.End()
 
 	existsUser, err := s.authRepo.FindByEmail(ctx, user)
 	if existsUser != nil || err == nil {
+		s.incRegistrations(metric.OutcomeConflict)
 		return nil,
-			httphelper.NewRestErrorWithMessage(http.StatusBadRequest,
-				httphelper.ErrEmailAlreadyExists, nil)
+			httphelper.NewRestErrorWithCode(http.StatusConflict, httphelper.CodeUserEmailExists,
+				httphelper.ErrExistsEmailError.Error(), nil)
 	}
 
 	if err = user.PrepareCreate(); err != nil {
+		s.incRegistrations(metric.OutcomeError)
 		return nil,
 			httphelper.NewBadRequestError(errors.Wrap(err, "authService.Register.PrepareCreate"))
 	}
 
 	createdUser, err := s.authRepo.Register(ctx, user)
 	if err != nil {
+		s.incRegistrations(metric.OutcomeError)
 		return nil, err
 	}
 	createdUser.SanitizePassword()
+	s.incRegistrations(metric.OutcomeSuccess)
 
 	token, err := authn.GenerateJWTToken(createdUser, s.cfg)
 	if err != nil {
@@ -79,8 +84,8 @@ func (s *authService) Register(
 
 // Update existing user
 func (s *authService) Update(ctx context.Context, user *models.User) (*models.User, error) {
-	span, ctx := opentracing.StartSpanFromContext(ctx, "authService.Update")
-	defer span.Finish()
+	ctx, span := tracing.StartSpan(ctx, "authService.Update")
+	defer span.End()
 
 	if err := user.PrepareUpdate(); err != nil {
 		return nil,
@@ -94,8 +99,8 @@ func (s *authService) Update(ctx context.Context, user *models.User) (*models.Us
 
 	updatedUser.SanitizePassword()
 
-	if err = s.redisRepo.DeleteUserCtx(ctx, s.GenerateUserKey(user.UserID.String())); err != nil {
-		s.logger.Errorf("AuthService.Update.DeleteUserCtx: %s", err)
+	if err = s.redisRepo.DeleteUserCtx(ctx, user.UserID); err != nil {
+		logger.FromContext(ctx, s.logger).Errorf("AuthService.Update.DeleteUserCtx: %s", err)
 	}
 
 	return updatedUser, nil
@@ -103,15 +108,15 @@ func (s *authService) Update(ctx context.Context, user *models.User) (*models.Us
 
 // Delete user
 func (s *authService) Delete(ctx context.Context, userID uuid.UUID) error {
-	span, ctx := opentracing.StartSpanFromContext(ctx, "authService.Delete")
-	defer span.Finish()
+	ctx, span := tracing.StartSpan(ctx, "authService.Delete")
+	defer span.End()
 
 	if err := s.authRepo.Delete(ctx, userID); err != nil {
 		return err
 	}
 
-	if err := s.redisRepo.DeleteUserCtx(ctx, s.GenerateUserKey(userID.String())); err != nil {
-		s.logger.Errorf("AuthService.Delete.DeleteUserCtx: %s", err)
+	if err := s.redisRepo.DeleteUserCtx(ctx, userID); err != nil {
+		logger.FromContext(ctx, s.logger).Errorf("AuthService.Delete.DeleteUserCtx: %s", err)
 	}
 
 	return nil
@@ -119,27 +124,17 @@ func (s *authService) Delete(ctx context.Context, userID uuid.UUID) error {
 
 // Get user by id
 func (u *authService) GetByID(ctx context.Context, userID uuid.UUID) (*models.User, error) {
-	span, ctx := opentracing.StartSpanFromContext(ctx, "authService.GetByID")
-	defer span.Finish()
-
-	cachedUser, err := u.redisRepo.GetByIDCtx(ctx, u.GenerateUserKey(userID.String()))
-	if err != nil {
-		u.logger.Errorf("authService.GetByID.GetByIDCtx: %v", err)
-	}
-	if cachedUser != nil {
-		return cachedUser, nil
-	}
+	ctx, span := tracing.StartSpan(ctx, "authService.GetByID")
+	defer span.End()
 
-	user, err := u.authRepo.GetByID(ctx, userID)
+	user, err := u.redisRepo.GetByIDCtx(ctx, userID,
+		func(ctx context.Context) (*models.User, error) {
+			return u.authRepo.GetByID(ctx, userID)
+		})
 	if err != nil {
 		return nil, err
 	}
 
-	if err = u.redisRepo.SetUserCtx(ctx,
-		u.GenerateUserKey(userID.String()), cacheDuration, user); err != nil {
-		u.logger.Errorf("authService.GetByID.SetUserCtx: %v", err)
-	}
-
 	user.SanitizePassword()
 
 	return user, nil
@@ -147,30 +142,55 @@ func (u *authService) GetByID(ctx context.Context, userID uuid.UUID) (*models.Us
 
 // Login user, returns user model with jwt token
 func (s *authService) Login(
-	ctx context.Context, user *models.User,
+	ctx context.Context, user *models.User, client *models.LoginClient,
 ) (*models.UserWithToken, error) {
-	span, ctx := opentracing.StartSpanFromContext(ctx, "authService.Login")
-	defer span.Finish()
+	ctx, span := tracing.StartSpan(ctx, "authService.Login")
+	defer span.End()
 
 	foundUser, err := s.authRepo.FindByEmail(ctx, user)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.incLogins(metric.OutcomeUserNotFound)
+			s.recordLoginEvent(ctx, nil, user.Email, client, models.LoginFailureUserNotFound)
+		} else {
+			s.incLogins(metric.OutcomeError)
+		}
 		return nil, err
 	}
 
 	if err = foundUser.ComparePasswords(user.Password); err != nil {
+		s.incLogins(metric.OutcomeInvalidPassword)
+		s.recordLoginEvent(ctx, &foundUser.UserID, user.Email, client,
+			models.LoginFailureInvalidPassword)
 		return nil,
-			httphelper.NewUnauthorizedError(errors.Wrap(err,
-				"authService.GetUsers.ComparePasswords"))
+			httphelper.NewRestErrorWithCode(http.StatusUnauthorized, httphelper.CodeWrongCredentials,
+				httphelper.ErrWrongCredentials.Error(),
+				errors.Wrap(err, "authService.GetUsers.ComparePasswords"))
 	}
 
+	loginDate, err := s.authRepo.UpdateLoginDate(ctx, foundUser.UserID)
+	if err != nil {
+		s.incLogins(metric.OutcomeError)
+		return nil, err
+	}
+	foundUser.LoginDate = loginDate
+
+	if err = s.redisRepo.DeleteUserCtx(ctx, foundUser.UserID); err != nil {
+		logger.FromContext(ctx, s.logger).Errorf("AuthService.Login.DeleteUserCtx: %s", err)
+	}
+
+	s.recordLoginEvent(ctx, &foundUser.UserID, user.Email, client, "")
+
 	foundUser.SanitizePassword()
 
 	token, err := authn.GenerateJWTToken(foundUser, s.cfg)
 	if err != nil {
+		s.incLogins(metric.OutcomeError)
 		return nil,
 			httphelper.NewInternalServerError(errors.Wrap(err,
 				"authService.GetUsers.GenerateJWTToken"))
 	}
+	s.incLogins(metric.OutcomeSuccess)
 
 	return &models.UserWithToken{
 		User:  foundUser,
@@ -178,6 +198,51 @@ func (s *authService) Login(
 	}, nil
 }
 
-func (s *authService) GenerateUserKey(userID string) string {
-	return fmt.Sprintf("%s: %s", basePrefix, userID)
+// Get the latest login attempts of a user
+func (s *authService) GetLoginEvents(
+	ctx context.Context, userID uuid.UUID,
+) ([]*models.LoginEvent, error) {
+	ctx, span := tracing.StartSpan(ctx, "authService.GetLoginEvents")
+	defer span.End()
+
+	return s.authRepo.GetLoginEvents(ctx, userID, loginEventsLimit)
+}
+
+// recordLoginEvent stores a login attempt, an empty failure reason marks a successful login.
+// Failures are only logged so that login history never blocks a login.
+func (s *authService) recordLoginEvent(
+	ctx context.Context,
+	userID *uuid.UUID,
+	email string,
+	client *models.LoginClient,
+	failureReason string,
+) {
+	event := &models.LoginEvent{
+		UserID:  userID,
+		Email:   email,
+		Success: failureReason == "",
+	}
+	if client != nil {
+		event.IPAddress = client.IPAddress
+		event.UserAgent = client.UserAgent
+	}
+	if failureReason != "" {
+		event.FailureReason = &failureReason
+	}
+
+	if err := s.authRepo.CreateLoginEvent(ctx, event); err != nil {
+		logger.FromContext(ctx, s.logger).Errorf("AuthService.Login.CreateLoginEvent: %s", err)
+	}
+}
+
+func (s *authService) incRegistrations(outcome string) {
+	if s.metrics != nil {
+		s.metrics.IncRegistrations(outcome)
+	}
+}
+
+func (s *authService) incLogins(outcome string) {
+	if s.metrics != nil {
+		s.metrics.IncLogins(outcome)
+	}
 }
@@ -4,14 +4,16 @@ import (
 	"companies-service/config"
 	"companies-service/internal/auth/mock"
 	"companies-service/internal/models"
+	"companies-service/pkg/cache"
 	"companies-service/pkg/logger"
+	"companies-service/pkg/tracing"
 	"context"
 	"database/sql"
-	"fmt"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
-	"github.com/opentracing/opentracing-go"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -36,7 +38,7 @@ func TestAuthService_Register(t *testing.T) {
 
 	apiLogger := logger.NewApiLogger(cfg)
 	mockAuthRepo := mock.NewMockRepository(ctrl)
-	authService := NewAuthService(cfg, mockAuthRepo, nil, apiLogger)
+	authService := NewAuthService(cfg, mockAuthRepo, nil, nil, apiLogger)
 
 	user := &models.User{
 		Password: "123456",
@@ -44,8 +46,8 @@ func TestAuthService_Register(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	span, ctxWithTrace := opentracing.StartSpanFromContext(ctx, "authService.Register")
-	defer span.Finish()
+	ctxWithTrace, span := tracing.StartSpan(ctx, "authService.Register")
+	defer span.End()
 
 	mockAuthRepo.EXPECT().FindByEmail(ctxWithTrace, gomock.Eq(user)).Return(nil, sql.ErrNoRows)
 	mockAuthRepo.EXPECT().Register(ctxWithTrace, gomock.Eq(user)).Return(user, nil)
@@ -78,20 +80,19 @@ func TestAuthService_Update(t *testing.T) {
 
 	mockAuthRepo := mock.NewMockRepository(ctrl)
 	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
-	authUC := NewAuthService(cfg, mockAuthRepo, mockRedisRepo, apiLogger)
+	authUC := NewAuthService(cfg, mockAuthRepo, mockRedisRepo, nil, apiLogger)
 
 	user := &models.User{
 		Password: "123456",
 		Email:    "nick.pap@gmail.com",
 	}
-	key := fmt.Sprintf("%s: %s", basePrefix, user.UserID)
 
 	ctx := context.Background()
-	span, ctxWithTrace := opentracing.StartSpanFromContext(ctx, "authService.Update")
-	defer span.Finish()
+	ctxWithTrace, span := tracing.StartSpan(ctx, "authService.Update")
+	defer span.End()
 
 	mockAuthRepo.EXPECT().Update(ctxWithTrace, gomock.Eq(user)).Return(user, nil)
-	mockRedisRepo.EXPECT().DeleteUserCtx(ctxWithTrace, key).Return(nil)
+	mockRedisRepo.EXPECT().DeleteUserCtx(ctxWithTrace, user.UserID).Return(nil)
 
 	updatedUser, err := authUC.Update(ctx, user)
 	require.NoError(t, err)
@@ -121,20 +122,19 @@ func TestAuthService_Delete(t *testing.T) {
 
 	mockAuthRepo := mock.NewMockRepository(ctrl)
 	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
-	authUC := NewAuthService(cfg, mockAuthRepo, mockRedisRepo, apiLogger)
+	authUC := NewAuthService(cfg, mockAuthRepo, mockRedisRepo, nil, apiLogger)
 
 	user := &models.User{
 		Password: "123456",
 		Email:    "nick.pap@gmail.com",
 	}
-	key := fmt.Sprintf("%s: %s", basePrefix, user.UserID)
 
 	ctx := context.Background()
-	span, ctxWithTrace := opentracing.StartSpanFromContext(ctx, "authService.Delete")
-	defer span.Finish()
+	ctxWithTrace, span := tracing.StartSpan(ctx, "authService.Delete")
+	defer span.End()
 
 	mockAuthRepo.EXPECT().Delete(ctxWithTrace, gomock.Eq(user.UserID)).Return(nil)
-	mockRedisRepo.EXPECT().DeleteUserCtx(ctxWithTrace, key).Return(nil)
+	mockRedisRepo.EXPECT().DeleteUserCtx(ctxWithTrace, user.UserID).Return(nil)
 
 	err := authUC.Delete(ctx, user.UserID)
 	require.NoError(t, err)
@@ -162,21 +162,23 @@ func TestAuthService_GetByID(t *testing.T) {
 	apiLogger := logger.NewApiLogger(cfg)
 	mockAuthRepo := mock.NewMockRepository(ctrl)
 	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
-	authUC := NewAuthService(cfg, mockAuthRepo, mockRedisRepo, apiLogger)
+	authUC := NewAuthService(cfg, mockAuthRepo, mockRedisRepo, nil, apiLogger)
 
 	user := &models.User{
 		Password: "123456",
 		Email:    "nick.pap@gmail.com",
 	}
-	key := fmt.Sprintf("%s: %s", basePrefix, user.UserID)
 
 	ctx := context.Background()
-	span, ctxWithTrace := opentracing.StartSpanFromContext(ctx, "authService.GetByID")
-	defer span.Finish()
-
-	mockRedisRepo.EXPECT().GetByIDCtx(ctxWithTrace, key).Return(nil, nil)
+	ctxWithTrace, span := tracing.StartSpan(ctx, "authService.GetByID")
+	defer span.End()
+
+	// The cache misses and calls the loader
+	mockRedisRepo.EXPECT().GetByIDCtx(ctxWithTrace, user.UserID, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, _ uuid.UUID, load cache.Loader[models.User]) (*models.User, error) {
+			return load(ctx)
+		})
 	mockAuthRepo.EXPECT().GetByID(ctxWithTrace, gomock.Eq(user.UserID)).Return(user, nil)
-	mockRedisRepo.EXPECT().SetUserCtx(ctxWithTrace, key, cacheDuration, user).Return(nil)
 
 	u, err := authUC.GetByID(ctx, user.UserID)
 	require.NoError(t, err)
@@ -205,11 +207,11 @@ func TestAuthService_Login(t *testing.T) {
 	apiLogger := logger.NewApiLogger(cfg)
 	mockAuthRepo := mock.NewMockRepository(ctrl)
 	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
-	authUC := NewAuthService(cfg, mockAuthRepo, mockRedisRepo, apiLogger)
+	authUC := NewAuthService(cfg, mockAuthRepo, mockRedisRepo, nil, apiLogger)
 
 	ctx := context.Background()
-	span, ctxWithTrace := opentracing.StartSpanFromContext(ctx, "authService.Login")
-	defer span.Finish()
+	ctxWithTrace, span := tracing.StartSpan(ctx, "authService.Login")
+	defer span.End()
 
 	user := &models.User{
 		Password: "123456",
@@ -224,10 +226,113 @@ func TestAuthService_Login(t *testing.T) {
 		Password: string(hashPassword),
 	}
 
-	mockAuthRepo.EXPECT().FindByEmail(ctxWithTrace, gomock.Eq(user)).Return(mockUser, nil)
+	client := &models.LoginClient{
+		IPAddress: "127.0.0.1",
+		UserAgent: "Go-http-client/1.1",
+	}
+	loginDate := time.Now()
 
-	userWithToken, err := authUC.Login(ctx, user)
+	mockAuthRepo.EXPECT().FindByEmail(ctxWithTrace, gomock.Eq(user)).Return(mockUser, nil)
+	mockAuthRepo.EXPECT().UpdateLoginDate(ctxWithTrace,
+		gomock.Eq(mockUser.UserID)).Return(loginDate, nil)
+	mockRedisRepo.EXPECT().DeleteUserCtx(ctxWithTrace, mockUser.UserID).Return(nil)
+	mockAuthRepo.EXPECT().CreateLoginEvent(ctxWithTrace, gomock.Eq(&models.LoginEvent{
+		UserID:    &mockUser.UserID,
+		Email:     user.Email,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+		Success:   true,
+	})).Return(nil)
+
+	userWithToken, err := authUC.Login(ctx, user, client)
 	require.NoError(t, err)
 	require.Nil(t, err)
 	require.NotNil(t, userWithToken)
+	require.Equal(t, loginDate, userWithToken.User.LoginDate)
+}
+
+func TestAuthService_Login_WrongPassword(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			JwtSecretKey: "secret",
+		},
+		Logger: config.Logger{
+			Development:       true,
+			DisableCaller:     false,
+			DisableStacktrace: false,
+			Encoding:          "json",
+		},
+	}
+
+	apiLogger := logger.NewApiLogger(cfg)
+	mockAuthRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	authUC := NewAuthService(cfg, mockAuthRepo, mockRedisRepo, nil, apiLogger)
+
+	ctx := context.Background()
+	ctxWithTrace, span := tracing.StartSpan(ctx, "authService.Login")
+	defer span.End()
+
+	user := &models.User{
+		Password: "wrong-password",
+		Email:    "nick.pap@gmail.com",
+	}
+
+	hashPassword, err := bcrypt.GenerateFromPassword([]byte("123456"), bcrypt.DefaultCost)
+	require.NoError(t, err)
+
+	mockUser := &models.User{
+		UserID:   uuid.New(),
+		Email:    "nick.pap@gmail.com",
+		Password: string(hashPassword),
+	}
+	failureReason := models.LoginFailureInvalidPassword
+
+	mockAuthRepo.EXPECT().FindByEmail(ctxWithTrace, gomock.Eq(user)).Return(mockUser, nil)
+	mockAuthRepo.EXPECT().CreateLoginEvent(ctxWithTrace, gomock.Eq(&models.LoginEvent{
+		UserID:        &mockUser.UserID,
+		Email:         user.Email,
+		Success:       false,
+		FailureReason: &failureReason,
+	})).Return(nil)
+
+	userWithToken, err := authUC.Login(ctx, user, nil)
+	require.Error(t, err)
+	require.Nil(t, userWithToken)
+}
+
+func TestAuthService_GetLoginEvents(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		Logger: config.Logger{
+			Development: true,
+		},
+	}
+
+	apiLogger := logger.NewApiLogger(cfg)
+	mockAuthRepo := mock.NewMockRepository(ctrl)
+	authUC := NewAuthService(cfg, mockAuthRepo, nil, nil, apiLogger)
+
+	userID := uuid.New()
+	loginEvents := []*models.LoginEvent{{UserID: &userID, Success: true}}
+
+	ctx := context.Background()
+	ctxWithTrace, span := tracing.StartSpan(ctx, "authService.GetLoginEvents")
+	defer span.End()
+
+	mockAuthRepo.EXPECT().GetLoginEvents(ctxWithTrace, gomock.Eq(userID),
+		loginEventsLimit).Return(loginEvents, nil)
+
+	events, err := authUC.GetLoginEvents(ctx, userID)
+	require.NoError(t, err)
+	require.Equal(t, loginEvents, events)
 }
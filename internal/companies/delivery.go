@@ -1,7 +1,11 @@
 package companies
 
 import (
+	"companies-service/pkg/events"
+	"context"
+
 	"github.com/gin-gonic/gin"
+	"github.com/segmentio/kafka-go"
 )
 
 // Companies HTTP Handlers interface
@@ -11,3 +15,8 @@ type Handlers interface {
 	Delete(c *gin.Context)
 	GetByID(c *gin.Context)
 }
+
+// Companies Kafka consumer handlers interface
+type ConsumerHandlers interface {
+	CompanyUpdated(ctx context.Context, msg kafka.Message, event *events.Envelope) error
+}
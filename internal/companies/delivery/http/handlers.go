@@ -4,18 +4,16 @@ import (
 	"companies-service/config"
 	"companies-service/internal/companies"
 	"companies-service/internal/models"
+	"companies-service/pkg/events"
 	"companies-service/pkg/httphelper"
 	"companies-service/pkg/kafka"
 	"companies-service/pkg/logger"
 	"companies-service/pkg/tracing"
-	"encoding/json"
+	"context"
 	"net/http"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"github.com/opentracing/opentracing-go"
-	kafkaMessages "github.com/segmentio/kafka-go"
 )
 
 // Companies handlers
@@ -23,6 +21,7 @@ type companiesHandlers struct {
 	cfg            *config.Config
 	companyService companies.Service
 	kafkaProducer  kafka.Producer
+	eventEncoder   *events.Encoder
 	logger         logger.Logger
 }
 
@@ -31,10 +30,11 @@ func NewCompaniesHandlers(
 	cfg *config.Config,
 	companyService companies.Service,
 	kafkaProducer kafka.Producer,
+	eventEncoder *events.Encoder,
 	logger logger.Logger,
 ) companies.Handlers {
 	return &companiesHandlers{cfg: cfg, companyService: companyService,
-		kafkaProducer: kafkaProducer, logger: logger}
+		kafkaProducer: kafkaProducer, eventEncoder: eventEncoder, logger: logger}
 }
 
 // Create
@@ -44,12 +44,12 @@ func NewCompaniesHandlers(
 // @Accept  json
 // @Produce  json
 // @Success 201 {object} models.Company
-// @Failure 400 {object} httphelper.RestErr
-// @Failure 500 {object} httphelper.RestErr
+// @Failure 400 {object} httphelper.ProblemDetails
+// @Failure 500 {object} httphelper.ProblemDetails
 // @Router /companies [post]
 func (h *companiesHandlers) Create(c *gin.Context) {
-	span, ctx := opentracing.StartSpanFromContext(c, "companiesHandlers.Create")
-	defer span.Finish()
+	ctx, span := tracing.StartSpan(c, "companiesHandlers.Create")
+	defer span.End()
 
 	company := &models.Company{}
 
@@ -64,22 +64,9 @@ func (h *companiesHandlers) Create(c *gin.Context) {
 		return
 	}
 
-	// Prepare the kafka event
-	msgBytes, err := json.Marshal(createdCompany)
-	if err != nil {
-		httphelper.ErrResponseWithLog(c, h.logger, err)
-		return
-	}
-
-	message := kafkaMessages.Message{
-		Topic:   h.cfg.KafkaTopics.CompanyCreated.TopicName,
-		Value:   msgBytes,
-		Time:    time.Now().UTC(),
-		Headers: tracing.GetKafkaTracingHeadersFromSpanCtx(span.Context()),
-	}
-
 	// Publish a company created event to the kafka broker
-	err = h.kafkaProducer.PublishMessage(ctx, message)
+	err = h.publishCompanyEvent(ctx, c, h.cfg.KafkaTopics.CompanyCreated.TopicName,
+		events.CompanyCreated, createdCompany.CompanyID, companyEventPayload(createdCompany))
 	if err != nil {
 		httphelper.ErrResponseWithLog(c, h.logger, err)
 		return
@@ -96,15 +83,15 @@ func (h *companiesHandlers) Create(c *gin.Context) {
 // @Produce  json
 // @Param id path int true "company_id"
 // @Success 200 {object} models.Company
-// @Failure 400 {object} httphelper.RestErr
-// @Failure 404 {object} httphelper.RestErr
-// @Failure 500 {object} httphelper.RestErr
+// @Failure 400 {object} httphelper.ProblemDetails
+// @Failure 404 {object} httphelper.ProblemDetails
+// @Failure 500 {object} httphelper.ProblemDetails
 // @Router /companies/{id} [patch]
 func (h *companiesHandlers) Update(c *gin.Context) {
-	span, ctx := opentracing.StartSpanFromContext(c, "companiesHandlers.Update")
-	defer span.Finish()
+	ctx, span := tracing.StartSpan(c, "companiesHandlers.Update")
+	defer span.End()
 
-	companyID, err := uuid.Parse(c.Param("company_id"))
+	companyID, err := httphelper.ParseUUIDParam(c, "company_id")
 	if err != nil {
 		httphelper.ErrResponseWithLog(c, h.logger, err)
 		return
@@ -119,34 +106,21 @@ func (h *companiesHandlers) Update(c *gin.Context) {
 
 	company.CompanyID = companyID
 
-	updatedCompany, err := h.companyService.Update(ctx, company)
-	if err != nil {
-		httphelper.ErrResponseWithLog(c, h.logger, err)
-		return
-	}
-
-	// Prepare the kafka event
-	msgBytes, err := json.Marshal(updatedCompany)
+	update, err := h.companyService.Update(ctx, company)
 	if err != nil {
 		httphelper.ErrResponseWithLog(c, h.logger, err)
 		return
 	}
 
-	message := kafkaMessages.Message{
-		Topic:   h.cfg.KafkaTopics.CompanyUpdated.TopicName,
-		Value:   msgBytes,
-		Time:    time.Now().UTC(),
-		Headers: tracing.GetKafkaTracingHeadersFromSpanCtx(span.Context()),
-	}
-
-	// Publish a company updated event to the kafka broker
-	err = h.kafkaProducer.PublishMessage(ctx, message)
+	// Publish a company updated event with the previous state to the kafka broker
+	err = h.publishCompanyEvent(ctx, c, h.cfg.KafkaTopics.CompanyUpdated.TopicName,
+		events.CompanyUpdated, update.Company.CompanyID, companyUpdateEventPayload(update))
 	if err != nil {
 		httphelper.ErrResponseWithLog(c, h.logger, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, updatedCompany)
+	c.JSON(http.StatusOK, update.Company)
 }
 
 // Delete
@@ -157,14 +131,14 @@ func (h *companiesHandlers) Update(c *gin.Context) {
 // @Produce  json
 // @Param id path int true "company_id"
 // @Success 200 {string} string	"ok"
-// @Failure 404 {object} httphelper.RestErr
-// @Failure 500 {object} httphelper.RestErr
+// @Failure 404 {object} httphelper.ProblemDetails
+// @Failure 500 {object} httphelper.ProblemDetails
 // @Router /companies/{id} [delete]
 func (h *companiesHandlers) Delete(c *gin.Context) {
-	span, ctx := opentracing.StartSpanFromContext(c, "companiesHandlers.Delete")
-	defer span.Finish()
+	ctx, span := tracing.StartSpan(c, "companiesHandlers.Delete")
+	defer span.End()
 
-	companyID, err := uuid.Parse(c.Param("company_id"))
+	companyID, err := httphelper.ParseUUIDParam(c, "company_id")
 	if err != nil {
 		httphelper.ErrResponseWithLog(c, h.logger, err)
 		return
@@ -175,23 +149,9 @@ func (h *companiesHandlers) Delete(c *gin.Context) {
 		return
 	}
 
-	// Prepare the kafka event
-	msg := `{CompanyID: ` + companyID.String() + `}`
-	msgBytes, err := json.Marshal(msg)
-	if err != nil {
-		httphelper.ErrResponseWithLog(c, h.logger, err)
-		return
-	}
-
-	message := kafkaMessages.Message{
-		Topic:   h.cfg.KafkaTopics.CompanyDeleted.TopicName,
-		Value:   msgBytes,
-		Time:    time.Now().UTC(),
-		Headers: tracing.GetKafkaTracingHeadersFromSpanCtx(span.Context()),
-	}
-
 	// Publish a company deleted event to the kafka broker
-	err = h.kafkaProducer.PublishMessage(ctx, message)
+	err = h.publishCompanyEvent(ctx, c, h.cfg.KafkaTopics.CompanyDeleted.TopicName,
+		events.CompanyDeleted, companyID, events.DeletedCompany{CompanyID: companyID})
 	if err != nil {
 		httphelper.ErrResponseWithLog(c, h.logger, err)
 		return
@@ -210,14 +170,14 @@ func (h *companiesHandlers) Delete(c *gin.Context) {
 // @Produce  json
 // @Param id path int true "company_id"
 // @Success 200 {string} string	"ok"
-// @Failure 404 {object} httphelper.RestErr
-// @Failure 500 {object} httphelper.RestErr
+// @Failure 404 {object} httphelper.ProblemDetails
+// @Failure 500 {object} httphelper.ProblemDetails
 // @Router /companies/{id} [get]
 func (h *companiesHandlers) GetByID(c *gin.Context) {
-	span, ctx := opentracing.StartSpanFromContext(c, "companiesHandlers.GetByID")
-	defer span.Finish()
+	ctx, span := tracing.StartSpan(c, "companiesHandlers.GetByID")
+	defer span.End()
 
-	companyID, err := uuid.Parse(c.Param("company_id"))
+	companyID, err := httphelper.ParseUUIDParam(c, "company_id")
 	if err != nil {
 		httphelper.ErrResponseWithLog(c, h.logger, err)
 		return
@@ -231,3 +191,66 @@ func (h *companiesHandlers) GetByID(c *gin.Context) {
 
 	c.JSON(http.StatusOK, company)
 }
+
+// publishCompanyEvent publishes a company event caused by the request user in the configured
+// mode, the message is keyed by the company id
+func (h *companiesHandlers) publishCompanyEvent(
+	ctx context.Context,
+	c *gin.Context,
+	topic string,
+	eventType string,
+	companyID uuid.UUID,
+	payload interface{},
+) error {
+	event, err := events.NewCompanyEvent(eventType, companyID, eventActor(c), payload)
+	if err != nil {
+		return err
+	}
+
+	message, err := h.eventEncoder.Encode(event, topic)
+	if err != nil {
+		return err
+	}
+	message.Headers = append(message.Headers, tracing.GetKafkaTracingHeaders(ctx)...)
+
+	return h.kafkaProducer.PublishMessage(ctx, message)
+}
+
+// eventActor returns the authenticated user of the request as the actor of an event
+func eventActor(c *gin.Context) *events.Actor {
+	user, ok := c.Get("user")
+	if !ok {
+		return nil
+	}
+	authUser, ok := user.(*models.User)
+	if !ok || authUser == nil {
+		return nil
+	}
+	actor := &events.Actor{UserID: authUser.UserID}
+	if authUser.Role != nil {
+		actor.Role = *authUser.Role
+	}
+	return actor
+}
+
+// companyEventPayload returns the event payload of a company
+func companyEventPayload(company *models.Company) events.Company {
+	return events.Company{
+		CompanyID:          company.CompanyID,
+		CompanyName:        company.CompanyName,
+		CompanyDescription: company.CompanyDescription,
+		AmountOfEmployees:  company.AmountOfEmployees,
+		Registered:         company.Registered,
+		CompanyType:        company.CompanyType,
+	}
+}
+
+// companyUpdateEventPayload returns the event payload of a company update
+func companyUpdateEventPayload(update *models.CompanyUpdate) events.CompanyUpdate {
+	previous := companyEventPayload(update.Previous)
+	return events.CompanyUpdate{
+		Company:       companyEventPayload(update.Company),
+		Previous:      &previous,
+		ChangedFields: update.ChangedFields,
+	}
+}
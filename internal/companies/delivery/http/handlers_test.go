@@ -6,7 +6,10 @@ import (
 	"companies-service/internal/companies/service"
 	"companies-service/internal/models"
 	"companies-service/pkg/converter"
+	"companies-service/pkg/events"
+	"companies-service/pkg/httphelper"
 	"companies-service/pkg/logger"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -16,6 +19,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -44,11 +48,17 @@ func TestCompanyHandlers_Create(t *testing.T) {
 	}
 
 	apiLogger := logger.NewApiLogger(nil)
-	mockCompanyService := mock.NewMockService(ctrl)
-	companiesService := service.NewCompaniesService(nil, mockCompanyService, apiLogger)
+	mockCompanyRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	companiesService := service.NewCompaniesService(nil, mockCompanyRepo, mockRedisRepo,
+		nil, apiLogger)
 
 	mockKafka := mock.NewMockKafka(ctrl)
-	handlers := NewCompaniesHandlers(cfg, companiesService, mockKafka, apiLogger)
+	eventEncoder, err := events.NewEncoder(events.ModeBinary, "/companies-service",
+		"com.companies")
+	require.NoError(t, err)
+	handlers := NewCompaniesHandlers(cfg, companiesService, mockKafka, eventEncoder,
+		apiLogger)
 
 	// Create a test request with a JSON body
 	company := &models.Company{
@@ -59,9 +69,24 @@ func TestCompanyHandlers_Create(t *testing.T) {
 		CompanyType:        "NonProfit",
 	}
 
-	mockCompanyService.EXPECT().Create(gomock.Any(), gomock.Any()).Return(company, nil)
+	mockCompanyRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(company, nil)
+
+	mockKafka.EXPECT().PublishMessage(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, msgs []kafka.Message) error {
+			require.Len(t, msgs, 1)
 
-	mockKafka.EXPECT().PublishMessage(gomock.Any(), gomock.Any()).Return(nil)
+			event, err := events.DecodeCloudEvent(msgs[0])
+			require.NoError(t, err)
+			assert.Equal(t, events.CloudEventsSpecVersion, event.SpecVersion)
+			assert.Equal(t, "/companies-service/company_created", event.Source)
+			assert.Equal(t, "com.companies.company_created", event.Type)
+			assert.Equal(t, event.Subject, string(msgs[0].Key))
+
+			var payload events.Company
+			require.NoError(t, event.DecodeData(&payload))
+			assert.Equal(t, company.CompanyName, payload.CompanyName)
+			return nil
+		})
 
 	// Define the test route
 	router := gin.Default()
@@ -104,11 +129,16 @@ func TestCompanyHandlers_Update(t *testing.T) {
 	}
 
 	apiLogger := logger.NewApiLogger(nil)
-	mockCompanyService := mock.NewMockService(ctrl)
-	companiesService := service.NewCompaniesService(nil, mockCompanyService, apiLogger)
+	mockCompanyRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	companiesService := service.NewCompaniesService(nil, mockCompanyRepo, mockRedisRepo,
+		nil, apiLogger)
 
 	mockKafka := mock.NewMockKafka(ctrl)
-	handlers := NewCompaniesHandlers(cfg, companiesService, mockKafka, apiLogger)
+	eventEncoder, err := events.NewEncoder(events.ModeEnvelope, "", "")
+	require.NoError(t, err)
+	handlers := NewCompaniesHandlers(cfg, companiesService, mockKafka, eventEncoder,
+		apiLogger)
 
 	// Create a test request with a JSON body
 	companyID := uuid.New()
@@ -120,9 +150,40 @@ func TestCompanyHandlers_Update(t *testing.T) {
 		CompanyType:        "NonProfit",
 	}
 
-	mockCompanyService.EXPECT().Update(gomock.Any(), gomock.Any()).Return(company, nil)
-
-	mockKafka.EXPECT().PublishMessage(gomock.Any(), gomock.Any()).Return(nil)
+	previousCompany := &models.Company{
+		CompanyID:          companyID,
+		CompanyName:        "Test Company",
+		CompanyDescription: "It is a test company",
+		AmountOfEmployees:  10,
+		Registered:         false,
+		CompanyType:        "NonProfit",
+	}
+	updatedCompany := *company
+	updatedCompany.CompanyID = companyID
+
+	mockCompanyRepo.EXPECT().Update(gomock.Any(), gomock.Any()).
+		Return(&updatedCompany, previousCompany, nil)
+	mockRedisRepo.EXPECT().DeleteCompanyCtx(gomock.Any(), companyID).Return(nil)
+
+	mockKafka.EXPECT().PublishMessage(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, msgs []kafka.Message) error {
+			require.Len(t, msgs, 1)
+			assert.Equal(t, "company_updated", msgs[0].Topic)
+
+			event, err := events.Decode(msgs[0].Value)
+			require.NoError(t, err)
+			assert.Equal(t, events.CompanyUpdated, event.Type)
+
+			payload, err := event.CompanyUpdatePayload()
+			require.NoError(t, err)
+			assert.Equal(t, company.CompanyDescription, payload.CompanyDescription)
+			require.NotNil(t, payload.Previous)
+			assert.Equal(t, previousCompany.CompanyDescription, payload.Previous.CompanyDescription)
+			assert.Equal(t, []string{events.FieldCompanyDescription, events.FieldRegistered},
+				payload.ChangedFields)
+			assert.True(t, payload.Changed(events.FieldRegistered))
+			return nil
+		})
 
 	// Define the test route
 	router := gin.Default()
@@ -165,18 +226,39 @@ func TestCompanyHandlers_Delete(t *testing.T) {
 	}
 
 	apiLogger := logger.NewApiLogger(nil)
-	mockCompanyService := mock.NewMockService(ctrl)
-	companiesService := service.NewCompaniesService(nil, mockCompanyService, apiLogger)
+	mockCompanyRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	companiesService := service.NewCompaniesService(nil, mockCompanyRepo, mockRedisRepo,
+		nil, apiLogger)
 
 	mockKafka := mock.NewMockKafka(ctrl)
-	handlers := NewCompaniesHandlers(cfg, companiesService, mockKafka, apiLogger)
+	eventEncoder, err := events.NewEncoder(events.ModeEnvelope, "", "")
+	require.NoError(t, err)
+	handlers := NewCompaniesHandlers(cfg, companiesService, mockKafka, eventEncoder,
+		apiLogger)
 
 	// Define the company ID for deletion
 	companyID := uuid.New()
 
-	mockCompanyService.EXPECT().Delete(gomock.Any(), companyID).Return(nil)
+	mockCompanyRepo.EXPECT().Delete(gomock.Any(), companyID).Return(&models.Company{CompanyID: companyID}, nil)
+	mockRedisRepo.EXPECT().DeleteCompanyCtx(gomock.Any(), companyID).Return(nil)
 
-	mockKafka.EXPECT().PublishMessage(gomock.Any(), gomock.Any()).Return(nil)
+	mockKafka.EXPECT().PublishMessage(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, msgs []kafka.Message) error {
+			require.Len(t, msgs, 1)
+			assert.Equal(t, "company_deleted", msgs[0].Topic)
+			assert.Equal(t, companyID.String(), string(msgs[0].Key))
+
+			event, err := events.Decode(msgs[0].Value)
+			require.NoError(t, err)
+			assert.Equal(t, events.CompanyDeleted, event.Type)
+			assert.Equal(t, companyID, event.CompanyID)
+
+			payload, err := event.DeletedCompanyPayload()
+			require.NoError(t, err)
+			assert.Equal(t, companyID, payload.CompanyID)
+			return nil
+		})
 
 	// Define the test route
 	router := gin.Default()
@@ -189,7 +271,7 @@ func TestCompanyHandlers_Delete(t *testing.T) {
 
 	// Parse the JSON response body
 	var response map[string]string
-	err := json.Unmarshal(w.Body.Bytes(), &response)
+	err = json.Unmarshal(w.Body.Bytes(), &response)
 	assert.Nil(t, err)
 
 	// Perform assertions on the response, if needed
@@ -203,10 +285,12 @@ func TestCompanyHandlers_GetByID(t *testing.T) {
 	defer ctrl.Finish()
 
 	apiLogger := logger.NewApiLogger(nil)
-	mockCompanyService := mock.NewMockService(ctrl)
-	companiesService := service.NewCompaniesService(nil, mockCompanyService, apiLogger)
+	mockCompanyRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	companiesService := service.NewCompaniesService(nil, mockCompanyRepo, mockRedisRepo,
+		nil, apiLogger)
 
-	handlers := NewCompaniesHandlers(nil, companiesService, nil, apiLogger)
+	handlers := NewCompaniesHandlers(nil, companiesService, nil, nil, apiLogger)
 
 	// Define a test company ID
 	companyID := uuid.New()
@@ -218,7 +302,8 @@ func TestCompanyHandlers_GetByID(t *testing.T) {
 		CompanyDescription: "It is a test company",
 	}
 
-	mockCompanyService.EXPECT().GetByID(gomock.Any(), companyID).Return(mockResponse, nil)
+	mockRedisRepo.EXPECT().GetByIDCtx(gomock.Any(), companyID, gomock.Any()).Return(mockResponse,
+		nil)
 
 	// Define the test route
 	router := gin.Default()
@@ -238,3 +323,35 @@ func TestCompanyHandlers_GetByID(t *testing.T) {
 	assert.Equal(t, mockResponse.CompanyName, response.CompanyName)
 	assert.Equal(t, mockResponse.CompanyDescription, response.CompanyDescription)
 }
+
+func TestCompanyHandlers_GetByID_InvalidID(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	apiLogger := logger.NewApiLogger(&config.Config{Server: config.ServerConfig{Mode: "Development"}})
+	apiLogger.InitLogger()
+	mockCompanyRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	companiesService := service.NewCompaniesService(nil, mockCompanyRepo, mockRedisRepo,
+		nil, apiLogger)
+
+	handlers := NewCompaniesHandlers(nil, companiesService, nil, nil, apiLogger)
+
+	// Define the test route
+	router := gin.Default()
+	router.GET("/api/v1/companies/:company_id", handlers.GetByID)
+
+	w := performRequest(router, "GET", "/api/v1/companies/not-a-uuid", "")
+
+	// Assert the problem details response
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, httphelper.ProblemContentType, w.Header().Get("Content-Type"))
+
+	var problem httphelper.ProblemDetails
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+	assert.Equal(t, httphelper.CodeInvalidID, problem.Code)
+	assert.Equal(t, "/api/v1/companies/not-a-uuid", problem.Instance)
+}
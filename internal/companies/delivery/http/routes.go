@@ -14,5 +14,5 @@ func MapCommentsRoutes(companiesGroup *gin.RouterGroup, h companies.Handlers,
 	companiesGroup.POST("", mw.AuthJWTMiddleware(cfg), h.Create)
 	companiesGroup.DELETE("/:company_id", mw.AuthJWTMiddleware(cfg), h.Delete)
 	companiesGroup.PATCH("/:company_id", mw.AuthJWTMiddleware(cfg), h.Update)
-	companiesGroup.GET("/:company_id", h.GetByID)
+	companiesGroup.GET("/:company_id", mw.ETagMiddleware(), h.GetByID)
 }
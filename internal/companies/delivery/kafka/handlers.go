@@ -0,0 +1,47 @@
+package kafka
+
+import (
+	"companies-service/config"
+	"companies-service/internal/companies"
+	"companies-service/pkg/events"
+	"companies-service/pkg/logger"
+	"companies-service/pkg/tracing"
+	"context"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Companies consumer handlers
+type companiesConsumerHandlers struct {
+	cfg     *config.Config
+	changes *events.FieldChanges
+	logger  logger.Logger
+}
+
+// NewCompaniesConsumerHandlers Companies Consumer Handlers Constructor
+func NewCompaniesConsumerHandlers(
+	cfg *config.Config, logger logger.Logger,
+) companies.ConsumerHandlers {
+	h := &companiesConsumerHandlers{cfg: cfg, changes: events.NewFieldChanges(), logger: logger}
+	h.changes.Subscribe(events.FieldRegistered, h.registeredChanged)
+	return h
+}
+
+// CompanyUpdated dispatches the field changes of a company updated event
+func (h *companiesConsumerHandlers) CompanyUpdated(
+	ctx context.Context, _ kafka.Message, event *events.Envelope,
+) error {
+	ctx, span := tracing.StartSpan(ctx, "companiesConsumerHandlers.CompanyUpdated")
+	defer span.End()
+
+	return h.changes.Dispatch(ctx, event)
+}
+
+// registeredChanged logs the companies getting registered or unregistered
+func (h *companiesConsumerHandlers) registeredChanged(
+	ctx context.Context, event *events.Envelope, update *events.CompanyUpdate,
+) error {
+	logger.FromContext(ctx, h.logger).Infow("Company registration changed",
+		"company_id", event.CompanyID, "registered", update.Registered, "event_id", event.ID)
+	return nil
+}
@@ -0,0 +1,69 @@
+package kafka
+
+import (
+	"companies-service/config"
+	"companies-service/pkg/events"
+	kafkaClient "companies-service/pkg/kafka"
+	"companies-service/pkg/logger"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompaniesConsumerHandlers_CompanyUpdated(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{Logger: config.Logger{Level: "error", Encoding: "console"}}
+	apiLogger := logger.NewApiLogger(cfg)
+	apiLogger.InitLogger()
+	handlers := NewCompaniesConsumerHandlers(cfg, apiLogger)
+
+	companyID := uuid.New()
+	company := events.Company{CompanyID: companyID, CompanyName: "Test Company",
+		Registered: true}
+	previous := company
+	previous.Registered = false
+
+	t.Run("Registered changed", func(t *testing.T) {
+		event, err := events.NewCompanyEvent(events.CompanyUpdated, companyID, nil,
+			events.CompanyUpdate{Company: company, Previous: &previous,
+				ChangedFields: []string{events.FieldRegistered}})
+		require.NoError(t, err)
+
+		err = handlers.CompanyUpdated(context.Background(), kafka.Message{}, event)
+		require.NoError(t, err)
+	})
+
+	t.Run("Invalid payload", func(t *testing.T) {
+		event, err := events.NewCompanyEvent(events.CompanyUpdated, companyID, nil,
+			events.CompanyUpdate{Company: company})
+		require.NoError(t, err)
+		event.Payload = json.RawMessage(`{"changed_fields": "registered"}`)
+
+		err = handlers.CompanyUpdated(context.Background(), kafka.Message{}, event)
+		require.Error(t, err)
+	})
+}
+
+func TestMapCompaniesConsumers(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{
+		KafkaTopics: config.KafkaTopics{
+			CompanyUpdated: config.TopicConfig{
+				TopicName: "company_updated",
+			},
+		},
+	}
+
+	apiLogger := logger.NewApiLogger(nil)
+	consumer := kafkaClient.NewConsumer(nil, nil, "", apiLogger)
+	MapCompaniesConsumers(consumer, NewCompaniesConsumerHandlers(cfg, apiLogger),
+		events.NewDecoder(nil), cfg)
+
+	require.Equal(t, []string{"company_updated"}, consumer.Topics())
+}
@@ -0,0 +1,27 @@
+package kafka
+
+import (
+	"companies-service/config"
+	"companies-service/internal/companies"
+	"companies-service/pkg/events"
+	kafkaClient "companies-service/pkg/kafka"
+	"context"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Map companies consumer handlers, the events are decoded whatever their publishing mode
+func MapCompaniesConsumers(consumer *kafkaClient.Consumer, h companies.ConsumerHandlers,
+	decoder *events.Decoder, cfg *config.Config) {
+	consumer.Handle(cfg.KafkaTopics.CompanyUpdated.TopicName,
+		kafkaClient.TypedHandler(eventDecoder(decoder, events.CompanyUpdated), h.CompanyUpdated))
+}
+
+// eventDecoder returns the decoder of the company events of a topic
+func eventDecoder(
+	decoder *events.Decoder, eventType string,
+) func(ctx context.Context, msg kafka.Message) (*events.Envelope, error) {
+	return func(ctx context.Context, msg kafka.Message) (*events.Envelope, error) {
+		return decoder.DecodeMessage(ctx, msg, eventType)
+	}
+}
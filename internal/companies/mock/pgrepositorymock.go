@@ -53,12 +53,13 @@ func (mr *MockRepositoryRecorder) Create(ctx, company interface{}) *gomock.Call
 // Update mocks base method
 func (m *MockRepository) Update(
 	ctx context.Context, company *models.Company,
-) (*models.Company, error) {
+) (*models.Company, *models.Company, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Update", ctx, company)
 	ret0, _ := ret[0].(*models.Company)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].(*models.Company)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // Update indicates an expected call of Update
@@ -69,11 +70,14 @@ func (mr *MockRepositoryRecorder) Update(ctx, company interface{}) *gomock.Call
 }
 
 // Delete mocks base method
-func (m *MockRepository) Delete(ctx context.Context, companyID uuid.UUID) error {
+func (m *MockRepository) Delete(
+	ctx context.Context, companyID uuid.UUID,
+) (*models.Company, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Delete", ctx, companyID)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(*models.Company)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // Delete indicates an expected call of Delete
@@ -0,0 +1,71 @@
+package mock
+
+import (
+	"companies-service/internal/models"
+	"companies-service/pkg/cache"
+	"context"
+	"reflect"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+)
+
+// MockRedisRepository is a mock of RedisRepository interface
+type MockRedisRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRedisRepositoryRecorder
+}
+
+// MockRedisRepositoryRecorder is the recorder for MockRedisRepository
+type MockRedisRepositoryRecorder struct {
+	mock *MockRedisRepository
+}
+
+// NewMockRedisRepository creates a new mock instance
+func NewMockRedisRepository(ctrl *gomock.Controller) *MockRedisRepository {
+	mock := &MockRedisRepository{ctrl: ctrl}
+	mock.recorder = &MockRedisRepositoryRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRedisRepository) EXPECT() *MockRedisRepositoryRecorder {
+	return m.recorder
+}
+
+// GetByIDCtx mocks base method
+func (m *MockRedisRepository) GetByIDCtx(
+	ctx context.Context, companyID uuid.UUID, load cache.Loader[models.Company],
+) (*models.Company, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByIDCtx", ctx, companyID, load)
+	ret0, _ := ret[0].(*models.Company)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByIDCtx indicates an expected call of GetByIDCtx
+func (mr *MockRedisRepositoryRecorder) GetByIDCtx(
+	ctx, companyID, load interface{},
+) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock,
+		"GetByIDCtx", reflect.TypeOf((*MockRedisRepository)(nil).GetByIDCtx),
+		ctx, companyID, load)
+}
+
+// DeleteCompanyCtx mocks base method
+func (m *MockRedisRepository) DeleteCompanyCtx(ctx context.Context, companyID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCompanyCtx", ctx, companyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCompanyCtx indicates an expected call of DeleteCompanyCtx
+func (mr *MockRedisRepositoryRecorder) DeleteCompanyCtx(ctx, companyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock,
+		"DeleteCompanyCtx", reflect.TypeOf((*MockRedisRepository)(nil).DeleteCompanyCtx),
+		ctx, companyID)
+}
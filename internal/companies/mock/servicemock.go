@@ -53,10 +53,10 @@ func (mr *MockServiceRecorder) Create(ctx, company interface{}) *gomock.Call {
 // Update mocks base method
 func (m *MockService) Update(
 	ctx context.Context, company *models.Company,
-) (*models.Company, error) {
+) (*models.CompanyUpdate, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Update", ctx, company)
-	ret0, _ := ret[0].(*models.Company)
+	ret0, _ := ret[0].(*models.CompanyUpdate)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -0,0 +1,17 @@
+package companies
+
+import (
+	"companies-service/internal/models"
+	"companies-service/pkg/cache"
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Companies Redis repository interface
+type RedisRepository interface {
+	GetByIDCtx(
+		ctx context.Context, companyID uuid.UUID, load cache.Loader[models.Company],
+	) (*models.Company, error)
+	DeleteCompanyCtx(ctx context.Context, companyID uuid.UUID) error
+}
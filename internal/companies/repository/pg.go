@@ -3,30 +3,45 @@ package repository
 import (
 	"companies-service/internal/companies"
 	"companies-service/internal/models"
+	"companies-service/pkg/tracing"
 	"context"
-	"database/sql"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
-	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 )
 
 const (
-	createCompany = `INSERT INTO companies
+	createCompany = `-- name: CreateCompany
+	INSERT INTO companies
 						(company_name, company_description,
 						 amount_of_employees, registered, company_type) 
 						VALUES ($1, $2, $3, $4, $5) RETURNING *`
 
-	updateCompany = `UPDATE companies SET
+	updateCompany = `-- name: UpdateCompany
+	UPDATE companies SET
 						company_name = $1, company_description = $2,
 						amount_of_employees = $3, registered = $4, 
 						company_type = $5
-						WHERE company_id = $6 RETURNING *`
-
-	deleteCompany = `DELETE FROM companies WHERE company_id = $1`
-
-	getCompanyByID = `SELECT company_id, company_name, company_description, 
+						FROM (SELECT company_id, company_name, company_description,
+							amount_of_employees, registered, company_type
+							FROM companies WHERE company_id = $6 FOR UPDATE) previous
+						WHERE companies.company_id = previous.company_id
+						RETURNING companies.company_id, companies.company_name,
+						companies.company_description, companies.amount_of_employees,
+						companies.registered, companies.company_type,
+						previous.company_id AS "previous.company_id",
+						previous.company_name AS "previous.company_name",
+						previous.company_description AS "previous.company_description",
+						previous.amount_of_employees AS "previous.amount_of_employees",
+						previous.registered AS "previous.registered",
+						previous.company_type AS "previous.company_type"`
+
+	deleteCompany = `-- name: DeleteCompany
+	DELETE FROM companies WHERE company_id = $1 RETURNING *`
+
+	getCompanyByID = `-- name: GetCompanyByID
+	SELECT company_id, company_name, company_description, 
 						amount_of_employees, registered, company_type 
 						FROM companies WHERE company_id = $1`
 )
@@ -45,8 +60,8 @@ func NewCompaniesRepository(db *sqlx.DB) companies.Repository {
 func (r *companiesRepo) Create(
 	ctx context.Context, company *models.Company,
 ) (*models.Company, error) {
-	span, ctx := opentracing.StartSpanFromContext(ctx, "companiesRepo.Create")
-	defer span.Finish()
+	ctx, span := tracing.StartSpan(ctx, "companiesRepo.Create")
+	defer span.End()
 
 	c := &models.Company{}
 	if err := r.db.QueryRowxContext(
@@ -64,14 +79,21 @@ func (r *companiesRepo) Create(
 	return c, nil
 }
 
-// Update a company
+// updatedCompanyRow is a company updated row with the row before the update
+type updatedCompanyRow struct {
+	models.Company
+	Previous models.Company `db:"previous"`
+}
+
+// Update a company, returns the updated and the previous rows. The previous row is locked
+// and read in the same statement.
 func (r *companiesRepo) Update(
 	ctx context.Context, company *models.Company,
-) (*models.Company, error) {
-	span, ctx := opentracing.StartSpanFromContext(ctx, "companiesRepo.Update")
-	defer span.Finish()
+) (*models.Company, *models.Company, error) {
+	ctx, span := tracing.StartSpan(ctx, "companiesRepo.Update")
+	defer span.End()
 
-	c := &models.Company{}
+	row := &updatedCompanyRow{}
 	if err := r.db.QueryRowxContext(
 		ctx,
 		updateCompany,
@@ -80,40 +102,34 @@ func (r *companiesRepo) Update(
 		company.AmountOfEmployees,
 		company.Registered,
 		company.CompanyType,
-		company.CompanyID).StructScan(c); err != nil {
-		return nil, errors.Wrap(err, "companiesRepo.Update.QueryRowxContext")
+		company.CompanyID).StructScan(row); err != nil {
+		return nil, nil, errors.Wrap(err, "companiesRepo.Update.QueryRowxContext")
 	}
 
-	return c, nil
+	return &row.Company, &row.Previous, nil
 }
 
 // Delete a company
-func (r *companiesRepo) Delete(ctx context.Context, companyID uuid.UUID) error {
-	span, ctx := opentracing.StartSpanFromContext(ctx, "companiesRepo.Delete")
-	defer span.Finish()
-
-	result, err := r.db.ExecContext(ctx, deleteCompany, companyID)
-	if err != nil {
-		return errors.Wrap(err, "companiesRepo.Delete.ExecContext")
-	}
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return errors.Wrap(err, "companiesRepo.Delete.RowsAffected")
-	}
+func (r *companiesRepo) Delete(
+	ctx context.Context, companyID uuid.UUID,
+) (*models.Company, error) {
+	ctx, span := tracing.StartSpan(ctx, "companiesRepo.Delete")
+	defer span.End()
 
-	if rowsAffected == 0 {
-		return errors.Wrap(sql.ErrNoRows, "companiesRepo.Delete.rowsAffected")
+	c := &models.Company{}
+	if err := r.db.QueryRowxContext(ctx, deleteCompany, companyID).StructScan(c); err != nil {
+		return nil, errors.Wrap(err, "companiesRepo.Delete.StructScan")
 	}
 
-	return nil
+	return c, nil
 }
 
 // GetByID company
 func (r *companiesRepo) GetByID(
 	ctx context.Context, companyID uuid.UUID,
 ) (*models.Company, error) {
-	span, ctx := opentracing.StartSpanFromContext(ctx, "companiesRepo.GetByID")
-	defer span.Finish()
+	ctx, span := tracing.StartSpan(ctx, "companiesRepo.GetByID")
+	defer span.End()
 
 	company := &models.Company{}
 	if err := r.db.GetContext(ctx, company, getCompanyByID, companyID); err != nil {
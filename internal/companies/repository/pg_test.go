@@ -3,6 +3,7 @@ package repository
 import (
 	"companies-service/internal/models"
 	"context"
+	"database/sql"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -101,8 +102,12 @@ func TestCompaniesRepo_Update(t *testing.T) {
 		rows := sqlmock.NewRows([]string{
 			"company_id", "company_name", "company_description",
 			"amount_of_employees", "registered", "company_type",
+			"previous.company_id", "previous.company_name", "previous.company_description",
+			"previous.amount_of_employees", "previous.registered", "previous.company_type",
 		}).AddRow(companyID, companyName, companyDescription,
-			amountOfEmployees, registered, companyType)
+			amountOfEmployees, registered, companyType,
+			companyID, companyName, "Computer company",
+			amountOfEmployees, false, companyType)
 
 		company := &models.Company{
 			CompanyID:          companyID,
@@ -117,11 +122,14 @@ func TestCompaniesRepo_Update(t *testing.T) {
 			company.AmountOfEmployees, company.Registered, company.CompanyType,
 			company.CompanyID).WillReturnRows(rows)
 
-		updatedCompany, err := companiesRepo.Update(context.Background(), company)
+		updatedCompany, previousCompany, err := companiesRepo.Update(context.Background(), company)
 
 		require.NoError(t, err)
-		require.NotNil(t, updatedCompany)
-		require.Equal(t, updatedCompany, company)
+		require.Equal(t, company, updatedCompany)
+		require.NotNil(t, previousCompany)
+		require.Equal(t, companyID, previousCompany.CompanyID)
+		require.Equal(t, "Computer company", previousCompany.CompanyDescription)
+		require.False(t, previousCompany.Registered)
 	})
 
 	t.Run("Update ERR", func(t *testing.T) {
@@ -140,10 +148,11 @@ func TestCompaniesRepo_Update(t *testing.T) {
 			company.AmountOfEmployees, company.Registered, company.CompanyType,
 			company.CompanyID).WillReturnError(updateErr)
 
-		updatedCompany, err := companiesRepo.Update(context.Background(), company)
+		updatedCompany, previousCompany, err := companiesRepo.Update(context.Background(), company)
 
 		require.NotNil(t, err)
 		require.Nil(t, updatedCompany)
+		require.Nil(t, previousCompany)
 	})
 }
 
@@ -161,19 +170,23 @@ func TestCompaniesRepo_Delete(t *testing.T) {
 
 	t.Run("Delete", func(t *testing.T) {
 		companyID := uuid.New()
-		mock.ExpectExec(deleteCompany).WithArgs(companyID).
-			WillReturnResult(sqlmock.NewResult(1, 1))
-		err := companiesRepo.Delete(context.Background(), companyID)
+		rows := sqlmock.NewRows([]string{"company_id", "company_type"}).
+			AddRow(companyID, "Corporations")
+		mock.ExpectQuery(deleteCompany).WithArgs(companyID).WillReturnRows(rows)
 
+		deletedCompany, err := companiesRepo.Delete(context.Background(), companyID)
 		require.NoError(t, err)
+		require.Equal(t, companyID, deletedCompany.CompanyID)
+		require.Equal(t, "Corporations", deletedCompany.CompanyType)
 	})
 
 	t.Run("Delete Err", func(t *testing.T) {
 		companyID := uuid.New()
-		mock.ExpectExec(deleteCompany).WithArgs(companyID).
-			WillReturnResult(sqlmock.NewResult(1, 0))
+		mock.ExpectQuery(deleteCompany).WithArgs(companyID).
+			WillReturnRows(sqlmock.NewRows([]string{"company_id"}))
 
-		err := companiesRepo.Delete(context.Background(), companyID)
+		_, err := companiesRepo.Delete(context.Background(), companyID)
 		require.NotNil(t, err)
+		require.True(t, errors.Is(err, sql.ErrNoRows))
 	})
 }
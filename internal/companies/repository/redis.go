@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"companies-service/config"
+	"companies-service/internal/companies"
+	"companies-service/internal/models"
+	"companies-service/pkg/cache"
+	"companies-service/pkg/logger"
+	"companies-service/pkg/metric"
+	"companies-service/pkg/tracing"
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+const basePrefix = "api-companies"
+
+// Companies redis repository
+type companiesRedisRepo struct {
+	cache *cache.Cache[models.Company]
+}
+
+// Companies Redis Repository constructor
+func NewCompaniesRedisRepo(
+	redisClient *redis.Client,
+	cfg *config.Config,
+	metrics metric.CacheMetrics,
+	invalidator *cache.Invalidator,
+	logger logger.Logger,
+) companies.RedisRepository {
+	opts := cache.OptionsFromConfig(basePrefix, cfg.Cache.Companies, sql.ErrNoRows, invalidator)
+	return &companiesRedisRepo{cache: cache.New[models.Company](redisClient, opts, metrics, logger)}
+}
+
+// GetByIDCtx gets company by id, loading and caching it on a miss
+func (r *companiesRedisRepo) GetByIDCtx(
+	ctx context.Context, companyID uuid.UUID, load cache.Loader[models.Company],
+) (*models.Company, error) {
+	ctx, span := tracing.StartSpan(ctx, "companiesRedisRepo.GetByIDCtx")
+	defer span.End()
+
+	company, err := r.cache.GetOrLoad(ctx, companyID.String(), load)
+	if err != nil {
+		return nil, errors.Wrap(err, "companiesRedisRepo.GetByIDCtx.cache.GetOrLoad")
+	}
+	return company, nil
+}
+
+// DeleteCompanyCtx deletes cached company by id
+func (r *companiesRedisRepo) DeleteCompanyCtx(ctx context.Context, companyID uuid.UUID) error {
+	ctx, span := tracing.StartSpan(ctx, "companiesRedisRepo.DeleteCompanyCtx")
+	defer span.End()
+
+	if err := r.cache.Delete(ctx, companyID.String()); err != nil {
+		return errors.Wrap(err, "companiesRedisRepo.DeleteCompanyCtx.cache.Delete")
+	}
+
+	return nil
+}
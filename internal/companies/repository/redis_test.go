@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"companies-service/config"
+	"companies-service/internal/companies"
+	"companies-service/internal/models"
+	"companies-service/pkg/logger"
+	"context"
+	"log"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func SetupRedis() companies.RedisRepository {
+	mr, err := miniredis.Run()
+	if err != nil {
+		log.Fatal(err)
+	}
+	client := redis.NewClient(&redis.Options{
+		Addr: mr.Addr(),
+	})
+
+	cfg := &config.Config{
+		Cache: config.Cache{
+			Companies: config.CacheConfig{
+				TTL:         10,
+				NegativeTTL: 10,
+			},
+		},
+	}
+
+	return NewCompaniesRedisRepo(client, cfg, nil, nil, logger.NewApiLogger(cfg))
+}
+
+func TestCompaniesRedisRepo_GetByIDCtx(t *testing.T) {
+	t.Parallel()
+
+	companiesRedisRepo := SetupRedis()
+
+	t.Run("GetByIDCtx", func(t *testing.T) {
+		companyID := uuid.New()
+		company := &models.Company{
+			CompanyID:   companyID,
+			CompanyName: "Apple",
+		}
+
+		loads := 0
+		load := func(ctx context.Context) (*models.Company, error) {
+			loads++
+			return company, nil
+		}
+
+		cachedCompany, err := companiesRedisRepo.GetByIDCtx(context.Background(), companyID, load)
+		require.NoError(t, err)
+		require.Equal(t, company, cachedCompany)
+
+		cachedCompany, err = companiesRedisRepo.GetByIDCtx(context.Background(), companyID, load)
+		require.NoError(t, err)
+		require.Equal(t, company, cachedCompany)
+		require.Equal(t, 1, loads)
+	})
+}
+
+func TestCompaniesRedisRepo_DeleteCompanyCtx(t *testing.T) {
+	t.Parallel()
+
+	companiesRedisRepo := SetupRedis()
+
+	t.Run("DeleteCompanyCtx", func(t *testing.T) {
+		companyID := uuid.New()
+		company := &models.Company{
+			CompanyID:   companyID,
+			CompanyName: "Apple",
+		}
+		load := func(ctx context.Context) (*models.Company, error) {
+			return company, nil
+		}
+
+		_, err := companiesRedisRepo.GetByIDCtx(context.Background(), companyID, load)
+		require.NoError(t, err)
+
+		err = companiesRedisRepo.DeleteCompanyCtx(context.Background(), companyID)
+		require.NoError(t, err)
+
+		// Readers get the updated company after the invalidation
+		updatedCompany := &models.Company{
+			CompanyID:   companyID,
+			CompanyName: "Apple Inc",
+		}
+		cachedCompany, err := companiesRedisRepo.GetByIDCtx(context.Background(), companyID,
+			func(ctx context.Context) (*models.Company, error) {
+				return updatedCompany, nil
+			})
+		require.NoError(t, err)
+		require.Equal(t, updatedCompany, cachedCompany)
+	})
+}
+
+func TestCompaniesRedisRepo_DeleteCompanyCtxDuringLoad(t *testing.T) {
+	t.Parallel()
+
+	companiesRedisRepo := SetupRedis()
+
+	companyID := uuid.New()
+	staleCompany := &models.Company{CompanyID: companyID, CompanyName: "Apple"}
+	updatedCompany := &models.Company{CompanyID: companyID, CompanyName: "Apple Inc"}
+
+	// A load reads the company before the update and finishes after its invalidation
+	loading := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := companiesRedisRepo.GetByIDCtx(context.Background(), companyID,
+			func(ctx context.Context) (*models.Company, error) {
+				close(loading)
+				<-release
+				return staleCompany, nil
+			})
+		require.NoError(t, err)
+	}()
+
+	<-loading
+	require.NoError(t, companiesRedisRepo.DeleteCompanyCtx(context.Background(), companyID))
+	close(release)
+	<-done
+
+	cachedCompany, err := companiesRedisRepo.GetByIDCtx(context.Background(), companyID,
+		func(ctx context.Context) (*models.Company, error) {
+			return updatedCompany, nil
+		})
+	require.NoError(t, err)
+	require.Equal(t, updatedCompany, cachedCompany)
+}
+
+func TestCompaniesRedisRepo_DeleteCompanyCtxRedisDown(t *testing.T) {
+	t.Parallel()
+
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+
+	cfg := &config.Config{
+		Cache: config.Cache{
+			Companies: config.CacheConfig{TTL: 10},
+		},
+	}
+	companiesRedisRepo := NewCompaniesRedisRepo(client, cfg, nil, nil, logger.NewApiLogger(cfg))
+
+	mr.Close()
+
+	err = companiesRedisRepo.DeleteCompanyCtx(context.Background(), uuid.New())
+	require.Error(t, err)
+}
@@ -10,7 +10,7 @@ import (
 // Companies service interface
 type Service interface {
 	Create(ctx context.Context, company *models.Company) (*models.Company, error)
-	Update(ctx context.Context, comment *models.Company) (*models.Company, error)
+	Update(ctx context.Context, comment *models.Company) (*models.CompanyUpdate, error)
 	Delete(ctx context.Context, companyID uuid.UUID) error
 	GetByID(ctx context.Context, companyID uuid.UUID) (*models.Company, error)
 }
@@ -5,58 +5,90 @@ import (
 	"companies-service/internal/companies"
 	"companies-service/internal/models"
 	"companies-service/pkg/logger"
+	"companies-service/pkg/metric"
+	"companies-service/pkg/tracing"
 	"context"
 
 	"github.com/google/uuid"
-	"github.com/opentracing/opentracing-go"
 )
 
 // Companies Service
 type companiesService struct {
 	cfg         *config.Config
 	companyRepo companies.Repository
+	redisRepo   companies.RedisRepository
+	metrics     metric.BusinessMetrics
 	logger      logger.Logger
 }
 
 // Companies Service constructor
 func NewCompaniesService(
-	cfg *config.Config, companyRepo companies.Repository, logger logger.Logger,
+	cfg *config.Config,
+	companyRepo companies.Repository,
+	redisRepo companies.RedisRepository,
+	metrics metric.BusinessMetrics,
+	logger logger.Logger,
 ) companies.Service {
-	return &companiesService{cfg: cfg, companyRepo: companyRepo, logger: logger}
+	return &companiesService{cfg: cfg, companyRepo: companyRepo, redisRepo: redisRepo,
+		metrics: metrics, logger: logger}
 }
 
 // Create a new company
 func (s *companiesService) Create(
 	ctx context.Context, company *models.Company,
 ) (*models.Company, error) {
-	span, ctx := opentracing.StartSpanFromContext(ctx, "companiesService.Create")
-	defer span.Finish()
-	return s.companyRepo.Create(ctx, company)
+	ctx, span := tracing.StartSpan(ctx, "companiesService.Create")
+	defer span.End()
+
+	createdCompany, err := s.companyRepo.Create(ctx, company)
+	if err != nil {
+		return nil, err
+	}
+	s.incCompanies(metric.CompanyCreated, createdCompany.CompanyType)
+
+	return createdCompany, nil
 }
 
-// Update a company
+// Update a company, the update holds the previous state of the company and its changed fields
 func (s *companiesService) Update(
 	ctx context.Context, company *models.Company,
-) (*models.Company, error) {
-	span, ctx := opentracing.StartSpanFromContext(ctx, "companiesService.Update")
-	defer span.Finish()
+) (*models.CompanyUpdate, error) {
+	ctx, span := tracing.StartSpan(ctx, "companiesService.Update")
+	defer span.End()
 
-	updatedCompany, err := s.companyRepo.Update(ctx, company)
+	updatedCompany, previousCompany, err := s.companyRepo.Update(ctx, company)
 	if err != nil {
 		return nil, err
 	}
+	s.incCompanies(metric.CompanyUpdated, updatedCompany.CompanyType)
+
+	// The write is committed, a stale cached company is bounded by the tombstone retries
+	// and the cache ttl
+	if err = s.redisRepo.DeleteCompanyCtx(ctx, company.CompanyID); err != nil {
+		logger.FromContext(ctx, s.logger).Errorf("companiesService.Update.DeleteCompanyCtx: %s", err)
+	}
 
-	return updatedCompany, nil
+	return &models.CompanyUpdate{
+		Company:       updatedCompany,
+		Previous:      previousCompany,
+		ChangedFields: updatedCompany.Diff(previousCompany),
+	}, nil
 }
 
 // Delete a company
 func (s *companiesService) Delete(ctx context.Context, companyID uuid.UUID) error {
-	span, ctx := opentracing.StartSpanFromContext(ctx, "companiesService.Delete")
-	defer span.Finish()
+	ctx, span := tracing.StartSpan(ctx, "companiesService.Delete")
+	defer span.End()
 
-	if err := s.companyRepo.Delete(ctx, companyID); err != nil {
+	deletedCompany, err := s.companyRepo.Delete(ctx, companyID)
+	if err != nil {
 		return err
 	}
+	s.incCompanies(metric.CompanyDeleted, deletedCompany.CompanyType)
+
+	if err := s.redisRepo.DeleteCompanyCtx(ctx, companyID); err != nil {
+		logger.FromContext(ctx, s.logger).Errorf("companiesService.Delete.DeleteCompanyCtx: %s", err)
+	}
 
 	return nil
 }
@@ -65,8 +97,17 @@ func (s *companiesService) Delete(ctx context.Context, companyID uuid.UUID) erro
 func (s *companiesService) GetByID(
 	ctx context.Context, companyID uuid.UUID,
 ) (*models.Company, error) {
-	span, ctx := opentracing.StartSpanFromContext(ctx, "companiesService.GetByID")
-	defer span.Finish()
+	ctx, span := tracing.StartSpan(ctx, "companiesService.GetByID")
+	defer span.End()
 
-	return s.companyRepo.GetByID(ctx, companyID)
+	return s.redisRepo.GetByIDCtx(ctx, companyID,
+		func(ctx context.Context) (*models.Company, error) {
+			return s.companyRepo.GetByID(ctx, companyID)
+		})
+}
+
+func (s *companiesService) incCompanies(event, companyType string) {
+	if s.metrics != nil {
+		s.metrics.IncCompanies(event, companyType)
+	}
 }
@@ -1,15 +1,18 @@
 package service
 
 import (
+	"companies-service/config"
 	"companies-service/internal/companies/mock"
 	"companies-service/internal/models"
+	"companies-service/pkg/cache"
 	"companies-service/pkg/logger"
+	"companies-service/pkg/tracing"
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
-	"github.com/opentracing/opentracing-go"
 	"github.com/stretchr/testify/require"
 )
 
@@ -21,7 +24,8 @@ func TestCompaniesService_Create(t *testing.T) {
 
 	apiLogger := logger.NewApiLogger(nil)
 	mockCompanyRepo := mock.NewMockRepository(ctrl)
-	companiesService := NewCompaniesService(nil, mockCompanyRepo, apiLogger)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	companiesService := NewCompaniesService(nil, mockCompanyRepo, mockRedisRepo, nil, apiLogger)
 
 	company := &models.Company{
 		CompanyID:          uuid.New(),
@@ -32,8 +36,8 @@ func TestCompaniesService_Create(t *testing.T) {
 		CompanyType:        "Corporations",
 	}
 
-	span, ctx := opentracing.StartSpanFromContext(context.Background(), "companiesService.Create")
-	defer span.Finish()
+	ctx, span := tracing.StartSpan(context.Background(), "companiesService.Create")
+	defer span.End()
 
 	mockCompanyRepo.EXPECT().Create(ctx, gomock.Eq(company)).Return(company, nil)
 
@@ -50,7 +54,8 @@ func TestCompaniesService_Update(t *testing.T) {
 
 	apiLogger := logger.NewApiLogger(nil)
 	mockCompanyRepo := mock.NewMockRepository(ctrl)
-	companiesService := NewCompaniesService(nil, mockCompanyRepo, apiLogger)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	companiesService := NewCompaniesService(nil, mockCompanyRepo, mockRedisRepo, nil, apiLogger)
 
 	company := &models.Company{
 		CompanyID:          uuid.New(),
@@ -61,15 +66,58 @@ func TestCompaniesService_Update(t *testing.T) {
 		CompanyType:        "Corporations",
 	}
 
-	span, ctxWithTrace := opentracing.StartSpanFromContext(context.Background(),
+	ctxWithTrace, span := tracing.StartSpan(context.Background(),
 		"companiesService.Update")
-	defer span.Finish()
+	defer span.End()
 
-	mockCompanyRepo.EXPECT().Update(ctxWithTrace, gomock.Eq(company)).Return(company, nil)
+	previousCompany := *company
+	previousCompany.AmountOfEmployees = 150000
+	previousCompany.Registered = false
 
-	updatedCompany, err := companiesService.Update(context.Background(), company)
+	mockCompanyRepo.EXPECT().Update(ctxWithTrace, gomock.Eq(company)).
+		Return(company, &previousCompany, nil)
+	mockRedisRepo.EXPECT().DeleteCompanyCtx(ctxWithTrace, company.CompanyID).Return(nil)
+
+	update, err := companiesService.Update(context.Background(), company)
 	require.NoError(t, err)
-	require.NotNil(t, updatedCompany)
+	require.NotNil(t, update)
+	require.Equal(t, company, update.Company)
+	require.Equal(t, &previousCompany, update.Previous)
+	require.Equal(t, []string{"amount_of_employees", "registered"}, update.ChangedFields)
+}
+
+func TestCompaniesService_InvalidationError(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	apiLogger := logger.NewApiLogger(&config.Config{
+		Logger: config.Logger{Level: "fatal", Encoding: "console"},
+	})
+	apiLogger.InitLogger()
+	mockCompanyRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	companiesService := NewCompaniesService(nil, mockCompanyRepo, mockRedisRepo, nil, apiLogger)
+
+	company := &models.Company{
+		CompanyID:   uuid.New(),
+		CompanyName: "Apple",
+	}
+	invalidationErr := errors.New("redis is down")
+
+	// The committed writes succeed, the cached company expires by itself
+	mockCompanyRepo.EXPECT().Update(gomock.Any(), gomock.Eq(company)).
+		Return(company, company, nil)
+	mockCompanyRepo.EXPECT().Delete(gomock.Any(), company.CompanyID).Return(company, nil)
+	mockRedisRepo.EXPECT().DeleteCompanyCtx(gomock.Any(), company.CompanyID).
+		Return(invalidationErr).Times(2)
+
+	update, err := companiesService.Update(context.Background(), company)
+	require.NoError(t, err)
+	require.Equal(t, company, update.Company)
+
+	require.NoError(t, companiesService.Delete(context.Background(), company.CompanyID))
 }
 
 func TestCompaniesService_Delete(t *testing.T) {
@@ -80,17 +128,20 @@ func TestCompaniesService_Delete(t *testing.T) {
 
 	apiLogger := logger.NewApiLogger(nil)
 	mockCompanyRepo := mock.NewMockRepository(ctrl)
-	companiesService := NewCompaniesService(nil, mockCompanyRepo, apiLogger)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	companiesService := NewCompaniesService(nil, mockCompanyRepo, mockRedisRepo, nil, apiLogger)
 
 	company := &models.Company{
 		CompanyID: uuid.New(),
 	}
 
-	span, ctxWithTrace := opentracing.StartSpanFromContext(context.Background(),
+	ctxWithTrace, span := tracing.StartSpan(context.Background(),
 		"companiesService.Delete")
-	defer span.Finish()
+	defer span.End()
 
-	mockCompanyRepo.EXPECT().Delete(ctxWithTrace, gomock.Eq(company.CompanyID)).Return(nil)
+	mockCompanyRepo.EXPECT().Delete(ctxWithTrace, gomock.Eq(company.CompanyID)).
+		Return(company, nil)
+	mockRedisRepo.EXPECT().DeleteCompanyCtx(ctxWithTrace, company.CompanyID).Return(nil)
 
 	err := companiesService.Delete(context.Background(), company.CompanyID)
 	require.NoError(t, err)
@@ -105,7 +156,8 @@ func TestCompaniesService_GetByID(t *testing.T) {
 
 	apiLogger := logger.NewApiLogger(nil)
 	mockCompanyRepo := mock.NewMockRepository(ctrl)
-	companiesService := NewCompaniesService(nil, mockCompanyRepo, apiLogger)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	companiesService := NewCompaniesService(nil, mockCompanyRepo, mockRedisRepo, nil, apiLogger)
 
 	company := &models.Company{
 		CompanyID:          uuid.New(),
@@ -116,10 +168,17 @@ func TestCompaniesService_GetByID(t *testing.T) {
 		CompanyType:        "Corporations",
 	}
 
-	span, ctxWithTrace := opentracing.StartSpanFromContext(context.Background(),
+	ctxWithTrace, span := tracing.StartSpan(context.Background(),
 		"companiesService.GetByID")
-	defer span.Finish()
-
+	defer span.End()
+
+	// The cache misses and calls the loader
+	mockRedisRepo.EXPECT().GetByIDCtx(ctxWithTrace, company.CompanyID, gomock.Any()).DoAndReturn(
+		func(
+			ctx context.Context, _ uuid.UUID, load cache.Loader[models.Company],
+		) (*models.Company, error) {
+			return load(ctx)
+		})
 	mockCompanyRepo.EXPECT().GetByID(ctxWithTrace,
 		gomock.Eq(company.CompanyID)).Return(company, nil)
 
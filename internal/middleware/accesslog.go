@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"companies-service/internal/models"
+	"companies-service/pkg/tracing"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+)
+
+const redacted = "REDACTED"
+
+// Access log middleware writes a structured line per request through the app logger.
+// The successful requests are sampled, the failed ones are always logged.
+func (mw *MiddlewareManager) AccessLogMiddleware() gin.HandlerFunc {
+	accessLog := mw.cfg.AccessLog
+
+	redactParams := make(map[string]struct{}, len(accessLog.RedactParams))
+	for _, param := range accessLog.RedactParams {
+		redactParams[strings.ToLower(param)] = struct{}{}
+	}
+
+	return func(c *gin.Context) {
+		if !accessLog.Enabled || skipAccessLog(accessLog.SkipPaths, c) {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		c.Next()
+		latency := time.Since(start)
+
+		status := c.Writer.Status()
+		if status < http.StatusBadRequest && rand.Float64() >= accessLog.SampleRate {
+			return
+		}
+
+		bytes := c.Writer.Size()
+		if bytes < 0 {
+			bytes = 0
+		}
+
+		route := routeLabel(c)
+
+		fields := []interface{}{
+			"method", c.Request.Method,
+			"route", route,
+			"path", c.Request.URL.Path,
+			"query", redactQuery(c.Request.URL.RawQuery, redactParams),
+			"status", status,
+			"latency", latency,
+			"bytes", bytes,
+			"client_ip", c.ClientIP(),
+			"user_agent", c.Request.UserAgent(),
+			"request_id", requestid.Get(c),
+			"trace_id", tracing.TraceIDFromContext(c.Request.Context()),
+		}
+		if user, ok := c.Get("user"); ok {
+			if user, ok := user.(*models.User); ok {
+				fields = append(fields, "user_id", user.UserID.String())
+			}
+		}
+		if len(c.Errors) > 0 {
+			fields = append(fields, "errors", c.Errors.String())
+		}
+
+		switch {
+		case status >= http.StatusInternalServerError:
+			mw.logger.Errorw("http request", fields...)
+		case status >= http.StatusBadRequest:
+			mw.logger.Warnw("http request", fields...)
+		default:
+			mw.logger.Infow("http request", fields...)
+		}
+	}
+}
+
+// skipAccessLog reports if the request path or route matches a skipped path,
+// a trailing * matches any suffix
+func skipAccessLog(skipPaths []string, c *gin.Context) bool {
+	for _, skipPath := range skipPaths {
+		if prefix, ok := strings.CutSuffix(skipPath, "*"); ok {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				return true
+			}
+			continue
+		}
+		if c.Request.URL.Path == skipPath || c.FullPath() == skipPath {
+			return true
+		}
+	}
+	return false
+}
+
+// redactQuery replaces the values of the credential params of the raw query, the keys are
+// decoded before they are matched. The params that can not be decoded are dropped
+func redactQuery(rawQuery string, redactParams map[string]struct{}) string {
+	if rawQuery == "" {
+		return ""
+	}
+
+	// The first error is ignored, the params parsed are still logged
+	values, _ := url.ParseQuery(rawQuery)
+	for key, params := range values {
+		if _, ok := redactParams[strings.ToLower(key)]; ok {
+			for i := range params {
+				params[i] = redacted
+			}
+		}
+	}
+	return values.Encode()
+}
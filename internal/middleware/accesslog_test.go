@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactQuery(t *testing.T) {
+	t.Parallel()
+
+	redactParams := map[string]struct{}{"token": {}, "password": {}}
+
+	tests := []struct {
+		name     string
+		rawQuery string
+		want     string
+	}{
+		{name: "Empty", rawQuery: "", want: ""},
+		{name: "Without credentials", rawQuery: "page=1&size=10", want: "page=1&size=10"},
+		{name: "Credential", rawQuery: "page=1&token=secret", want: "page=1&token=REDACTED"},
+		{name: "Case insensitive", rawQuery: "Token=secret", want: "Token=REDACTED"},
+		{name: "Repeated", rawQuery: "token=a&token=b", want: "token=REDACTED&token=REDACTED"},
+		{name: "Encoded key", rawQuery: "%74oken=secret&pass%77ord=secret",
+			want: "password=REDACTED&token=REDACTED"},
+		{name: "Without value", rawQuery: "token", want: "token=REDACTED"},
+		{name: "Invalid escape dropped", rawQuery: "to%zzken=secret&page=1", want: "page=1"},
+		{name: "Semicolon dropped", rawQuery: "page=1;token=secret", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, redactQuery(tt.rawQuery, redactParams))
+		})
+	}
+}
+
+func TestSkipAccessLog(t *testing.T) {
+	t.Parallel()
+
+	skipPaths := []string{"/livez", "/api/v1/companies/:company_id", "/swagger/*"}
+
+	tests := []struct {
+		name string
+		path string
+		want bool
+	}{
+		{name: "Path", path: "/livez", want: true},
+		{name: "Route", path: "/api/v1/companies/1", want: true},
+		{name: "Prefix", path: "/swagger/index.html", want: true},
+		{name: "Prefix without suffix", path: "/swagger/", want: true},
+		{name: "Other path", path: "/readyz", want: false},
+		{name: "Path of a longer route", path: "/livez/details", want: false},
+		{name: "Other route", path: "/api/v1/companies", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var skipped bool
+			router := gin.New()
+			handler := func(c *gin.Context) {
+				skipped = skipAccessLog(skipPaths, c)
+			}
+			router.GET("/livez", handler)
+			router.GET("/livez/details", handler)
+			router.GET("/readyz", handler)
+			router.GET("/api/v1/companies", handler)
+			router.GET("/api/v1/companies/:company_id", handler)
+			router.GET("/swagger/*any", handler)
+
+			router.ServeHTTP(httptest.NewRecorder(),
+				httptest.NewRequest(http.MethodGet, tt.path, nil))
+			assert.Equal(t, tt.want, skipped)
+		})
+	}
+}
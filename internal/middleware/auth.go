@@ -2,11 +2,14 @@ package middleware
 
 import (
 	"companies-service/config"
+	"companies-service/internal/models"
 	"companies-service/pkg/authn"
 	"companies-service/pkg/httphelper"
+	"companies-service/pkg/logger"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
@@ -15,13 +18,12 @@ func (mw *MiddlewareManager) AuthJWTMiddleware(cfg *config.Config) gin.HandlerFu
 	return func(c *gin.Context) {
 		tokenString := authn.ExtractBearerToken(c.Request)
 
-		mw.logger.Infof("auth middleware bearerHeader %s", tokenString)
-
 		if tokenString != "" {
-			if err := mw.validateJWTToken(tokenString, cfg); err != nil {
-				mw.logger.Error("middleware validateJWTToken",
+			if err := mw.validateJWTToken(c, tokenString, cfg); err != nil {
+				logger.FromContext(c.Request.Context(), mw.logger).Error("middleware validateJWTToken",
 					zap.String("headerJWT", err.Error()))
-				c.String(http.StatusUnauthorized, httphelper.ErrUnauthorized.Error())
+				httphelper.ProblemResponse(c, httphelper.NewRestErrorWithCode(http.StatusUnauthorized,
+					httphelper.CodeInvalidToken, httphelper.ErrInvalidJWTToken.Error(), err))
 				c.Abort()
 				return
 			}
@@ -32,15 +34,40 @@ func (mw *MiddlewareManager) AuthJWTMiddleware(cfg *config.Config) gin.HandlerFu
 
 		cookie, err := c.Cookie("jwt-token")
 		if err != nil {
-			mw.logger.Errorf("c.Cookie", err.Error())
-			c.String(http.StatusUnauthorized, httphelper.ErrUnauthorized.Error())
+			logger.FromContext(c.Request.Context(), mw.logger).Errorf("c.Cookie: %s", err.Error())
+			httphelper.ProblemResponse(c, httphelper.NewUnauthorizedError(err))
 			c.Abort()
 			return
 		}
 
-		if err = mw.validateJWTToken(cookie, cfg); err != nil {
-			mw.logger.Errorf("validateJWTToken", err.Error())
-			c.String(http.StatusUnauthorized, httphelper.ErrUnauthorized.Error())
+		if err = mw.validateJWTToken(c, cookie, cfg); err != nil {
+			logger.FromContext(c.Request.Context(), mw.logger).Errorf("validateJWTToken: %s",
+				err.Error())
+			httphelper.ProblemResponse(c, httphelper.NewRestErrorWithCode(http.StatusUnauthorized,
+				httphelper.CodeInvalidToken, httphelper.ErrInvalidJWTToken.Error(), err))
+			c.Abort()
+			return
+		}
+
+		// The cookie is sent by the browser on its own, unlike the bearer token
+		if err = mw.checkCSRF(c); err != nil {
+			httphelper.ErrResponseWithLog(c, mw.logger, err)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// Admin role based auth middleware, must be used after AuthJWTMiddleware
+func (mw *MiddlewareManager) AdminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := c.MustGet("user").(*models.User)
+		if !ok || user.Role == nil || *user.Role != models.RoleAdmin {
+			logger.FromContext(c.Request.Context(), mw.logger).Errorf(
+				"AdminMiddleware, IPAddress: %s, Error: %s", c.ClientIP(), httphelper.ErrPermissionDenied)
+			httphelper.ProblemResponse(c, httphelper.ErrPermissionDenied)
 			c.Abort()
 			return
 		}
@@ -49,15 +76,39 @@ func (mw *MiddlewareManager) AuthJWTMiddleware(cfg *config.Config) gin.HandlerFu
 	}
 }
 
-func (mw *MiddlewareManager) validateJWTToken(tokenString string, cfg *config.Config) error {
+func (mw *MiddlewareManager) validateJWTToken(
+	c *gin.Context, tokenString string, cfg *config.Config,
+) error {
 	if tokenString == "" {
 		return httphelper.ErrInvalidJWTToken
 	}
 
-	_, err := authn.ValidateJWT(tokenString, cfg)
+	claims, err := authn.ValidateJWT(tokenString, cfg)
+	if err != nil {
+		return err
+	}
+
+	userID, ok := claims["id"].(string)
+	if !ok {
+		return httphelper.ErrInvalidJWTClaims
+	}
+
+	userUUID, err := uuid.Parse(userID)
 	if err != nil {
 		return err
 	}
 
+	user, err := mw.authService.GetByID(c, userUUID)
+	if err != nil {
+		return err
+	}
+
+	c.Set("user", user)
+
+	// The lines logged for the request from now on carry the user id
+	ctx := c.Request.Context()
+	requestLogger := logger.FromContext(ctx, mw.logger).With("user_id", user.UserID.String())
+	c.Request = c.Request.WithContext(logger.NewContext(ctx, requestLogger))
+
 	return nil
 }
@@ -0,0 +1,242 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	encodingBrotli   = "br"
+	encodingGzip     = "gzip"
+	encodingIdentity = "identity"
+)
+
+// encoder is implemented by the gzip and brotli writers
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// Compression middleware compresses the responses of at least MinSize bytes
+// with the encoding negotiated by the Accept-Encoding header, brotli preferred over gzip
+func (mw *MiddlewareManager) CompressionMiddleware() gin.HandlerFunc {
+	compression := mw.cfg.Compression
+	pools := map[string]*sync.Pool{
+		encodingBrotli: {New: func() interface{} {
+			return brotli.NewWriterLevel(io.Discard, compression.BrotliLevel)
+		}},
+		encodingGzip: {New: func() interface{} {
+			gzipWriter, err := gzip.NewWriterLevel(io.Discard, compression.GzipLevel)
+			if err != nil {
+				gzipWriter = gzip.NewWriter(io.Discard)
+			}
+			return gzipWriter
+		}},
+	}
+
+	return func(c *gin.Context) {
+		if !compression.Enabled || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"))
+		if encoding == "" {
+			c.Next()
+			return
+		}
+
+		writer := &compressWriter{
+			ResponseWriter: c.Writer,
+			encoding:       encoding,
+			pool:           pools[encoding],
+			minSize:        compression.MinSize,
+		}
+		defer writer.finish()
+
+		c.Writer = writer
+		c.Next()
+	}
+}
+
+// compressWriter buffers the response until it reaches the min size,
+// then it compresses it. Smaller responses are sent as is.
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding string
+	pool     *sync.Pool
+	minSize  int
+	status   int
+	size     int
+	buf      []byte
+	started  bool
+	encoder  encoder
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	if !w.started {
+		w.status = code
+	}
+}
+
+func (w *compressWriter) WriteHeaderNow() {
+	if !w.started {
+		w.start(false)
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	w.size += len(data)
+	if !w.started {
+		w.buf = append(w.buf, data...)
+		if len(w.buf) < w.minSize {
+			return len(data), nil
+		}
+		if err := w.start(true); err != nil {
+			return 0, err
+		}
+		return len(data), nil
+	}
+
+	if w.encoder != nil {
+		return w.encoder.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *compressWriter) Status() int {
+	if !w.started && w.status != 0 {
+		return w.status
+	}
+	return w.ResponseWriter.Status()
+}
+
+// Size returns the uncompressed size of the body written by the handler
+func (w *compressWriter) Size() int {
+	if !w.Written() {
+		return -1
+	}
+	return w.size
+}
+
+func (w *compressWriter) Written() bool {
+	return w.started || len(w.buf) > 0 || w.ResponseWriter.Written()
+}
+
+func (w *compressWriter) Flush() {
+	if !w.started {
+		_ = w.start(len(w.buf) >= w.minSize)
+	}
+	if w.encoder != nil {
+		_ = w.encoder.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// start writes the headers and the buffered body, compressed when asked and allowed
+func (w *compressWriter) start(compress bool) error {
+	w.started = true
+	header := w.ResponseWriter.Header()
+
+	status := w.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	if compress && status != http.StatusNoContent && status != http.StatusNotModified &&
+		header.Get("Content-Encoding") == "" {
+		header.Set("Content-Encoding", w.encoding)
+		header.Del("Content-Length")
+		// The compressed representation has its own strong entity tag
+		if etag := header.Get("ETag"); strings.HasSuffix(etag, `"`) {
+			header.Set("ETag", strings.TrimSuffix(etag, `"`)+"-"+w.encoding+`"`)
+		}
+
+		w.encoder = w.pool.Get().(encoder)
+		w.encoder.Reset(w.ResponseWriter)
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	if w.encoder != nil {
+		_, err := w.encoder.Write(buf)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buf)
+	return err
+}
+
+// finish writes the rest of the response
+func (w *compressWriter) finish() {
+	if !w.started {
+		if len(w.buf) == 0 && w.status == 0 {
+			return
+		}
+		_ = w.start(false)
+	}
+
+	if w.encoder != nil {
+		_ = w.encoder.Close()
+		w.encoder.Reset(io.Discard)
+		w.pool.Put(w.encoder)
+		w.encoder = nil
+	}
+}
+
+// negotiateEncoding returns the supported encoding of the Accept-Encoding header
+// with the highest quality, empty when the response must not be compressed.
+// The * wildcard applies to the supported encodings the header does not list
+func negotiateEncoding(acceptEncoding string) string {
+	qualities := make(map[string]float64)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+
+		if name == "*" || name == encodingBrotli || name == encodingGzip {
+			qualities[name] = quality
+		}
+	}
+
+	best, bestQuality := "", 0.0
+	// Brotli is first to win the ties, it compresses json better
+	for _, name := range []string{encodingBrotli, encodingGzip} {
+		quality, ok := qualities[name]
+		if !ok {
+			quality = qualities["*"]
+		}
+		if quality > bestQuality {
+			best, bestQuality = name, quality
+		}
+	}
+
+	return best
+}
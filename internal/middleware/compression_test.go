@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"bytes"
+	"companies-service/config"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddlewareManager_CompressionMiddleware(t *testing.T) {
+	t.Parallel()
+
+	mw := NewMiddlewareManager(nil, &config.Config{Compression: config.Compression{
+		Enabled: true, MinSize: 64, GzipLevel: gzip.DefaultCompression, BrotliLevel: 4,
+	}}, nil, nil, nil)
+
+	large := strings.Repeat("company ", 100)
+	var innerSize int
+
+	router := gin.New()
+	router.Use(gin.RecoveryWithWriter(io.Discard))
+	router.Use(mw.CompressionMiddleware())
+	router.Use(func(c *gin.Context) {
+		c.Next()
+		innerSize = c.Writer.Size()
+	})
+	router.GET("/large", mw.ETagMiddleware(), func(c *gin.Context) {
+		c.String(http.StatusOK, large)
+	})
+	router.GET("/small", func(c *gin.Context) {
+		c.String(http.StatusOK, "small")
+	})
+	router.GET("/panic", mw.ETagMiddleware(), func(c *gin.Context) {
+		panic("handler failure")
+	})
+
+	t.Run("Gzip", func(t *testing.T) {
+		w := performRequest(router, "/large", map[string]string{"Accept-Encoding": "gzip"})
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, encodingGzip, w.Header().Get("Content-Encoding"))
+		assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+		assert.True(t, strings.HasSuffix(w.Header().Get("ETag"), `-gzip"`))
+
+		reader, err := gzip.NewReader(w.Body)
+		require.NoError(t, err)
+		body, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, large, string(body))
+		assert.Equal(t, len(large), innerSize)
+	})
+
+	t.Run("Brotli preferred", func(t *testing.T) {
+		w := performRequest(router, "/large", map[string]string{"Accept-Encoding": "gzip, br"})
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, encodingBrotli, w.Header().Get("Content-Encoding"))
+
+		body, err := io.ReadAll(brotli.NewReader(bytes.NewReader(w.Body.Bytes())))
+		require.NoError(t, err)
+		assert.Equal(t, large, string(body))
+		assert.Equal(t, len(large), innerSize)
+	})
+
+	t.Run("Not modified", func(t *testing.T) {
+		etag := performRequest(router, "/large",
+			map[string]string{"Accept-Encoding": "gzip"}).Header().Get("ETag")
+
+		w := performRequest(router, "/large",
+			map[string]string{"Accept-Encoding": "gzip", "If-None-Match": etag})
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.Empty(t, w.Body.String())
+	})
+
+	t.Run("Smaller than min size", func(t *testing.T) {
+		w := performRequest(router, "/small", map[string]string{"Accept-Encoding": "gzip"})
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.Equal(t, "small", w.Body.String())
+		assert.Equal(t, len("small"), innerSize)
+	})
+
+	t.Run("Identity", func(t *testing.T) {
+		w := performRequest(router, "/large", map[string]string{"Accept-Encoding": "identity"})
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.Equal(t, large, w.Body.String())
+	})
+
+	t.Run("Handler panic", func(t *testing.T) {
+		w := performRequest(router, "/panic", map[string]string{"Accept-Encoding": "gzip"})
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+	})
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		want           string
+	}{
+		{name: "Empty", acceptEncoding: "", want: ""},
+		{name: "Gzip", acceptEncoding: "gzip", want: encodingGzip},
+		{name: "Brotli wins ties", acceptEncoding: "gzip, br", want: encodingBrotli},
+		{name: "Quality", acceptEncoding: "br;q=0.5, gzip;q=0.8", want: encodingGzip},
+		{name: "Refused", acceptEncoding: "gzip;q=0", want: ""},
+		{name: "Wildcard", acceptEncoding: "*", want: encodingBrotli},
+		{name: "Wildcard without refused brotli", acceptEncoding: "br;q=0, *", want: encodingGzip},
+		{name: "Wildcard below gzip", acceptEncoding: "gzip, *;q=0.5", want: encodingGzip},
+		{name: "Wildcard refused", acceptEncoding: "br;q=0, gzip;q=0, *", want: ""},
+		{name: "Unsupported", acceptEncoding: "deflate, identity", want: ""},
+		{name: "Invalid quality", acceptEncoding: "br;q=x, gzip", want: encodingGzip},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, negotiateEncoding(tt.acceptEncoding))
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ETag middleware sets the strong entity tag of the successful GET responses
+// and answers 304 Not Modified when it matches the If-None-Match header
+func (mw *MiddlewareManager) ETagMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.Next()
+			return
+		}
+
+		writer := &bufferedWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = writer
+		// Restored when the handler panics too, the recovery writes the error response
+		defer func() { c.Writer = writer.ResponseWriter }()
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		if writer.status != http.StatusOK {
+			writer.flush()
+			return
+		}
+
+		hash := sha256.Sum256(writer.buf)
+		etag := `"` + base64.RawURLEncoding.EncodeToString(hash[:16]) + `"`
+		c.Header("ETag", etag)
+
+		if etagMatches(c.GetHeader("If-None-Match"), etag) {
+			c.Writer.Header().Del("Content-Length")
+			c.Writer.Header().Del("Content-Type")
+			c.Writer.WriteHeader(http.StatusNotModified)
+			c.Writer.WriteHeaderNow()
+			return
+		}
+
+		writer.flush()
+	}
+}
+
+// etagMatches compares the If-None-Match entity tags with the weak comparison,
+// ignoring the content coding suffix of the compressed representations
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+		for _, encoding := range []string{encodingBrotli, encodingGzip} {
+			if candidate == strings.TrimSuffix(etag, `"`)+"-"+encoding+`"` {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// bufferedWriter holds the response until the entity tag is computed
+type bufferedWriter struct {
+	gin.ResponseWriter
+	status int
+	buf    []byte
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	w.buf = append(w.buf, data...)
+	return len(data), nil
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *bufferedWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferedWriter) Size() int {
+	if len(w.buf) == 0 {
+		return w.ResponseWriter.Size()
+	}
+	return len(w.buf)
+}
+
+func (w *bufferedWriter) Written() bool {
+	return len(w.buf) > 0 || w.ResponseWriter.Written()
+}
+
+func (w *bufferedWriter) flush() {
+	w.ResponseWriter.WriteHeader(w.status)
+	if len(w.buf) == 0 {
+		w.ResponseWriter.WriteHeaderNow()
+		return
+	}
+	_, _ = w.ResponseWriter.Write(w.buf)
+}
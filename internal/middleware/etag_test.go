@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"companies-service/config"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddlewareManager_ETagMiddleware(t *testing.T) {
+	t.Parallel()
+
+	mw := NewMiddlewareManager(nil, &config.Config{}, nil, nil, nil)
+
+	router := gin.New()
+	router.Use(gin.RecoveryWithWriter(io.Discard))
+	router.GET("/company", mw.ETagMiddleware(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"company_name": "Test Company"})
+	})
+	router.GET("/missing", mw.ETagMiddleware(), func(c *gin.Context) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	})
+	router.GET("/panic", mw.ETagMiddleware(), func(c *gin.Context) {
+		c.String(http.StatusOK, "partial")
+		panic("handler failure")
+	})
+
+	t.Run("Entity tag set", func(t *testing.T) {
+		w := performRequest(router, "/company", nil)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NotEmpty(t, w.Header().Get("ETag"))
+		assert.JSONEq(t, `{"company_name": "Test Company"}`, w.Body.String())
+	})
+
+	t.Run("Not modified", func(t *testing.T) {
+		etag := performRequest(router, "/company", nil).Header().Get("ETag")
+
+		w := performRequest(router, "/company", map[string]string{"If-None-Match": etag})
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Empty(t, w.Body.String())
+
+		w = performRequest(router, "/company", map[string]string{"If-None-Match": `"other"`})
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Error response without entity tag", func(t *testing.T) {
+		w := performRequest(router, "/missing", nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Empty(t, w.Header().Get("ETag"))
+		assert.JSONEq(t, `{"error": "not found"}`, w.Body.String())
+	})
+
+	t.Run("Handler panic", func(t *testing.T) {
+		w := performRequest(router, "/panic", nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Empty(t, w.Header().Get("ETag"))
+		assert.Empty(t, w.Body.String())
+	})
+}
+
+func TestEtagMatches(t *testing.T) {
+	t.Parallel()
+
+	etag := `"abc"`
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		want        bool
+	}{
+		{name: "Empty", ifNoneMatch: "", want: false},
+		{name: "Same", ifNoneMatch: `"abc"`, want: true},
+		{name: "Weak", ifNoneMatch: `W/"abc"`, want: true},
+		{name: "Wildcard", ifNoneMatch: "*", want: true},
+		{name: "List", ifNoneMatch: `"other", "abc"`, want: true},
+		{name: "Gzip", ifNoneMatch: `"abc-gzip"`, want: true},
+		{name: "Brotli", ifNoneMatch: `"abc-br"`, want: true},
+		{name: "Other", ifNoneMatch: `"abcd"`, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, etagMatches(tt.ifNoneMatch, etag))
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"companies-service/config"
+	"companies-service/pkg/logger"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// performRequest is a helper function to perform a test GET request with headers
+func performRequest(r http.Handler, path string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// newTestLogger returns a logger that only writes the fatal errors
+func newTestLogger() logger.Logger {
+	apiLogger := logger.NewApiLogger(&config.Config{
+		Logger: config.Logger{Level: "fatal", Encoding: "console"},
+	})
+	apiLogger.InitLogger()
+	return apiLogger
+}
+
+// useTestTracerProvider sets a global tracer provider that records the ended spans
+// and the W3C trace context propagation until the test ends
+func useTestTracerProvider(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	return recorder
+}
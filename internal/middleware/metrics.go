@@ -7,17 +7,31 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// Prometheus metrics middleware
+// unmatchedRoute labels the requests of no route, their paths are unbounded
+const unmatchedRoute = "unmatched"
+
+// Prometheus metrics middleware, the requests are labeled by their route template
 func (mw *MiddlewareManager) MetricsMiddleware(metrics metric.Metrics) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
+		metrics.IncInFlight()
+		defer metrics.DecInFlight()
 
 		c.Next()
 
 		status := c.Writer.Status()
+		route := routeLabel(c)
+
+		metrics.ObserveResponseTime(status, c.Request.Method, route, time.Since(start).Seconds())
+		metrics.ObserveResponseSize(status, c.Request.Method, route, max(c.Writer.Size(), 0))
+		metrics.IncHits(status, c.Request.Method, route)
+	}
+}
 
-		metrics.ObserveResponseTime(status, c.Request.Method, c.Request.URL.Path,
-			time.Since(start).Seconds())
-		metrics.IncHits(status, c.Request.Method, c.Request.URL.Path)
+// routeLabel returns the route template of the request
+func routeLabel(c *gin.Context) string {
+	if route := c.FullPath(); route != "" {
+		return route
 	}
+	return unmatchedRoute
 }
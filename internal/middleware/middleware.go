@@ -4,6 +4,7 @@ import (
 	"companies-service/config"
 	"companies-service/internal/auth"
 	"companies-service/pkg/logger"
+	"companies-service/pkg/ratelimit"
 )
 
 // Middleware manager
@@ -11,12 +12,18 @@ type MiddlewareManager struct {
 	authService auth.Service
 	cfg         *config.Config
 	origins     []string
+	rateLimiter ratelimit.Limiter
 	logger      logger.Logger
 }
 
 // Middleware manager constructor
 func NewMiddlewareManager(
-	authService auth.Service, cfg *config.Config, origins []string, logger logger.Logger,
+	authService auth.Service,
+	cfg *config.Config,
+	origins []string,
+	rateLimiter ratelimit.Limiter,
+	logger logger.Logger,
 ) *MiddlewareManager {
-	return &MiddlewareManager{authService: authService, cfg: cfg, origins: origins, logger: logger}
+	return &MiddlewareManager{authService: authService, cfg: cfg, origins: origins,
+		rateLimiter: rateLimiter, logger: logger}
 }
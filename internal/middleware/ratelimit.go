@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"companies-service/config"
+	"companies-service/pkg/authn"
+	"companies-service/pkg/httphelper"
+	"companies-service/pkg/logger"
+	"companies-service/pkg/metric"
+	"companies-service/pkg/ratelimit"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	apiKeyHeader = "X-API-Key"
+	defaultRoute = "*"
+
+	rateLimitKeyUser   = "user"
+	rateLimitKeyAPIKey = "api_key"
+	rateLimitKeyIP     = "ip"
+)
+
+// Redis rate limiting middleware, limits requests by user id, api key or client ip
+// with the policy of the route or the default policy
+func (mw *MiddlewareManager) RateLimitMiddleware(metrics metric.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !mw.cfg.RateLimit.Enabled {
+			c.Next()
+			return
+		}
+
+		policy := mw.rateLimitPolicy(c.Request.Method, c.FullPath())
+		if policy.Requests <= 0 || policy.Period <= 0 {
+			c.Next()
+			return
+		}
+
+		keyType, key := mw.rateLimitKey(c)
+		route := policy.Route
+		if route == "" {
+			route = defaultRoute
+		}
+
+		result, err := mw.rateLimiter.Allow(c,
+			fmt.Sprintf("%s:%s:%s:%s", c.Request.Method, route, keyType, key),
+			ratelimit.Limit{
+				Requests: policy.Requests,
+				Period:   time.Duration(policy.Period) * time.Second,
+				Burst:    policy.Burst,
+			})
+		if err != nil {
+			// Fail open, the rate limiter must not take the api down with redis
+			logger.FromContext(c.Request.Context(), mw.logger).Errorf(
+				"RateLimitMiddleware.Allow, Error: %s", err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", ceilSeconds(result.ResetAfter))
+
+		if !result.Allowed {
+			metrics.IncRateLimited(c.Request.Method, route, keyType)
+			c.Header("Retry-After", ceilSeconds(result.RetryAfter))
+			httphelper.ProblemResponse(c, httphelper.ErrTooManyRequests)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// rateLimitPolicy returns the policy of the route or the default policy
+func (mw *MiddlewareManager) rateLimitPolicy(method, route string) config.RateLimitPolicy {
+	for _, policy := range mw.cfg.RateLimit.Policies {
+		if policy.Route == route && (policy.Method == "" || strings.EqualFold(policy.Method, method)) {
+			return policy
+		}
+	}
+	return mw.cfg.RateLimit.Default
+}
+
+// rateLimitKey identifies the client by the user id of a valid jwt token,
+// then by the api key and finally by the client ip
+func (mw *MiddlewareManager) rateLimitKey(c *gin.Context) (string, string) {
+	tokenString := authn.ExtractBearerToken(c.Request)
+	if tokenString == "" {
+		tokenString, _ = c.Cookie("jwt-token")
+	}
+	if tokenString != "" {
+		if claims, err := authn.ValidateJWT(tokenString, mw.cfg); err == nil {
+			if userID, ok := claims["id"].(string); ok && userID != "" {
+				return rateLimitKeyUser, userID
+			}
+		}
+	}
+
+	if apiKey := c.GetHeader(apiKeyHeader); apiKey != "" {
+		hash := sha256.Sum256([]byte(apiKey))
+		return rateLimitKeyAPIKey, hex.EncodeToString(hash[:16])
+	}
+
+	return rateLimitKeyIP, c.ClientIP()
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"companies-service/config"
+	"companies-service/pkg/httphelper"
+	"companies-service/pkg/logger"
+	"companies-service/pkg/metric"
+	"companies-service/pkg/ratelimit"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingLimiter is a limiter whose redis is down
+type failingLimiter struct{}
+
+func (failingLimiter) Allow(context.Context, string, ratelimit.Limit) (*ratelimit.Result, error) {
+	return nil, errors.New("redis is down")
+}
+
+func newRateLimitRouter(
+	t *testing.T, limiter ratelimit.Limiter, metrics metric.Metrics,
+) http.Handler {
+	t.Helper()
+
+	cfg := &config.Config{RateLimit: config.RateLimit{
+		Enabled: true,
+		Default: config.RateLimitPolicy{Requests: 100, Period: 60},
+		Policies: []config.RateLimitPolicy{
+			{Method: http.MethodGet, Route: "/login", Requests: 1, Period: 60},
+		},
+	}}
+	apiLogger := logger.NewApiLogger(&config.Config{
+		Logger: config.Logger{Level: "fatal", Encoding: "console"},
+	})
+	apiLogger.InitLogger()
+	mw := NewMiddlewareManager(nil, cfg, nil, limiter, apiLogger)
+
+	router := gin.New()
+	router.Use(mw.RateLimitMiddleware(metrics))
+	ok := func(c *gin.Context) {
+		c.String(http.StatusOK, "OK")
+	}
+	router.GET("/login", ok)
+	router.GET("/companies", ok)
+	return router
+}
+
+func TestMiddlewareManager_RateLimitMiddleware(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Too many requests", func(t *testing.T) {
+		t.Parallel()
+
+		mr := miniredis.RunT(t)
+		mr.SetTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+		redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+		t.Cleanup(func() { _ = redisClient.Close() })
+
+		metrics, err := metric.CreateMetrics(metric.NewRegistry(), "test")
+		require.NoError(t, err)
+		router := newRateLimitRouter(t, ratelimit.NewRedisLimiter(redisClient), metrics)
+
+		w := performRequest(router, "/login", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "1", w.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "60", w.Header().Get("RateLimit-Reset"))
+		assert.Empty(t, w.Header().Get("Retry-After"))
+
+		w = performRequest(router, "/login", nil)
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, httphelper.ProblemContentType, w.Header().Get("Content-Type"))
+		assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "60", w.Header().Get("Retry-After"))
+		assert.NotEqual(t, "OK", w.Body.String())
+
+		limited := metrics.(*metric.PrometheusMetrics).Limited
+		assert.Equal(t, float64(1), testutil.ToFloat64(
+			limited.WithLabelValues(http.MethodGet, "/login", rateLimitKeyIP)))
+
+		// The other routes are limited by the default policy
+		w = performRequest(router, "/companies", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "100", w.Header().Get("RateLimit-Limit"))
+
+		// The clients are limited separately
+		w = performRequest(router, "/login", map[string]string{apiKeyHeader: "key"})
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Fail open", func(t *testing.T) {
+		t.Parallel()
+
+		router := newRateLimitRouter(t, failingLimiter{}, nil)
+
+		for i := 0; i < 3; i++ {
+			w := performRequest(router, "/login", nil)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+		}
+	})
+}
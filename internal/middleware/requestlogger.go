@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"companies-service/pkg/httphelper"
+	"companies-service/pkg/logger"
+	"companies-service/pkg/tracing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Request logger middleware stores the request id and a child logger of the request
+// in the request context, must be used after the request id and tracing middlewares
+func (mw *MiddlewareManager) RequestLoggerMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		requestID := httphelper.GetRequestID(c)
+
+		requestLogger := mw.logger.With(
+			"request_id", requestID,
+			"trace_id", tracing.TraceIDFromContext(ctx),
+			"span_id", tracing.SpanIDFromContext(ctx),
+			"method", c.Request.Method,
+			"route", c.FullPath(),
+		)
+
+		ctx = tracing.ContextWithRequestID(ctx, requestID)
+		c.Request = c.Request.WithContext(logger.NewContext(ctx, requestLogger))
+
+		c.Next()
+	}
+}
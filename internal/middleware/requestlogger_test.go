@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"companies-service/pkg/logger"
+	"companies-service/pkg/tracing"
+	"net/http"
+	"testing"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fieldsLogger records the fields of the child loggers
+type fieldsLogger struct {
+	logger.Logger
+	fields map[string]interface{}
+}
+
+func (l *fieldsLogger) With(keysAndValues ...interface{}) logger.Logger {
+	fields := make(map[string]interface{}, len(l.fields)+len(keysAndValues)/2)
+	for key, value := range l.fields {
+		fields[key] = value
+	}
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		fields[keysAndValues[i].(string)] = keysAndValues[i+1]
+	}
+	return &fieldsLogger{Logger: l.Logger, fields: fields}
+}
+
+func TestMiddlewareManager_RequestLoggerMiddleware(t *testing.T) {
+	// The test sets the global tracer provider, it does not run in parallel
+	useTestTracerProvider(t)
+
+	mw := NewMiddlewareManager(nil, nil, nil, nil, &fieldsLogger{Logger: newTestLogger()})
+
+	var (
+		requestLogger *fieldsLogger
+		requestID     string
+	)
+	router := gin.New()
+	router.Use(requestid.New(), mw.TracingMiddleware(), mw.RequestLoggerMiddleware())
+	router.GET("/companies/:id", func(c *gin.Context) {
+		requestLogger, _ = logger.FromContext(c.Request.Context(), nil).(*fieldsLogger)
+		requestID = tracing.RequestIDFromContext(c.Request.Context())
+		c.String(http.StatusOK, "OK")
+	})
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	w := performRequest(router, "/companies/1", map[string]string{
+		tracing.RequestIDHeader: "client-request-id",
+		"traceparent":           "00-" + traceID + "-00f067aa0ba902b7-01",
+	})
+
+	assert.Equal(t, "client-request-id", w.Header().Get(tracing.RequestIDHeader))
+	assert.Equal(t, "client-request-id", requestID)
+	require.NotNil(t, requestLogger)
+	assert.Equal(t, "client-request-id", requestLogger.fields["request_id"])
+	assert.Equal(t, traceID, requestLogger.fields["trace_id"])
+	assert.Equal(t, "/companies/:id", requestLogger.fields["route"])
+
+	// The generated request id is echoed and logged
+	w = performRequest(router, "/companies/1", nil)
+
+	generatedID := w.Header().Get(tracing.RequestIDHeader)
+	assert.NotEmpty(t, generatedID)
+	assert.Equal(t, generatedID, requestID)
+	require.NotNil(t, requestLogger)
+	assert.Equal(t, generatedID, requestLogger.fields["request_id"])
+	assert.NotEmpty(t, requestLogger.fields["trace_id"])
+	assert.NotEqual(t, traceID, requestLogger.fields["trace_id"])
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"companies-service/pkg/httphelper"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// CORS middleware with the policy of the config
+func (mw *MiddlewareManager) CORSMiddleware() (gin.HandlerFunc, error) {
+	corsConfig := cors.Config{
+		AllowOrigins:     mw.origins,
+		AllowMethods:     mw.cfg.CORS.AllowMethods,
+		AllowHeaders:     mw.cfg.CORS.AllowHeaders,
+		ExposeHeaders:    mw.cfg.CORS.ExposeHeaders,
+		AllowCredentials: mw.cfg.CORS.AllowCredentials,
+		MaxAge:           time.Duration(mw.cfg.CORS.MaxAge) * time.Second,
+	}
+
+	for _, origin := range corsConfig.AllowOrigins {
+		// Browsers reject credentialed responses allowed for any origin
+		if origin == "*" && corsConfig.AllowCredentials {
+			return nil, errors.New("cors: the wildcard origin can not allow credentials")
+		}
+	}
+	if err := corsConfig.Validate(); err != nil {
+		return nil, errors.Wrap(err, "cors.Config.Validate")
+	}
+
+	return cors.New(corsConfig), nil
+}
+
+// Security headers middleware
+func (mw *MiddlewareManager) SecurityHeadersMiddleware() gin.HandlerFunc {
+	security := mw.cfg.Security
+
+	hsts := ""
+	if security.HSTSMaxAge > 0 {
+		hsts = fmt.Sprintf("max-age=%d", security.HSTSMaxAge)
+		if security.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+	}
+
+	return func(c *gin.Context) {
+		header := c.Writer.Header()
+		header.Set("X-Content-Type-Options", "nosniff")
+		if hsts != "" && c.Request.TLS != nil {
+			header.Set("Strict-Transport-Security", hsts)
+		}
+		if security.ContentSecurityPolicy != "" {
+			header.Set("Content-Security-Policy", security.ContentSecurityPolicy)
+		}
+		if security.FrameOptions != "" {
+			header.Set("X-Frame-Options", security.FrameOptions)
+		}
+		if security.ReferrerPolicy != "" {
+			header.Set("Referrer-Policy", security.ReferrerPolicy)
+		}
+
+		c.Next()
+	}
+}
+
+// checkCSRF checks the double submit token of the unsafe requests of the cookie auth,
+// the header must match the csrf cookie
+func (mw *MiddlewareManager) checkCSRF(c *gin.Context) error {
+	if !mw.cfg.CSRF.Enabled {
+		return nil
+	}
+
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return nil
+	}
+
+	cookieToken, err := c.Cookie(mw.cfg.CSRF.CookieName)
+	if err != nil || cookieToken == "" {
+		return httphelper.ErrInvalidCSRFToken
+	}
+
+	headerToken := c.GetHeader(mw.cfg.CSRF.HeaderName)
+	if subtle.ConstantTimeCompare([]byte(headerToken), []byte(cookieToken)) != 1 {
+		return httphelper.ErrInvalidCSRFToken
+	}
+
+	return nil
+}
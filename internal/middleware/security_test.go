@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"companies-service/config"
+	"companies-service/internal/auth/mock"
+	"companies-service/internal/models"
+	"companies-service/pkg/authn"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddlewareManager_CSRF(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{JwtSecretKey: "secretkey"},
+		CSRF:   config.CSRF{Enabled: true, CookieName: "csrf-token", HeaderName: "X-CSRF-Token"},
+	}
+	user := &models.User{UserID: uuid.New(), Email: "user@test.com"}
+	token, err := authn.GenerateJWTToken(user, cfg)
+	require.NoError(t, err)
+
+	authService := mock.NewMockService(ctrl)
+	authService.EXPECT().GetByID(gomock.Any(), user.UserID).Return(user, nil).AnyTimes()
+	mw := NewMiddlewareManager(authService, cfg, nil, nil, newTestLogger())
+
+	router := gin.New()
+	ok := func(c *gin.Context) {
+		c.String(http.StatusOK, "OK")
+	}
+	for _, method := range []string{http.MethodGet, http.MethodHead, http.MethodOptions,
+		http.MethodPost, http.MethodDelete} {
+		router.Handle(method, "/companies", mw.AuthJWTMiddleware(cfg), ok)
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		bearer     bool
+		csrfCookie string
+		csrfHeader string
+		want       int
+	}{
+		{name: "Missing token", method: http.MethodPost, want: http.StatusForbidden},
+		{name: "Missing header", method: http.MethodPost, csrfCookie: "token",
+			want: http.StatusForbidden},
+		{name: "Missing cookie", method: http.MethodPost, csrfHeader: "token",
+			want: http.StatusForbidden},
+		{name: "Mismatched token", method: http.MethodDelete, csrfCookie: "token",
+			csrfHeader: "other", want: http.StatusForbidden},
+		{name: "Matching token", method: http.MethodPost, csrfCookie: "token",
+			csrfHeader: "token", want: http.StatusOK},
+		{name: "GET", method: http.MethodGet, want: http.StatusOK},
+		{name: "HEAD", method: http.MethodHead, want: http.StatusOK},
+		{name: "OPTIONS", method: http.MethodOptions, want: http.StatusOK},
+		{name: "Bearer auth", method: http.MethodPost, bearer: true, want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/companies", nil)
+			if tt.bearer {
+				req.Header.Set("Authorization", "Bearer "+token)
+			} else {
+				req.AddCookie(&http.Cookie{Name: "jwt-token", Value: token})
+			}
+			if tt.csrfCookie != "" {
+				req.AddCookie(&http.Cookie{Name: "csrf-token", Value: tt.csrfCookie})
+			}
+			if tt.csrfHeader != "" {
+				req.Header.Set("X-CSRF-Token", tt.csrfHeader)
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
+
+func TestMiddlewareManager_SecurityHeadersMiddleware(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{Security: config.SecurityHeaders{
+		HSTSMaxAge:            31536000,
+		HSTSIncludeSubdomains: true,
+		ContentSecurityPolicy: "default-src 'none'",
+		FrameOptions:          "DENY",
+		ReferrerPolicy:        "no-referrer",
+	}}
+	mw := NewMiddlewareManager(nil, cfg, nil, nil, nil)
+
+	router := gin.New()
+	router.Use(mw.SecurityHeadersMiddleware())
+	router.GET("/companies", func(c *gin.Context) {
+		c.String(http.StatusOK, "OK")
+	})
+
+	w := performRequest(router, "/companies", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, "default-src 'none'", w.Header().Get("Content-Security-Policy"))
+	assert.Equal(t, "DENY", w.Header().Get("X-Frame-Options"))
+	assert.Equal(t, "no-referrer", w.Header().Get("Referrer-Policy"))
+	// HSTS is only sent over TLS
+	assert.Empty(t, w.Header().Get("Strict-Transport-Security"))
+
+	req := httptest.NewRequest(http.MethodGet, "/companies", nil)
+	req.TLS = &tls.ConnectionState{}
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, "max-age=31536000; includeSubDomains",
+		w.Header().Get("Strict-Transport-Security"))
+}
+
+func TestMiddlewareManager_CORSMiddleware(t *testing.T) {
+	t.Parallel()
+
+	cors := config.CORS{AllowMethods: []string{http.MethodGet}, AllowCredentials: true}
+
+	mw := NewMiddlewareManager(nil, &config.Config{CORS: cors}, []string{"*"}, nil, nil)
+	_, err := mw.CORSMiddleware()
+	assert.Error(t, err)
+
+	mw = NewMiddlewareManager(nil, &config.Config{CORS: cors},
+		[]string{"http://localhost:3000"}, nil, nil)
+	corsMiddleware, err := mw.CORSMiddleware()
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(corsMiddleware)
+	router.GET("/companies", func(c *gin.Context) {
+		c.String(http.StatusOK, "OK")
+	})
+
+	w := performRequest(router, "/companies", map[string]string{"Origin": "http://localhost:3000"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "http://localhost:3000", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+
+	w = performRequest(router, "/companies", map[string]string{"Origin": "http://evil.com"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+}
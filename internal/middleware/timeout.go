@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"companies-service/pkg/httphelper"
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Timeout middleware bounds every request by the deadline of its route or the default one.
+// The deadline is carried by the request context down to the sql and redis calls.
+func (mw *MiddlewareManager) TimeoutMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timeout := mw.requestTimeout(c.Request.Method, c.FullPath())
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		// The handler gave up without a response, answer for it
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Writer.Written() {
+			httphelper.ErrResponseWithLog(c, mw.logger, httphelper.ErrRequestTimeoutError)
+			c.Abort()
+		}
+	}
+}
+
+// requestTimeout returns the timeout of the route or the default timeout
+func (mw *MiddlewareManager) requestTimeout(method, route string) time.Duration {
+	for _, routeTimeout := range mw.cfg.Server.RouteTimeouts {
+		if routeTimeout.Route == route &&
+			(routeTimeout.Method == "" || strings.EqualFold(routeTimeout.Method, method)) {
+			return time.Duration(routeTimeout.Timeout) * time.Second
+		}
+	}
+	return time.Second * mw.cfg.Server.CtxDefaultTimeout
+}
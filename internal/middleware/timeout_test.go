@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"companies-service/config"
+	"companies-service/pkg/httphelper"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddlewareManager_TimeoutMiddleware(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{Server: config.ServerConfig{
+		CtxDefaultTimeout: 8,
+		RouteTimeouts: []config.RouteTimeout{
+			{Method: http.MethodGet, Route: "/slow", Timeout: 1},
+			{Method: http.MethodGet, Route: "/handled", Timeout: 1},
+			{Route: "/unbounded", Timeout: 0},
+		},
+	}}
+	mw := NewMiddlewareManager(nil, cfg, nil, nil, newTestLogger())
+
+	router := gin.New()
+	router.Use(mw.TimeoutMiddleware())
+	router.GET("/slow", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+	})
+	router.GET("/handled", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		c.String(http.StatusServiceUnavailable, "unavailable")
+	})
+	router.GET("/default", func(c *gin.Context) {
+		deadline, ok := c.Request.Context().Deadline()
+		assert.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(8*time.Second), deadline, time.Second)
+		c.String(http.StatusOK, "OK")
+	})
+	router.GET("/unbounded", func(c *gin.Context) {
+		_, ok := c.Request.Context().Deadline()
+		assert.False(t, ok)
+		c.String(http.StatusOK, "OK")
+	})
+
+	t.Run("Deadline exceeded", func(t *testing.T) {
+		t.Parallel()
+
+		start := time.Now()
+		w := performRequest(router, "/slow", nil)
+		assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+		assert.Equal(t, httphelper.ProblemContentType, w.Header().Get("Content-Type"))
+		assert.Less(t, time.Since(start), 2*time.Second)
+	})
+
+	t.Run("Response of the handler kept", func(t *testing.T) {
+		t.Parallel()
+
+		w := performRequest(router, "/handled", nil)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "unavailable", w.Body.String())
+	})
+
+	t.Run("Default timeout", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, http.StatusOK, performRequest(router, "/default", nil).Code)
+	})
+
+	t.Run("Without timeout", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, http.StatusOK, performRequest(router, "/unbounded", nil).Code)
+	})
+}
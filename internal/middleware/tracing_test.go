@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestMiddlewareManager_TracingMiddleware(t *testing.T) {
+	// The test sets the global tracer provider, it does not run in parallel
+	recorder := useTestTracerProvider(t)
+
+	mw := NewMiddlewareManager(nil, nil, nil, nil, newTestLogger())
+
+	var handlerSpan trace.SpanContext
+	router := gin.New()
+	router.Use(mw.TracingMiddleware())
+	router.GET("/companies/:id", func(c *gin.Context) {
+		handlerSpan = trace.SpanContextFromContext(c.Request.Context())
+		c.String(http.StatusOK, "OK")
+	})
+	router.GET("/failing", func(c *gin.Context) {
+		c.String(http.StatusInternalServerError, "failed")
+	})
+
+	const (
+		traceID  = "4bf92f3577b34da6a3ce929d0e0e4736"
+		parentID = "00f067aa0ba902b7"
+	)
+	performRequest(router, "/companies/1", map[string]string{
+		"traceparent": "00-" + traceID + "-" + parentID + "-01"})
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "GET /companies/:id", span.Name())
+	assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+	assert.Equal(t, traceID, span.SpanContext().TraceID().String())
+	assert.Equal(t, parentID, span.Parent().SpanID().String())
+	assert.True(t, span.Parent().IsRemote())
+	assert.Equal(t, span.SpanContext(), handlerSpan)
+	assert.Contains(t, span.Attributes(), attribute.String("http.route", "/companies/:id"))
+	assert.Contains(t, span.Attributes(), attribute.Int("http.response.status_code", http.StatusOK))
+	assert.Equal(t, codes.Unset, span.Status().Code)
+
+	// A request without trace context starts a new trace, the server errors are recorded
+	performRequest(router, "/failing", nil)
+
+	spans = recorder.Ended()
+	require.Len(t, spans, 2)
+	span = spans[1]
+	assert.NotEqual(t, traceID, span.SpanContext().TraceID().String())
+	assert.False(t, span.Parent().IsValid())
+	assert.Contains(t, span.Attributes(),
+		attribute.Int("http.response.status_code", http.StatusInternalServerError))
+	assert.Equal(t, codes.Error, span.Status().Code)
+}
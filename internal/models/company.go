@@ -13,3 +13,31 @@ type Company struct {
 	Registered         bool      `json:"registered" db:"registered" validate:"required"`
 	CompanyType        string    `json:"company_type" db:"company_type" validate:"required"`
 }
+
+// CompanyUpdate is an updated company with its state before the update
+type CompanyUpdate struct {
+	Company       *Company
+	Previous      *Company
+	ChangedFields []string
+}
+
+// Diff returns the json names of the fields that differ from the previous company
+func (c *Company) Diff(previous *Company) []string {
+	changed := []string{}
+	if c.CompanyName != previous.CompanyName {
+		changed = append(changed, "company_name")
+	}
+	if c.CompanyDescription != previous.CompanyDescription {
+		changed = append(changed, "company_description")
+	}
+	if c.AmountOfEmployees != previous.AmountOfEmployees {
+		changed = append(changed, "amount_of_employees")
+	}
+	if c.Registered != previous.Registered {
+		changed = append(changed, "registered")
+	}
+	if c.CompanyType != previous.CompanyType {
+		changed = append(changed, "company_type")
+	}
+	return changed
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Login failure reasons
+const (
+	LoginFailureUserNotFound    = "user_not_found"
+	LoginFailureInvalidPassword = "invalid_password"
+)
+
+// LoginEvent model, one row per login attempt
+type LoginEvent struct {
+	LoginEventID  uuid.UUID  `json:"login_event_id" db:"login_event_id"`
+	UserID        *uuid.UUID `json:"user_id,omitempty" db:"user_id"`
+	Email         string     `json:"email" db:"email"`
+	IPAddress     string     `json:"ip_address" db:"ip_address"`
+	UserAgent     string     `json:"user_agent" db:"user_agent"`
+	Success       bool       `json:"success" db:"success"`
+	FailureReason *string    `json:"failure_reason,omitempty" db:"failure_reason"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+// LoginClient holds the client details of a login attempt
+type LoginClient struct {
+	IPAddress string
+	UserAgent string
+}
@@ -8,6 +8,9 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// RoleAdmin is the role of the users who can manage other users
+const RoleAdmin = "admin"
+
 // User model
 type User struct {
 	UserID      uuid.UUID  `json:"user_id" db:"user_id" redis:"user_id" validate:"omitempty"`
@@ -0,0 +1,177 @@
+package server
+
+import (
+	"companies-service/pkg/cache"
+	"companies-service/pkg/httphelper"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveConfigKeys are the config key fragments of the values never dumped
+var sensitiveConfigKeys = []string{"password", "secret", "token"}
+
+// newAdminServer returns the admin server of the pprof port, it serves pprof, the metrics
+// and the runtime operations of the service, all protected by the admin token. Without an
+// admin token it serves only the metrics
+func (s *Server) newAdminServer(cacheInvalidator *cache.Invalidator) *http.Server {
+	router := gin.New()
+	router.Use(gin.Recovery())
+
+	server := &http.Server{
+		Addr:              s.cfg.Server.PprofPort,
+		ReadHeaderTimeout: time.Second * s.cfg.Server.ReadTimeout,
+		MaxHeaderBytes:    maxHeaderBytes,
+		Handler:           router,
+	}
+
+	metricsHandler := gin.WrapH(promhttp.HandlerFor(s.registry,
+		promhttp.HandlerOpts{Registry: s.registry}))
+	if s.cfg.Server.AdminToken == "" {
+		router.GET("/metrics", metricsHandler)
+		return server
+	}
+
+	router.Use(s.adminTokenMiddleware())
+	router.GET("/metrics", metricsHandler)
+
+	pprofGroup := router.Group("/debug/pprof")
+	pprofGroup.GET("/", gin.WrapF(pprof.Index))
+	pprofGroup.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+	pprofGroup.GET("/profile", gin.WrapF(pprof.Profile))
+	pprofGroup.GET("/symbol", gin.WrapF(pprof.Symbol))
+	pprofGroup.POST("/symbol", gin.WrapF(pprof.Symbol))
+	pprofGroup.GET("/trace", gin.WrapF(pprof.Trace))
+	pprofGroup.GET("/:profile", gin.WrapF(pprof.Index))
+
+	adminGroup := router.Group("/admin")
+	adminGroup.GET("/log-level", s.getLogLevel)
+	adminGroup.PUT("/log-level", s.setLogLevel)
+	adminGroup.GET("/config", s.getConfig)
+	adminGroup.GET("/caches", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"caches": cacheInvalidator.Prefixes()})
+	})
+	adminGroup.POST("/caches/:prefix/flush", s.flushCache(cacheInvalidator))
+
+	return server
+}
+
+// adminTokenMiddleware accepts only the requests with the admin bearer token
+func (s *Server) adminTokenMiddleware() gin.HandlerFunc {
+	adminToken := []byte(s.cfg.Server.AdminToken)
+	return func(c *gin.Context) {
+		token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if len(adminToken) == 0 || subtle.ConstantTimeCompare([]byte(token), adminToken) != 1 {
+			httphelper.ProblemResponse(c, httphelper.ErrUnauthorized)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func (s *Server) getLogLevel(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"level": s.logger.Level()})
+}
+
+func (s *Server) setLogLevel(c *gin.Context) {
+	var request struct {
+		Level string `json:"level" validate:"required"`
+	}
+	if err := httphelper.ReadRequest(c, &request); err != nil {
+		httphelper.ErrResponseWithLog(c, s.logger, err)
+		return
+	}
+
+	if err := s.logger.SetLevel(strings.ToLower(request.Level)); err != nil {
+		httphelper.ErrResponseWithLog(c, s.logger, httphelper.NewRestErrorWithCode(
+			http.StatusBadRequest, httphelper.CodeInvalidInput, err.Error(), err))
+		return
+	}
+
+	s.logger.Infof("Logger level changed to %s", s.logger.Level())
+	c.JSON(http.StatusOK, gin.H{"level": s.logger.Level()})
+}
+
+// getConfig dumps the effective config with the secrets redacted
+func (s *Server) getConfig(c *gin.Context) {
+	configBytes, err := json.Marshal(s.cfg)
+	if err != nil {
+		httphelper.ErrResponseWithLog(c, s.logger, err)
+		return
+	}
+
+	var config map[string]interface{}
+	if err = json.Unmarshal(configBytes, &config); err != nil {
+		httphelper.ErrResponseWithLog(c, s.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, redactConfig(config))
+}
+
+func (s *Server) flushCache(cacheInvalidator *cache.Invalidator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		prefix := c.Param("prefix")
+
+		found := false
+		for _, cachePrefix := range cacheInvalidator.Prefixes() {
+			if cachePrefix == prefix {
+				found = true
+				break
+			}
+		}
+		if !found {
+			httphelper.ErrResponseWithLog(c, s.logger, httphelper.NewNotFoundError(prefix))
+			return
+		}
+
+		deleted, err := cacheInvalidator.Flush(c, prefix)
+		if err != nil {
+			httphelper.ErrResponseWithLog(c, s.logger, err)
+			return
+		}
+
+		s.logger.Infof("Cache %s flushed, deleted keys: %d", prefix, deleted)
+		c.JSON(http.StatusOK, gin.H{"cache": prefix, "deleted": deleted})
+	}
+}
+
+// redactConfig replaces the values of the sensitive keys of the config
+func redactConfig(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if isSensitiveConfigKey(key) {
+				if str, ok := item.(string); ok && str != "" {
+					v[key] = redacted
+				}
+				continue
+			}
+			v[key] = redactConfig(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactConfig(item)
+		}
+	}
+	return value
+}
+
+func isSensitiveConfigKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveConfigKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
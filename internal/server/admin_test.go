@@ -0,0 +1,114 @@
+package server
+
+import (
+	"companies-service/config"
+	"companies-service/pkg/cache"
+	"companies-service/pkg/metric"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newAdminTestServer returns the admin server handler of a server with the admin token
+func newAdminTestServer(t *testing.T, adminToken string) http.Handler {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = redisClient.Close() })
+
+	cfg := &config.Config{
+		Server:   config.ServerConfig{AdminToken: adminToken, JwtSecretKey: "secretkey"},
+		Postgres: config.PostgresConfig{PostgresqlPassword: "postgres"},
+		Logger:   config.Logger{Level: "error", Encoding: "console"},
+	}
+	s := NewServer(cfg, nil, redisClient, metric.NewRegistry(), newTestLogger())
+	invalidator := cache.NewInvalidator(redisClient, "invalidations", s.logger)
+	return s.newAdminServer(invalidator).Handler
+}
+
+func performAdminRequest(handler http.Handler, path, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestServer_AdminServer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("With admin token", func(t *testing.T) {
+		t.Parallel()
+
+		handler := newAdminTestServer(t, "admintoken")
+
+		for _, path := range []string{"/metrics", "/debug/pprof/", "/admin/log-level"} {
+			assert.Equal(t, http.StatusUnauthorized,
+				performAdminRequest(handler, path, "").Code, path)
+			assert.Equal(t, http.StatusUnauthorized,
+				performAdminRequest(handler, path, "wrong").Code, path)
+			assert.Equal(t, http.StatusOK,
+				performAdminRequest(handler, path, "admintoken").Code, path)
+		}
+
+		w := performAdminRequest(handler, "/admin/config", "admintoken")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "admintoken")
+		assert.NotContains(t, w.Body.String(), "secretkey")
+	})
+
+	t.Run("Without admin token", func(t *testing.T) {
+		t.Parallel()
+
+		handler := newAdminTestServer(t, "")
+
+		assert.Equal(t, http.StatusOK, performAdminRequest(handler, "/metrics", "").Code)
+		assert.Equal(t, http.StatusNotFound,
+			performAdminRequest(handler, "/debug/pprof/", "").Code)
+		assert.Equal(t, http.StatusNotFound,
+			performAdminRequest(handler, "/admin/config", "").Code)
+	})
+}
+
+func TestRedactConfig(t *testing.T) {
+	t.Parallel()
+
+	var cfg map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"Server": {"Port": ":8080", "JwtSecretKey": "secretkey", "AdminToken": "admintoken"},
+		"Postgres": {"PostgresqlUser": "postgres", "PostgresqlPassword": "postgres"},
+		"Redis": {"Password": ""},
+		"Registries": [{"URL": "http://registry", "Password": "registry"}],
+		"Tokens": {"Count": 2}
+	}`), &cfg))
+
+	redactConfig(cfg)
+
+	server := cfg["Server"].(map[string]interface{})
+	assert.Equal(t, ":8080", server["Port"])
+	assert.Equal(t, redacted, server["JwtSecretKey"])
+	assert.Equal(t, redacted, server["AdminToken"])
+
+	postgres := cfg["Postgres"].(map[string]interface{})
+	assert.Equal(t, "postgres", postgres["PostgresqlUser"])
+	assert.Equal(t, redacted, postgres["PostgresqlPassword"])
+
+	// The empty secrets show that they are not configured
+	assert.Equal(t, "", cfg["Redis"].(map[string]interface{})["Password"])
+
+	registry := cfg["Registries"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "http://registry", registry["URL"])
+	assert.Equal(t, redacted, registry["Password"])
+
+	// Only the string values of the sensitive keys are redacted
+	assert.Equal(t, map[string]interface{}{"Count": float64(2)}, cfg["Tokens"])
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	companiesKafka "companies-service/internal/companies/delivery/kafka"
+	"companies-service/pkg/events"
+	kafkaClient "companies-service/pkg/kafka"
+	"companies-service/pkg/metric"
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// runConsumer starts the consumer of the company events, the returned function stops it and
+// waits for the messages being handled. The consumer is disabled without a group id, the
+// failed messages are published to the dead letter topic with the producer
+func (s *Server) runConsumer(producer kafkaClient.Producer) (func(), error) {
+	cfg := s.cfg.Kafka.Consumer
+	if cfg.GroupID == "" {
+		s.logger.Info("Kafka consumer is disabled, no group id configured")
+		return func() {}, nil
+	}
+	if cfg.DeadLetterTopic.TopicName == "" {
+		return nil, errors.New("kafka consumer requires a dead letter topic")
+	}
+
+	decoder, err := s.newEventDecoder()
+	if err != nil {
+		return nil, errors.Wrap(err, "s.newEventDecoder")
+	}
+
+	consumerMetrics, err := metric.CreateConsumerMetrics(s.registry, s.cfg.Metrics.ServiceName)
+	if err != nil {
+		s.logger.Errorf("CreateConsumerMetrics Error: %s", err)
+	}
+
+	group := kafkaClient.NewConsumerGroup(s.cfg.Kafka.Brokers, cfg.GroupID, s.logger)
+	consumer := kafkaClient.NewConsumer(group, producer, cfg.DeadLetterTopic.TopicName,
+		s.logger)
+	consumer.Use(
+		kafkaClient.TracingMiddleware(),
+		kafkaClient.LoggingMiddleware(s.logger),
+		kafkaClient.MetricsMiddleware(consumerMetrics),
+		kafkaClient.RecoveryMiddleware(s.logger),
+	)
+
+	companiesConsumerHandlers := companiesKafka.NewCompaniesConsumerHandlers(s.cfg, s.logger)
+	companiesKafka.MapCompaniesConsumers(consumer, companiesConsumerHandlers, decoder, s.cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		consumer.Run(ctx)
+	}()
+
+	return func() {
+		cancel()
+		wg.Wait()
+	}, nil
+}
+
+// newEventDecoder returns the decoder of the company events, the protobuf and avro events
+// are decoded with the schemas of the registry
+func (s *Server) newEventDecoder() (*events.Decoder, error) {
+	mode := s.cfg.Events.Mode
+	if mode != events.ModeProtobuf && mode != events.ModeAvro {
+		return events.NewDecoder(nil), nil
+	}
+
+	registry, err := s.newSchemaRegistry()
+	if err != nil {
+		return nil, err
+	}
+	return events.NewDecoder(registry), nil
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"companies-service/pkg/events"
+	"companies-service/pkg/schemaregistry"
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Schema registry types
+const (
+	schemaRegistryConfluent = "confluent"
+	schemaRegistryFile      = "file"
+)
+
+// newEventEncoder returns the encoder of the configured events mode, the protobuf and avro
+// schemas are checked against the latest schemas of the company topics so that an
+// incompatible schema fails the startup
+func (s *Server) newEventEncoder() (*events.Encoder, error) {
+	mode := s.cfg.Events.Mode
+	if mode != events.ModeProtobuf && mode != events.ModeAvro {
+		return events.NewEncoder(mode, s.cfg.Events.Source, s.cfg.Events.TypePrefix)
+	}
+
+	registry, err := s.newSchemaRegistry()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(),
+		time.Duration(s.cfg.Events.SchemaRegistry.Timeout)*time.Second)
+	defer cancel()
+
+	topics := []string{
+		s.cfg.KafkaTopics.CompanyCreated.TopicName,
+		s.cfg.KafkaTopics.CompanyUpdated.TopicName,
+		s.cfg.KafkaTopics.CompanyDeleted.TopicName,
+	}
+	return events.NewSchemaEncoder(ctx, mode, registry, topics)
+}
+
+func (s *Server) newSchemaRegistry() (schemaregistry.SchemaRegistry, error) {
+	cfg := s.cfg.Events.SchemaRegistry
+
+	switch cfg.Type {
+	case schemaRegistryConfluent:
+		return schemaregistry.NewConfluentRegistry(cfg.URL, cfg.Username, cfg.Password,
+			time.Duration(cfg.Timeout)*time.Second), nil
+	case schemaRegistryFile:
+		return schemaregistry.NewFileRegistry(cfg.FilePath)
+	default:
+		return nil, errors.Errorf("unknown schema registry type %q", cfg.Type)
+	}
+}
@@ -9,70 +9,100 @@ import (
 	companiesRepository "companies-service/internal/companies/repository"
 	companiesService "companies-service/internal/companies/service"
 	"companies-service/internal/middleware"
+	"companies-service/pkg/cache"
 	"companies-service/pkg/kafka"
 	"companies-service/pkg/metric"
-	"time"
+	"companies-service/pkg/ratelimit"
 
-	"github.com/gin-contrib/cors"
 	"github.com/gin-contrib/requestid"
 	limits "github.com/gin-contrib/size"
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
 
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
 // Map Server Handlers
-func (s *Server) MapHandlers(kafkaProducer kafka.Producer) error {
-	metrics, err := metric.CreateMetrics(s.cfg.Metrics.URL, s.cfg.Metrics.ServiceName)
+func (s *Server) MapHandlers(
+	kafkaProducer kafka.Producer,
+	cacheInvalidator *cache.Invalidator,
+	businessMetrics metric.BusinessMetrics,
+) error {
+	metrics, err := metric.CreateMetrics(s.registry, s.cfg.Metrics.ServiceName)
 	if err != nil {
 		s.logger.Errorf("CreateMetrics Error: %s", err)
 	}
-	s.logger.Info(
-		"Metrics available URL: %s, ServiceName: %s",
-		s.cfg.Metrics.URL,
-		s.cfg.Metrics.ServiceName,
-	)
+	s.logger.Infof("Metrics available on the admin server, ServiceName: %s",
+		s.cfg.Metrics.ServiceName)
+
+	cacheMetrics, err := metric.CreateCacheMetrics(s.registry, s.cfg.Metrics.ServiceName)
+	if err != nil {
+		s.logger.Errorf("CreateCacheMetrics Error: %s", err)
+	}
 
 	// Init repositories
 	authRepo := authRepository.NewAuthRepository(s.db)
 	companiesRepo := companiesRepository.NewCompaniesRepository(s.db)
-	authRedisRepo := authRepository.NewAuthRedisRepo(s.redisClient)
+	authRedisRepo := authRepository.NewAuthRedisRepo(s.redisClient, s.cfg, cacheMetrics,
+		cacheInvalidator, s.logger)
+	companiesRedisRepo := companiesRepository.NewCompaniesRedisRepo(s.redisClient, s.cfg,
+		cacheMetrics, cacheInvalidator, s.logger)
 
 	// Init useCases
-	authSrv := authService.NewAuthService(s.cfg, authRepo, authRedisRepo, s.logger)
-	companiesSrv := companiesService.NewCompaniesService(s.cfg, companiesRepo, s.logger)
+	authSrv := authService.NewAuthService(s.cfg, authRepo, authRedisRepo, businessMetrics,
+		s.logger)
+	companiesSrv := companiesService.NewCompaniesService(s.cfg, companiesRepo, companiesRedisRepo,
+		businessMetrics, s.logger)
+
+	eventEncoder, err := s.newEventEncoder()
+	if err != nil {
+		return errors.Wrap(err, "s.newEventEncoder")
+	}
+	s.logger.Infof("Company events published in %s mode", eventEncoder.Mode())
 
 	// Init handlers
 	authHandler := authHttp.NewAuthHandlers(s.cfg, authSrv, s.logger)
 	companiesHandler := companiesHttp.NewCompaniesHandlers(s.cfg, companiesSrv, kafkaProducer,
-		s.logger)
+		eventEncoder, s.logger)
 
 	docs.SwaggerInfo.Title = "Company Service REST API"
 	s.gin.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
 
-	s.gin.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"POST, OPTIONS, GET, PUT, PATCH, DELETE"},
-		AllowHeaders:     []string{"Origin"},
-		ExposeHeaders:    []string{"Content-Length", "Content-Type"},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
-	}))
+	rateLimiter := ratelimit.NewRedisLimiter(s.redisClient)
+	mw := middleware.NewMiddlewareManager(authSrv, s.cfg, s.cfg.CORS.AllowOrigins, rateLimiter,
+		s.logger)
+
+	corsMiddleware, err := mw.CORSMiddleware()
+	if err != nil {
+		return err
+	}
+	// The access log wraps every other middleware, the rejected preflights are logged too
+	s.gin.Use(mw.AccessLogMiddleware())
+	s.gin.Use(corsMiddleware)
+	// The swagger ui is mapped before, it is not restricted by the security headers
+	s.gin.Use(mw.SecurityHeadersMiddleware())
+	s.gin.Use(mw.CompressionMiddleware())
 
 	// Global middleware
-	// Logger middleware will write the logs to gin.DefaultWriter
-	// even if you set with GIN_MODE=release.
-	// By default gin.DefaultWriter = os.Stdout
-	s.gin.Use(gin.Logger())
 	// Recovery middleware recovers from any panics and writes a 500 if there was one.
 	s.gin.Use(gin.Recovery())
 
 	s.gin.Use(requestid.New())
 
-	mw := middleware.NewMiddlewareManager(authSrv, s.cfg, []string{"*"}, s.logger)
+	// Handlers read the server span of the request context through the gin context
+	s.gin.ContextWithFallback = true
+
+	s.gin.Use(mw.TracingMiddleware())
+	s.gin.Use(mw.RequestLoggerMiddleware())
 	s.gin.Use(mw.MetricsMiddleware(metrics))
 
+	healthChecker := s.newHealthChecker()
+	s.mapProbeRoutes(healthChecker)
+
+	s.gin.Use(mw.RateLimitMiddleware(metrics))
+	s.gin.Use(mw.TimeoutMiddleware())
+
 	s.gin.Use(limits.RequestSizeLimiter(1024 * 1024 * 5)) // 5MB
 	if s.cfg.Server.Debug {
 		s.gin.Use(mw.DebugMiddleware())
@@ -88,5 +118,7 @@ func (s *Server) MapHandlers(kafkaProducer kafka.Producer) error {
 	authHttp.MapAuthRoutes(authGroup, authHandler, mw, s.cfg)
 	companiesHttp.MapCommentsRoutes(companiesGroup, companiesHandler, mw, s.cfg)
 
+	s.mapHealthRoutes(healthChecker, mw)
+
 	return nil
 }
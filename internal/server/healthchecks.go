@@ -1,37 +1,114 @@
 package server
 
 import (
-	"companies-service/pkg/httphelper"
+	"companies-service/config"
+	"companies-service/internal/middleware"
+	"companies-service/pkg/health"
 	"context"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/segmentio/kafka-go"
 )
 
-func (s *Server) runHealthCheck() {
-	s.gin.GET("/live", func(c *gin.Context) {
-		s.logger.Infof("Health check RequestID: %s", httphelper.GetRequestID(c))
-		if err := s.configureHealthCheckEndpoints(c); err != nil {
-			c.JSON(http.StatusServiceUnavailable, gin.H{
-				"error": err.Error(),
-			})
+const getMigrationVersionQuery = `SELECT version, dirty FROM schema_migrations LIMIT 1`
+
+// newHealthChecker returns the checker of the dependencies of the readiness and detailed
+// health endpoints
+func (s *Server) newHealthChecker() *health.Checker {
+	return health.NewChecker(
+		config.HealthCheckTimeout(s.cfg.Health.CheckTimeout),
+		config.HealthCacheTTL(s.cfg.Health.CacheTTL),
+		health.Check{Name: "postgres", Check: s.checkPostgres},
+		health.Check{Name: "redis", Check: s.checkRedis},
+		health.Check{Name: "kafka", Check: s.checkKafka},
+		health.Check{Name: "migrations", Check: s.checkMigrations},
+	)
+}
+
+// mapProbeRoutes maps the liveness and readiness probes, they are mapped before the rate
+// limit so that the probes of the orchestrator are never rejected
+func (s *Server) mapProbeRoutes(checker *health.Checker) {
+	// Liveness only checks the process, a dependency outage must not restart it
+	live := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "OK"})
+	}
+	s.gin.GET("/livez", live)
+	// /live is kept for the probes configured before /livez
+	s.gin.GET("/live", live)
+
+	s.gin.GET("/readyz", func(c *gin.Context) {
+		if !s.ready.Load() {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "shutting down"})
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"status": "OK"})
+
+		results, healthy := checker.Check()
+		checks := make(map[string]string, len(results))
+		for _, result := range results {
+			checks[result.Name] = result.Status
+		}
+
+		if !healthy {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "checks": checks})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "OK", "checks": checks})
+	})
+}
+
+// mapHealthRoutes maps the detailed health endpoint of the admins
+func (s *Server) mapHealthRoutes(checker *health.Checker, mw *middleware.MiddlewareManager) {
+	healthGroup := s.gin.Group("/health", mw.AuthJWTMiddleware(s.cfg), mw.AdminMiddleware())
+	healthGroup.GET("/details", func(c *gin.Context) {
+		results, healthy := checker.Check()
+
+		status := http.StatusOK
+		if !healthy {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, gin.H{"ready": s.ready.Load(), "checks": results})
 	})
 }
 
-func (s *Server) configureHealthCheckEndpoints(ctx context.Context) error {
-	// Liveness check of (Postgres) DB
-	if err := s.db.Ping(); err != nil {
-		s.logger.Warnf("(DB Liveness Check) err: {%v}", err)
-		return err
+func (s *Server) checkPostgres(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+func (s *Server) checkRedis(ctx context.Context) error {
+	return s.redisClient.Ping(ctx).Err()
+}
+
+// checkKafka checks that at least one of the brokers is reachable
+func (s *Server) checkKafka(ctx context.Context) error {
+	err := errors.New("no kafka brokers configured")
+	for _, broker := range s.cfg.Kafka.Brokers {
+		var conn *kafka.Conn
+		conn, err = kafka.DialContext(ctx, "tcp", broker)
+		if err == nil {
+			return conn.Close()
+		}
+	}
+	return errors.Wrap(err, "kafka.DialContext")
+}
+
+// checkMigrations checks that the schema is migrated to the required version
+func (s *Server) checkMigrations(ctx context.Context) error {
+	var (
+		version int
+		dirty   bool
+	)
+	if err := s.db.QueryRowContext(ctx, getMigrationVersionQuery).Scan(&version, &dirty); err != nil {
+		return errors.Wrap(err, "db.QueryRowContext")
 	}
 
-	// Liveness check of Redis
-	if err := s.redisClient.Ping(ctx).Err(); err != nil {
-		s.logger.Warnf("(Redis Liveness Check) err: {%v}", err)
-		return err
+	if dirty {
+		return errors.Errorf("migration %d is dirty", version)
+	}
+	if version < s.cfg.Postgres.MigrationVersion {
+		return errors.Errorf("migration version %d, required %d",
+			version, s.cfg.Postgres.MigrationVersion)
 	}
 
 	return nil
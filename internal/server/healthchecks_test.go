@@ -0,0 +1,77 @@
+package server
+
+import (
+	"companies-service/config"
+	"companies-service/pkg/cache"
+	"companies-service/pkg/metric"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_ProbeRoutes(t *testing.T) {
+	// MapHandlers sets the global gin mode, the test does not run in parallel
+
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	mr := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = redisClient.Close() })
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{JwtSecretKey: "secretkey"},
+		RateLimit: config.RateLimit{Enabled: true,
+			Default: config.RateLimitPolicy{Requests: 1, Period: 60, Burst: 1}},
+		CORS:  config.CORS{AllowOrigins: []string{"http://localhost:3000"}},
+		Kafka: &config.Kafka{},
+	}
+	s := NewServer(cfg, sqlx.NewDb(db, "sqlmock"), redisClient, metric.NewRegistry(),
+		newTestLogger())
+	invalidator := cache.NewInvalidator(redisClient, "invalidations", s.logger)
+	require.NoError(t, s.MapHandlers(nil, invalidator, nil))
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.gin.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	// The probes are never rate limited
+	for i := 0; i < 3; i++ {
+		for _, path := range []string{"/livez", "/live"} {
+			w := get(path)
+			assert.Equal(t, http.StatusOK, w.Code, path)
+			assert.Empty(t, w.Header().Get("RateLimit-Limit"), path)
+		}
+
+		w := get("/readyz")
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+		assert.JSONEq(t, `{"status":"shutting down"}`, w.Body.String())
+	}
+
+	s.ready.Store(true)
+	w := get("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	var body struct {
+		Status string
+		Checks map[string]string
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "unavailable", body.Status)
+	assert.Contains(t, body.Checks, "kafka")
+
+	// The api routes are still rate limited
+	assert.Equal(t, "1", get("/api/v1/companies").Header().Get("RateLimit-Limit"))
+	assert.Equal(t, http.StatusTooManyRequests, get("/api/v1/companies").Code)
+}
@@ -2,19 +2,26 @@ package server
 
 import (
 	"companies-service/config"
+	"companies-service/pkg/cache"
 	kafkaClient "companies-service/pkg/kafka"
 	"companies-service/pkg/logger"
+	"companies-service/pkg/metric"
+	"companies-service/pkg/tlsconfig"
 	"context"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"strconv"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/redis/go-redis/v9"
 	"github.com/segmentio/kafka-go"
 )
@@ -31,19 +38,28 @@ type Server struct {
 	db          *sqlx.DB
 	redisClient *redis.Client
 	kafkaConn   *kafka.Conn
+	registry    *prometheus.Registry
 	logger      logger.Logger
+	// ready reports whether the server accepts traffic, it is false while shutting down
+	ready   atomic.Bool
+	workers sync.WaitGroup
 }
 
 // NewServer constructor
 func NewServer(
-	cfg *config.Config, db *sqlx.DB, redisClient *redis.Client, logger logger.Logger,
+	cfg *config.Config,
+	db *sqlx.DB,
+	redisClient *redis.Client,
+	registry *prometheus.Registry,
+	logger logger.Logger,
 ) *Server {
-	return &Server{gin: gin.New(), cfg: cfg, db: db, redisClient: redisClient, logger: logger}
+	return &Server{gin: gin.New(), cfg: cfg, db: db, redisClient: redisClient,
+		registry: registry, logger: logger}
 }
 
 func (s *Server) Run() error {
-	ctx, shutdown := context.WithTimeout(context.Background(), ctxTimeout*time.Second)
-	defer shutdown()
+	ctx, cancel := context.WithTimeout(context.Background(), ctxTimeout*time.Second)
+	defer cancel()
 
 	server := &http.Server{
 		Addr:           s.cfg.Server.Port,
@@ -53,47 +69,156 @@ func (s *Server) Run() error {
 		Handler:        s.gin,
 	}
 
-	go func() {
-		s.logger.Infof("Starting Server on PORT: %s", s.cfg.Server.Port)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			s.logger.Fatalf("Error ListenAndServe: %s", err)
+	// Until the server starts, a failure releases what was started so far in reverse order,
+	// then the shutdown releases everything
+	started := false
+	var cleanups []func()
+	defer func() {
+		if started {
+			return
+		}
+		for i := len(cleanups) - 1; i >= 0; i-- {
+			cleanups[i]()
 		}
 	}()
+	cleanups = append(cleanups, s.closeStores)
 
 	if err := s.connectKafkaBrokers(ctx); err != nil {
 		return errors.Wrap(err, "s.connectKafkaBrokers")
 	}
-	defer s.kafkaConn.Close()
+	cleanups = append(cleanups, func() {
+		if err := s.kafkaConn.Close(); err != nil {
+			s.logger.Errorf("kafkaConn.Close: %s", err)
+		}
+	})
 
 	if s.cfg.Kafka.InitTopics {
 		s.initKafkaTopics(ctx)
 	}
 
-	kafkaProducer := kafkaClient.NewProducer(s.logger, s.cfg.Kafka.Brokers)
-	defer kafkaProducer.Close() // nolint: errcheck
+	businessMetrics, err := metric.CreateBusinessMetrics(s.registry, s.cfg.Metrics.ServiceName)
+	if err != nil {
+		s.logger.Errorf("CreateBusinessMetrics Error: %s", err)
+	}
+
+	kafkaProducer := kafkaClient.NewProducer(s.logger, s.cfg.Kafka.Brokers, businessMetrics)
 	s.logger.Info("Kafka connected")
+	cleanups = append(cleanups, func() {
+		if err := kafkaProducer.Close(); err != nil {
+			s.logger.Errorf("kafkaProducer.Close: %s", err)
+		}
+	})
+
+	if err := s.registry.Register(kafkaClient.NewWriterStatsCollector(s.cfg.Metrics.ServiceName,
+		kafkaProducer.Stats)); err != nil {
+		s.logger.Errorf("Register kafka writer stats collector Error: %s", err)
+	}
+
+	// Background workers are stopped when the server shuts down
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	cleanups = append(cleanups, func() {
+		stopWorkers()
+		s.workers.Wait()
+	})
+
+	cacheInvalidator := cache.NewInvalidator(s.redisClient, s.cfg.Cache.InvalidationChannel,
+		s.logger)
+	s.runWorker(workersCtx, cacheInvalidator.Run)
 
-	if err := s.MapHandlers(kafkaProducer); err != nil {
+	if err := s.MapHandlers(kafkaProducer, cacheInvalidator, businessMetrics); err != nil {
 		return err
 	}
 
-	s.runHealthCheck()
+	adminServer := s.newAdminServer(cacheInvalidator)
+
+	if s.cfg.Server.SSL {
+		if err := s.configureTLS(workersCtx, server); err != nil {
+			return errors.Wrap(err, "s.configureTLS")
+		}
+	}
+
+	// The consumer starts last, nothing can fail once it consumes
+	stopConsumer, err := s.runConsumer(kafkaProducer)
+	if err != nil {
+		return errors.Wrap(err, "s.runConsumer")
+	}
+	started = true
+
+	serverErrors := make(chan error, 1)
+	go func() {
+		s.logger.Infof("Starting Server on PORT: %s, TLS: %v", s.cfg.Server.Port, s.cfg.Server.SSL)
+		if err := s.listenAndServe(server); err != nil && err != http.ErrServerClosed {
+			serverErrors <- err
+		}
+	}()
+	if s.cfg.Server.AdminToken == "" {
+		s.logger.Warnf("Admin token is not configured, set %s to serve pprof and the admin "+
+			"routes, the admin server serves only the metrics", config.AdminTokenEnv)
+	}
+	go func() {
+		s.logger.Infof("Starting Admin Server on PORT: %s", s.cfg.Server.PprofPort)
+		if err := adminServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			serverErrors <- err
+		}
+	}()
+	s.ready.Store(true)
 
 	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt)
-	<-quit
-	s.logger.Info("shutdown server  ...")
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(quit)
 
-	// Shut downs gracefully the server
-	if err := server.Shutdown(ctx); err != nil {
-		s.logger.Error(err)
-		return err
+	var runErr error
+	select {
+	case sig := <-quit:
+		s.logger.Infof("Received signal %s, shutting down server ...", sig)
+	case err := <-serverErrors:
+		s.logger.Errorf("Error ListenAndServe: %s", err)
+		runErr = errors.Wrap(err, "server.ListenAndServe")
+	}
+
+	if err := s.shutdown(server, adminServer, stopConsumer, kafkaProducer,
+		stopWorkers); err != nil &&
+		runErr == nil {
+		runErr = err
+	}
+
+	return runErr
+}
+
+// configureTLS sets the tls config of the server, the certificates are reloaded
+// from disk by a background worker
+func (s *Server) configureTLS(ctx context.Context, server *http.Server) error {
+	reloader, err := tlsconfig.NewReloader(s.cfg.TLS.CertFile, s.cfg.TLS.KeyFile,
+		s.cfg.TLS.ClientCAFile, s.logger)
+	if err != nil {
+		return errors.Wrap(err, "tlsconfig.NewReloader")
+	}
+
+	tlsConfig, err := tlsconfig.NewServerConfig(s.cfg.TLS, reloader)
+	if err != nil {
+		return errors.Wrap(err, "tlsconfig.NewServerConfig")
+	}
+	server.TLSConfig = tlsConfig
+
+	if s.cfg.TLS.ReloadInterval > 0 {
+		interval := time.Duration(s.cfg.TLS.ReloadInterval) * time.Second
+		s.runWorker(ctx, func(ctx context.Context) {
+			reloader.Run(ctx, interval)
+		})
 	}
 
-	s.logger.Info("shutting down gracefully")
 	return nil
 }
 
+func (s *Server) listenAndServe(server *http.Server) error {
+	if server.TLSConfig != nil {
+		// The certificates are provided by the tls config
+		return server.ListenAndServeTLS("", "")
+	}
+	return server.ListenAndServe()
+}
+
 func (s *Server) connectKafkaBrokers(ctx context.Context) error {
 	kafkaConn, err := kafkaClient.NewKafkaConn(ctx, s.cfg.Kafka)
 	if err != nil {
@@ -149,15 +274,21 @@ func (s *Server) initKafkaTopics(ctx context.Context) {
 		ReplicationFactor: s.cfg.KafkaTopics.CompanyDeleted.ReplicationFactor,
 	}
 
-	if err := conn.CreateTopics(
-		companyCreatedTopic,
-		companyUpdatedTopic,
-		companyDeletedTopic,
-	); err != nil {
+	topics := []kafka.TopicConfig{companyCreatedTopic, companyUpdatedTopic, companyDeletedTopic}
+
+	consumerCfg := s.cfg.Kafka.Consumer
+	if consumerCfg.GroupID != "" && consumerCfg.DeadLetterTopic.TopicName != "" {
+		topics = append(topics, kafka.TopicConfig{
+			Topic:             consumerCfg.DeadLetterTopic.TopicName,
+			NumPartitions:     consumerCfg.DeadLetterTopic.Partitions,
+			ReplicationFactor: consumerCfg.DeadLetterTopic.ReplicationFactor,
+		})
+	}
+
+	if err := conn.CreateTopics(topics...); err != nil {
 		s.logger.Warn("kafkaConn.CreateTopics", err)
 		return
 	}
 
-	s.logger.Infof("kafka topics created or already exists: %+v",
-		[]kafka.TopicConfig{companyCreatedTopic, companyUpdatedTopic, companyDeletedTopic})
+	s.logger.Infof("kafka topics created or already exists: %+v", topics)
 }
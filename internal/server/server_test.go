@@ -0,0 +1,46 @@
+package server
+
+import (
+	"companies-service/config"
+	"companies-service/pkg/logger"
+	"companies-service/pkg/metric"
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLogger() logger.Logger {
+	apiLogger := logger.NewApiLogger(&config.Config{
+		Logger: config.Logger{Level: "error", Encoding: "console"},
+	})
+	apiLogger.InitLogger()
+	return apiLogger
+}
+
+func TestServer_RunReleasesStoresOnStartFailure(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	mock.ExpectClose()
+
+	mr := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	cfg := &config.Config{Kafka: &config.Kafka{Brokers: []string{"127.0.0.1:1"}}}
+	s := NewServer(cfg, sqlx.NewDb(db, "sqlmock"), redisClient, metric.NewRegistry(),
+		newTestLogger())
+
+	err = s.Run()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "s.connectKafkaBrokers")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.ErrorIs(t, redisClient.Ping(context.Background()).Err(), redis.ErrClosed)
+}
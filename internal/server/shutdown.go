@@ -0,0 +1,120 @@
+package server
+
+import (
+	"companies-service/config"
+	"companies-service/pkg/kafka"
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// shutdown stops the server in order: it fails the readiness, drains the traffic,
+// shuts down http, stops the kafka consumer, flushes the kafka producer, stops the
+// background workers and closes the stores. Every step runs with its own timeout, the
+// default one when it is not configured.
+func (s *Server) shutdown(
+	server, adminServer *http.Server,
+	stopConsumer func(),
+	kafkaProducer kafka.Producer,
+	stopWorkers context.CancelFunc,
+) error {
+	s.ready.Store(false)
+
+	drainPeriod := time.Duration(s.cfg.Shutdown.DrainPeriod) * time.Second
+	s.logger.Infof("Readiness is failing, draining traffic for %s", drainPeriod)
+	time.Sleep(drainPeriod)
+
+	httpTimeout := config.ShutdownTimeout(s.cfg.Shutdown.HTTPTimeout)
+	kafkaTimeout := config.ShutdownTimeout(s.cfg.Shutdown.KafkaTimeout)
+	workersTimeout := config.ShutdownTimeout(s.cfg.Shutdown.WorkersTimeout)
+	storesTimeout := config.ShutdownTimeout(s.cfg.Shutdown.StoresTimeout)
+
+	var shutdownErr error
+	steps := []struct {
+		name    string
+		timeout time.Duration
+		run     func(ctx context.Context) error
+	}{
+		{"http server", httpTimeout, server.Shutdown},
+		{"admin server", httpTimeout, adminServer.Shutdown},
+		{"kafka consumer", kafkaTimeout, func(ctx context.Context) error {
+			stopConsumer()
+			return nil
+		}},
+		{"kafka producer", kafkaTimeout, func(ctx context.Context) error {
+			return kafkaProducer.Close()
+		}},
+		{"background workers", workersTimeout, func(ctx context.Context) error {
+			stopWorkers()
+			s.workers.Wait()
+			return nil
+		}},
+		{"kafka connection", kafkaTimeout, func(ctx context.Context) error {
+			return s.kafkaConn.Close()
+		}},
+		{"redis", storesTimeout, func(ctx context.Context) error {
+			return s.redisClient.Close()
+		}},
+		{"postgres", storesTimeout, func(ctx context.Context) error {
+			return s.db.Close()
+		}},
+	}
+
+	for _, step := range steps {
+		if err := s.shutdownStep(step.name, step.timeout, step.run); err != nil && shutdownErr == nil {
+			shutdownErr = err
+		}
+	}
+
+	s.logger.Info("Server shut down gracefully")
+	return shutdownErr
+}
+
+// shutdownStep runs a shutdown step, it gives up on the step when its timeout expires
+func (s *Server) shutdownStep(
+	name string, timeout time.Duration, run func(ctx context.Context) error,
+) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- run(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			s.logger.Errorf("Shutdown %s failed after %s: %s", name, time.Since(start), err)
+			return errors.Wrapf(err, "shutdown %s", name)
+		}
+		s.logger.Infof("Shutdown %s completed in %s", name, time.Since(start))
+		return nil
+	case <-ctx.Done():
+		s.logger.Errorf("Shutdown %s timed out after %s", name, time.Since(start))
+		return errors.Wrapf(ctx.Err(), "shutdown %s", name)
+	}
+}
+
+// closeStores closes the redis and postgres clients when the server fails to start
+func (s *Server) closeStores() {
+	if err := s.redisClient.Close(); err != nil {
+		s.logger.Errorf("redisClient.Close: %s", err)
+	}
+	if err := s.db.Close(); err != nil {
+		s.logger.Errorf("db.Close: %s", err)
+	}
+}
+
+// runWorker runs a background worker until the workers context is done,
+// the shutdown waits for all the workers to return
+func (s *Server) runWorker(ctx context.Context, worker func(ctx context.Context)) {
+	s.workers.Add(1)
+	go func() {
+		defer s.workers.Done()
+		worker(ctx)
+	}()
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"companies-service/config"
+	"companies-service/pkg/metric"
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProducer records its closing in the shutdown steps
+type fakeProducer struct {
+	close func() error
+}
+
+func (p *fakeProducer) PublishMessage(context.Context, ...kafka.Message) error {
+	return nil
+}
+
+func (p *fakeProducer) Close() error {
+	return p.close()
+}
+
+func TestServer_Shutdown(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	mock.ExpectClose()
+
+	mr := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	// The timeouts are not configured, the steps run with the default timeout
+	s := NewServer(&config.Config{}, sqlx.NewDb(db, "sqlmock"), redisClient,
+		metric.NewRegistry(), newTestLogger())
+	clientConn, serverConn := net.Pipe()
+	defer serverConn.Close()
+	s.kafkaConn = kafka.NewConn(clientConn, "company_updated", 0)
+	s.ready.Store(true)
+
+	var mu sync.Mutex
+	var steps []string
+	record := func(step string) {
+		mu.Lock()
+		defer mu.Unlock()
+		steps = append(steps, step)
+	}
+
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	s.runWorker(workersCtx, func(ctx context.Context) {
+		<-ctx.Done()
+		record("worker")
+	})
+
+	err = s.shutdown(&http.Server{}, &http.Server{}, func() { record("consumer") },
+		&fakeProducer{close: func() error {
+			record("producer")
+			return nil
+		}}, stopWorkers)
+	require.NoError(t, err)
+
+	assert.False(t, s.ready.Load())
+	assert.Equal(t, []string{"consumer", "producer", "worker"}, steps)
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.ErrorIs(t, redisClient.Ping(context.Background()).Err(), redis.ErrClosed)
+}
+
+func TestServer_ShutdownStep(t *testing.T) {
+	t.Parallel()
+
+	s := &Server{logger: newTestLogger()}
+
+	t.Run("Completed", func(t *testing.T) {
+		err := s.shutdownStep("step", time.Second, func(ctx context.Context) error {
+			_, hasDeadline := ctx.Deadline()
+			assert.True(t, hasDeadline)
+			return nil
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		stepErr := errors.New("close error")
+		err := s.shutdownStep("step", time.Second, func(context.Context) error {
+			return stepErr
+		})
+		assert.ErrorIs(t, err, stepErr)
+		assert.ErrorContains(t, err, "shutdown step")
+	})
+
+	t.Run("Timed out", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+
+		start := time.Now()
+		err := s.shutdownStep("step", 20*time.Millisecond, func(context.Context) error {
+			<-release
+			return nil
+		})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+}
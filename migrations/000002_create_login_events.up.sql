@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS login_events
+(
+    login_event_id UUID PRIMARY KEY                  DEFAULT uuid_generate_v4(),
+    user_id        UUID REFERENCES users (user_id) ON DELETE CASCADE,
+    email          TEXT                     NOT NULL,
+    ip_address     TEXT                     NOT NULL DEFAULT '',
+    user_agent     TEXT                     NOT NULL DEFAULT '',
+    success        BOOLEAN                  NOT NULL,
+    failure_reason VARCHAR(64),
+    created_at     TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS login_events_user_id_created_at_idx
+    ON login_events (user_id, created_at DESC);
@@ -4,6 +4,8 @@ import (
 	"companies-service/config"
 	"companies-service/internal/models"
 	"companies-service/pkg/httphelper"
+	"crypto/rand"
+	"encoding/base64"
 	"errors"
 	"html"
 	"net/http"
@@ -13,6 +15,8 @@ import (
 	"github.com/dgrijalva/jwt-go"
 )
 
+const csrfTokenBytes = 32
+
 // JWT Claims struct
 type Claims struct {
 	Email string `json:"email"`
@@ -48,7 +52,7 @@ func ValidateJWT(tokenString string, cfg *config.Config) (map[string]interface{}
 	// Initialize a new instance of `Claims` (here using Claims map)
 	claims := jwt.MapClaims{}
 
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errors.New("invalid token signature")
 		}
@@ -66,6 +70,15 @@ func ValidateJWT(tokenString string, cfg *config.Config) (map[string]interface{}
 	return claims, nil
 }
 
+// Generate new random CSRF token
+func GenerateCSRFToken() (string, error) {
+	tokenBytes := make([]byte, csrfTokenBytes)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(tokenBytes), nil
+}
+
 // Extract bearer token from request Authorization header
 func ExtractBearerToken(r *http.Request) string {
 	headerAuthorization := r.Header.Get("Authorization")
@@ -74,6 +87,9 @@ func ExtractBearerToken(r *http.Request) string {
 	}
 
 	bearerToken := strings.Split(headerAuthorization, " ")
+	if len(bearerToken) != 2 {
+		return ""
+	}
 
 	return html.EscapeString(bearerToken[1])
 }
@@ -0,0 +1,430 @@
+package cache
+
+import (
+	"companies-service/config"
+	"companies-service/pkg/logger"
+	"companies-service/pkg/metric"
+	"companies-service/pkg/tracing"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+)
+
+// notFoundMarker is stored instead of a value for negatively cached keys.
+// A JSON encoded value can never be equal to it.
+const notFoundMarker = "\x00not-found"
+
+// tombstonePrefix starts the unique markers written by Delete, they read as misses. A load
+// caches its value only while the key still holds the marker it missed on, so a load that
+// started before a deletion never caches its stale value
+const tombstonePrefix = "\x00invalidated:"
+
+// Delete retries the tombstone writes before failing
+const (
+	deleteAttempts = 3
+	deleteBackoff  = 50 * time.Millisecond
+)
+
+// defaultLoadTimeout bounds the shared loads of the caches without a load timeout
+const defaultLoadTimeout = 10 * time.Second
+
+// compareAndSetScript sets the key to ARGV[2] with the ARGV[3] ms ttl when it still holds
+// ARGV[1], or when it is missing and ARGV[1] is empty
+var compareAndSetScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if current == false then current = '' end
+if current ~= ARGV[1] then
+	return 0
+end
+if tonumber(ARGV[3]) > 0 then
+	redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+else
+	redis.call('SET', KEYS[1], ARGV[2])
+end
+return 1
+`)
+
+// ErrMiss is returned by Get when the key is not cached
+var ErrMiss = errors.New("cache miss")
+
+// Loader loads a value from the source of truth on a cache miss
+type Loader[T any] func(ctx context.Context) (*T, error)
+
+// Cache options
+type Options struct {
+	// Prefix of every key of the cache, also used as the metrics label
+	Prefix string
+	// TTL of the cached values
+	TTL time.Duration
+	// NegativeTTL of the not found results, zero disables negative caching
+	NegativeTTL time.Duration
+	// Jitter adds a random duration up to TTL*Jitter to every expiration
+	Jitter float64
+	// NotFound is the loader error that marks a missing value
+	NotFound error
+	// LocalSize is the max entries of the in-process tier, zero disables it
+	LocalSize int
+	// LocalTTL of the in-process entries, keep it short to bound staleness
+	LocalTTL time.Duration
+	// LoadTimeout bounds the shared loads, they outlive the callers that started them.
+	// Zero defaults to defaultLoadTimeout
+	LoadTimeout time.Duration
+	// Invalidator broadcasts the deletions to the in-process tier of all instances
+	Invalidator *Invalidator
+}
+
+// Cache is a typed read-through cache over redis
+type Cache[T any] struct {
+	redisClient *redis.Client
+	local       *localCache
+	opts        Options
+	group       singleflight.Group
+	metrics     metric.CacheMetrics
+	logger      logger.Logger
+}
+
+// Cache constructor
+func New[T any](
+	redisClient *redis.Client, opts Options, metrics metric.CacheMetrics, logger logger.Logger,
+) *Cache[T] {
+	c := &Cache[T]{redisClient: redisClient, opts: opts, metrics: metrics, logger: logger}
+	if opts.LocalSize > 0 && opts.LocalTTL > 0 {
+		c.local = newLocalCache(opts.LocalSize, opts.LocalTTL)
+	}
+	if opts.Invalidator != nil {
+		opts.Invalidator.register(opts.Prefix, c.local)
+	}
+	return c
+}
+
+// Key returns the redis key of the given id
+func (c *Cache[T]) Key(id string) string {
+	return fmt.Sprintf("%s:%s", c.opts.Prefix, id)
+}
+
+// Get returns the cached value, ErrMiss when it is not cached
+// or the NotFound error when it is negatively cached
+func (c *Cache[T]) Get(ctx context.Context, id string) (*T, error) {
+	ctx, span := tracing.StartSpan(ctx, "cache.Get")
+	defer span.End()
+
+	valueBytes, err := c.getBytes(ctx, c.Key(id), c.localGeneration())
+	if err != nil {
+		return nil, err
+	}
+
+	return c.decode(valueBytes)
+}
+
+// Set caches the value
+func (c *Cache[T]) Set(ctx context.Context, id string, value *T) error {
+	ctx, span := tracing.StartSpan(ctx, "cache.Set")
+	defer span.End()
+
+	valueBytes, err := json.Marshal(value)
+	if err != nil {
+		return errors.Wrap(err, "cache.Set.json.Marshal")
+	}
+
+	generation := c.localGeneration()
+	if err = c.redisClient.Set(ctx, c.Key(id), valueBytes,
+		c.withJitter(c.opts.TTL)).Err(); err != nil {
+		return errors.Wrap(err, "cache.Set.redisClient.Set")
+	}
+	c.setLocal(c.Key(id), valueBytes, generation)
+
+	return nil
+}
+
+// SetNotFound caches a not found result
+func (c *Cache[T]) SetNotFound(ctx context.Context, id string) error {
+	ctx, span := tracing.StartSpan(ctx, "cache.SetNotFound")
+	defer span.End()
+
+	if c.opts.NegativeTTL <= 0 {
+		return nil
+	}
+
+	generation := c.localGeneration()
+	if err := c.redisClient.Set(ctx, c.Key(id), notFoundMarker,
+		c.withJitter(c.opts.NegativeTTL)).Err(); err != nil {
+		return errors.Wrap(err, "cache.SetNotFound.redisClient.Set")
+	}
+	c.setLocal(c.Key(id), []byte(notFoundMarker), generation)
+
+	return nil
+}
+
+// Delete replaces the cached value with a tombstone, the loads started before it do not
+// cache their value. The tombstone write is retried, an error means that the value may
+// still be cached
+func (c *Cache[T]) Delete(ctx context.Context, id string) error {
+	ctx, span := tracing.StartSpan(ctx, "cache.Delete")
+	defer span.End()
+
+	// Readers after the deletion must not join a load started before it
+	c.group.Forget(c.Key(id))
+
+	if c.local != nil {
+		c.local.delete(c.Key(id))
+	}
+
+	if err := c.writeTombstone(ctx, c.Key(id)); err != nil {
+		return errors.Wrap(err, "cache.Delete.writeTombstone")
+	}
+
+	if c.local != nil && c.opts.Invalidator != nil {
+		if err := c.opts.Invalidator.Publish(ctx, c.Key(id)); err != nil {
+			return errors.Wrap(err, "cache.Delete.Invalidator.Publish")
+		}
+	}
+
+	return nil
+}
+
+// GetOrLoad returns the cached value or loads and caches it on a miss.
+// Concurrent misses of the same key share a single load.
+func (c *Cache[T]) GetOrLoad(ctx context.Context, id string, load Loader[T]) (*T, error) {
+	ctx, span := tracing.StartSpan(ctx, "cache.GetOrLoad")
+	defer span.End()
+
+	key := c.Key(id)
+	generation := c.localGeneration()
+
+	// The marker the key holds on a miss, the loaded value is cached only while it is there
+	var missed string
+	cacheable := true
+
+	valueBytes, err := c.getBytes(ctx, key, generation)
+	switch {
+	case err == nil && isTombstone(valueBytes):
+		c.incMisses()
+		missed = string(valueBytes)
+	case err == nil:
+		value, err := c.decode(valueBytes)
+		switch {
+		case err == nil:
+			c.incHits()
+			return value, nil
+		case c.isNotFound(err):
+			c.incNegativeHits()
+			return nil, err
+		}
+		c.incErrors()
+		logger.FromContext(ctx, c.logger).Errorf("cache.GetOrLoad.decode: %v", err)
+		cacheable = false
+	case errors.Is(err, ErrMiss):
+		c.incMisses()
+	default:
+		c.incErrors()
+		logger.FromContext(ctx, c.logger).Errorf("cache.GetOrLoad.getBytes: %v", err)
+		cacheable = false
+	}
+
+	// The load is shared, a caller that gives up must not fail the others
+	loads := c.group.DoChan(key, func() (interface{}, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.loadTimeout())
+		defer cancel()
+
+		value, err := load(ctx)
+		if err != nil {
+			if c.isNotFound(err) && cacheable && c.opts.NegativeTTL > 0 {
+				if err := c.compareAndSet(ctx, key, missed, []byte(notFoundMarker),
+					c.opts.NegativeTTL, generation); err != nil {
+					c.incErrors()
+					logger.FromContext(ctx, c.logger).Errorf("cache.GetOrLoad.SetNotFound: %v", err)
+				}
+			}
+			return nil, err
+		}
+
+		if cacheable {
+			valueBytes, err := json.Marshal(value)
+			if err == nil {
+				err = c.compareAndSet(ctx, key, missed, valueBytes, c.opts.TTL, generation)
+			}
+			if err != nil {
+				c.incErrors()
+				logger.FromContext(ctx, c.logger).Errorf("cache.GetOrLoad.Set: %v", err)
+			}
+		}
+
+		return value, nil
+	})
+
+	var loaded singleflight.Result
+	select {
+	case <-ctx.Done():
+		return nil, errors.Wrap(ctx.Err(), "cache.GetOrLoad")
+	case loaded = <-loads:
+	}
+	if loaded.Err != nil {
+		return nil, loaded.Err
+	}
+
+	// Callers sharing a load get their own copy of the value
+	result := *loaded.Val.(*T)
+	return &result, nil
+}
+
+func (c *Cache[T]) loadTimeout() time.Duration {
+	if c.opts.LoadTimeout <= 0 {
+		return defaultLoadTimeout
+	}
+	return c.opts.LoadTimeout
+}
+
+// decode returns the value of the raw cached bytes, the tombstones are misses
+func (c *Cache[T]) decode(valueBytes []byte) (*T, error) {
+	if isTombstone(valueBytes) {
+		return nil, ErrMiss
+	}
+	if string(valueBytes) == notFoundMarker {
+		return nil, errors.Wrap(c.opts.NotFound, "cache.Get.notFound")
+	}
+
+	value := new(T)
+	if err := json.Unmarshal(valueBytes, value); err != nil {
+		return nil, errors.Wrap(err, "cache.Get.json.Unmarshal")
+	}
+
+	return value, nil
+}
+
+// compareAndSet caches the loaded bytes when the key still holds the missed marker
+func (c *Cache[T]) compareAndSet(
+	ctx context.Context, key, missed string, valueBytes []byte, ttl time.Duration,
+	generation uint64,
+) error {
+	set, err := compareAndSetScript.Run(ctx, c.redisClient, []string{key}, missed, valueBytes,
+		c.withJitter(ttl).Milliseconds()).Int()
+	if err != nil {
+		return errors.Wrap(err, "cache.compareAndSet.Run")
+	}
+	if set == 1 {
+		c.setLocal(key, valueBytes, generation)
+	}
+	return nil
+}
+
+// writeTombstone replaces the key with a new tombstone, it retries the failed writes
+func (c *Cache[T]) writeTombstone(ctx context.Context, key string) error {
+	// The tombstone outlives the loads in flight, it expires with the values
+	tombstone := tombstonePrefix + uuid.NewString()
+
+	var err error
+	for attempt := 1; attempt <= deleteAttempts; attempt++ {
+		if err = c.redisClient.Set(ctx, key, tombstone, c.opts.TTL).Err(); err == nil {
+			return nil
+		}
+		if attempt == deleteAttempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(time.Duration(attempt) * deleteBackoff):
+		}
+	}
+	return err
+}
+
+// getBytes reads the raw value from the in-process tier first, then from redis. The value
+// is cached in the in-process tier when no deletion happened since the generation was read
+func (c *Cache[T]) getBytes(ctx context.Context, key string, generation uint64) ([]byte, error) {
+	if c.local != nil {
+		if valueBytes, ok := c.local.get(key); ok {
+			return valueBytes, nil
+		}
+	}
+
+	valueBytes, err := c.redisClient.Get(ctx, key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrMiss
+		}
+		return nil, errors.Wrap(err, "cache.Get.redisClient.Get")
+	}
+	if !isTombstone(valueBytes) {
+		c.setLocal(key, valueBytes, generation)
+	}
+
+	return valueBytes, nil
+}
+
+func (c *Cache[T]) localGeneration() uint64 {
+	if c.local == nil {
+		return 0
+	}
+	return c.local.currentGeneration()
+}
+
+func (c *Cache[T]) setLocal(key string, valueBytes []byte, generation uint64) {
+	if c.local != nil {
+		c.local.set(key, valueBytes, generation)
+	}
+}
+
+func isTombstone(valueBytes []byte) bool {
+	return strings.HasPrefix(string(valueBytes), tombstonePrefix)
+}
+
+func (c *Cache[T]) isNotFound(err error) bool {
+	return c.opts.NotFound != nil && errors.Is(err, c.opts.NotFound)
+}
+
+func (c *Cache[T]) withJitter(ttl time.Duration) time.Duration {
+	if c.opts.Jitter <= 0 || ttl <= 0 {
+		return ttl
+	}
+	return ttl + time.Duration(rand.Int63n(int64(float64(ttl)*c.opts.Jitter)+1))
+}
+
+func (c *Cache[T]) incHits() {
+	if c.metrics != nil {
+		c.metrics.IncCacheHits(c.opts.Prefix)
+	}
+}
+
+func (c *Cache[T]) incMisses() {
+	if c.metrics != nil {
+		c.metrics.IncCacheMisses(c.opts.Prefix)
+	}
+}
+
+func (c *Cache[T]) incNegativeHits() {
+	if c.metrics != nil {
+		c.metrics.IncCacheNegativeHits(c.opts.Prefix)
+	}
+}
+
+func (c *Cache[T]) incErrors() {
+	if c.metrics != nil {
+		c.metrics.IncCacheErrors(c.opts.Prefix)
+	}
+}
+
+// OptionsFromConfig builds the cache options from the cache config
+func OptionsFromConfig(
+	prefix string, cfg config.CacheConfig, notFound error, invalidator *Invalidator,
+) Options {
+	return Options{
+		Prefix:      prefix,
+		TTL:         time.Duration(cfg.TTL) * time.Second,
+		NegativeTTL: time.Duration(cfg.NegativeTTL) * time.Second,
+		Jitter:      float64(cfg.JitterPercent) / 100,
+		NotFound:    notFound,
+		LocalSize:   cfg.LocalSize,
+		LocalTTL:    time.Duration(cfg.LocalTTL) * time.Second,
+		Invalidator: invalidator,
+	}
+}
@@ -0,0 +1,288 @@
+package cache
+
+import (
+	"companies-service/config"
+	"companies-service/pkg/logger"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errNotFound = errors.New("not found")
+
+type company struct {
+	Name string `json:"name"`
+}
+
+func newTestCache(t *testing.T, opts Options) (*Cache[company], *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = redisClient.Close() })
+
+	apiLogger := logger.NewApiLogger(&config.Config{
+		Logger: config.Logger{Level: "fatal", Encoding: "console"},
+	})
+	apiLogger.InitLogger()
+
+	opts.Prefix = "companies"
+	opts.NotFound = errNotFound
+	return New[company](redisClient, opts, nil, apiLogger), mr
+}
+
+func TestCache_GetOrLoad(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("Cached", func(t *testing.T) {
+		t.Parallel()
+
+		c, mr := newTestCache(t, Options{TTL: time.Minute})
+
+		var loads atomic.Int32
+		load := func(context.Context) (*company, error) {
+			loads.Add(1)
+			return &company{Name: "Apple"}, nil
+		}
+
+		for i := 0; i < 2; i++ {
+			value, err := c.GetOrLoad(ctx, "1", load)
+			require.NoError(t, err)
+			assert.Equal(t, "Apple", value.Name)
+		}
+		assert.Equal(t, int32(1), loads.Load())
+		assert.Equal(t, time.Minute, mr.TTL(c.Key("1")))
+	})
+
+	t.Run("Coalesced loads", func(t *testing.T) {
+		t.Parallel()
+
+		c, _ := newTestCache(t, Options{TTL: time.Minute})
+
+		var loads atomic.Int32
+		release := make(chan struct{})
+		load := func(context.Context) (*company, error) {
+			loads.Add(1)
+			<-release
+			return &company{Name: "Apple"}, nil
+		}
+
+		const callers = 10
+		values := make([]*company, callers)
+		var started, done sync.WaitGroup
+		for i := 0; i < callers; i++ {
+			started.Add(1)
+			done.Add(1)
+			go func(i int) {
+				defer done.Done()
+				started.Done()
+				value, err := c.GetOrLoad(ctx, "1", load)
+				assert.NoError(t, err)
+				values[i] = value
+			}(i)
+		}
+		started.Wait()
+		// The callers miss before the load is released
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		done.Wait()
+
+		assert.Equal(t, int32(1), loads.Load())
+		for _, value := range values[1:] {
+			require.NotNil(t, value)
+			assert.Equal(t, *values[0], *value)
+			// Every caller gets its own copy
+			assert.NotSame(t, values[0], value)
+		}
+	})
+
+	t.Run("Load outlives a cancelled caller", func(t *testing.T) {
+		t.Parallel()
+
+		c, _ := newTestCache(t, Options{TTL: time.Minute})
+
+		release := make(chan struct{})
+		loadErr := make(chan error, 1)
+		load := func(loadCtx context.Context) (*company, error) {
+			<-release
+			loadErr <- loadCtx.Err()
+			return &company{Name: "Apple"}, nil
+		}
+
+		firstCtx, cancel := context.WithCancel(ctx)
+		first := make(chan error, 1)
+		go func() {
+			_, err := c.GetOrLoad(firstCtx, "1", load)
+			first <- err
+		}()
+		second := make(chan *company, 1)
+		go func() {
+			value, err := c.GetOrLoad(ctx, "1", load)
+			assert.NoError(t, err)
+			second <- value
+		}()
+
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+		assert.ErrorIs(t, <-first, context.Canceled)
+
+		close(release)
+		assert.Equal(t, "Apple", (<-second).Name)
+		assert.NoError(t, <-loadErr)
+	})
+
+	t.Run("Load timeout", func(t *testing.T) {
+		t.Parallel()
+
+		c, _ := newTestCache(t, Options{TTL: time.Minute, LoadTimeout: 50 * time.Millisecond})
+
+		_, err := c.GetOrLoad(ctx, "1", func(loadCtx context.Context) (*company, error) {
+			<-loadCtx.Done()
+			return nil, loadCtx.Err()
+		})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("Negative caching", func(t *testing.T) {
+		t.Parallel()
+
+		c, mr := newTestCache(t, Options{TTL: time.Minute, NegativeTTL: 10 * time.Second})
+
+		var loads atomic.Int32
+		load := func(context.Context) (*company, error) {
+			loads.Add(1)
+			return nil, errNotFound
+		}
+
+		for i := 0; i < 2; i++ {
+			_, err := c.GetOrLoad(ctx, "1", load)
+			assert.ErrorIs(t, err, errNotFound)
+		}
+		assert.Equal(t, int32(1), loads.Load())
+		assert.Equal(t, 10*time.Second, mr.TTL(c.Key("1")))
+
+		_, err := c.Get(ctx, "1")
+		assert.ErrorIs(t, err, errNotFound)
+	})
+
+	t.Run("Without negative caching", func(t *testing.T) {
+		t.Parallel()
+
+		c, mr := newTestCache(t, Options{TTL: time.Minute})
+
+		_, err := c.GetOrLoad(ctx, "1", func(context.Context) (*company, error) {
+			return nil, errNotFound
+		})
+		assert.ErrorIs(t, err, errNotFound)
+		assert.False(t, mr.Exists(c.Key("1")))
+	})
+
+	t.Run("Redis down", func(t *testing.T) {
+		t.Parallel()
+
+		c, mr := newTestCache(t, Options{TTL: time.Minute})
+		mr.Close()
+
+		// The cache is bypassed, the value is loaded
+		value, err := c.GetOrLoad(ctx, "1", func(context.Context) (*company, error) {
+			return &company{Name: "Apple"}, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Apple", value.Name)
+	})
+}
+
+func TestCache_Delete(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("Load started before the deletion", func(t *testing.T) {
+		t.Parallel()
+
+		c, mr := newTestCache(t, Options{TTL: time.Minute})
+
+		release := make(chan struct{})
+		loaded := make(chan struct{})
+		go func() {
+			defer close(loaded)
+			value, err := c.GetOrLoad(ctx, "1", func(context.Context) (*company, error) {
+				<-release
+				return &company{Name: "Stale"}, nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, "Stale", value.Name)
+		}()
+
+		time.Sleep(50 * time.Millisecond)
+		require.NoError(t, c.Delete(ctx, "1"))
+		close(release)
+		<-loaded
+
+		// The tombstone kept the stale value out of the cache
+		tombstone, err := mr.Get(c.Key("1"))
+		require.NoError(t, err)
+		assert.True(t, isTombstone([]byte(tombstone)))
+		_, err = c.Get(ctx, "1")
+		assert.ErrorIs(t, err, ErrMiss)
+
+		// A load after the deletion replaces the tombstone
+		value, err := c.GetOrLoad(ctx, "1", func(context.Context) (*company, error) {
+			return &company{Name: "Fresh"}, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Fresh", value.Name)
+
+		cached, err := c.Get(ctx, "1")
+		require.NoError(t, err)
+		assert.Equal(t, "Fresh", cached.Name)
+	})
+
+	t.Run("Compare and set", func(t *testing.T) {
+		t.Parallel()
+
+		c, mr := newTestCache(t, Options{TTL: time.Minute})
+		key := c.Key("1")
+
+		require.NoError(t, c.compareAndSet(ctx, key, "", []byte(`{"name":"First"}`),
+			time.Minute, 0))
+		// The key no longer holds the missed marker
+		require.NoError(t, c.compareAndSet(ctx, key, "", []byte(`{"name":"Second"}`),
+			time.Minute, 0))
+		value, err := mr.Get(key)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"name":"First"}`, value)
+
+		require.NoError(t, mr.Set(key, tombstonePrefix+"a"))
+		require.NoError(t, c.compareAndSet(ctx, key, tombstonePrefix+"b",
+			[]byte(`{"name":"Stale"}`), time.Minute, 0))
+		value, err = mr.Get(key)
+		require.NoError(t, err)
+		assert.Equal(t, tombstonePrefix+"a", value)
+
+		require.NoError(t, c.compareAndSet(ctx, key, tombstonePrefix+"a",
+			[]byte(`{"name":"Fresh"}`), time.Minute, 0))
+		value, err = mr.Get(key)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"name":"Fresh"}`, value)
+	})
+
+	t.Run("Redis down", func(t *testing.T) {
+		t.Parallel()
+
+		c, mr := newTestCache(t, Options{TTL: time.Minute})
+		mr.Close()
+
+		assert.Error(t, c.Delete(ctx, "1"))
+	})
+}
@@ -0,0 +1,145 @@
+package cache
+
+import (
+	"companies-service/pkg/logger"
+	"companies-service/pkg/tracing"
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+// flushBatchSize is the number of keys scanned and deleted at once by Flush
+const flushBatchSize = 100
+
+// Invalidator broadcasts cache invalidations over redis pub/sub,
+// so every running instance drops the stale entries of its in-process caches
+type Invalidator struct {
+	redisClient *redis.Client
+	channel     string
+	mu          sync.RWMutex
+	locals      []*localCache
+	prefixes    []string
+	logger      logger.Logger
+}
+
+// Invalidator constructor
+func NewInvalidator(redisClient *redis.Client, channel string, logger logger.Logger) *Invalidator {
+	return &Invalidator{redisClient: redisClient, channel: channel, logger: logger}
+}
+
+// Run listens for invalidations until the context is done
+func (i *Invalidator) Run(ctx context.Context) {
+	pubsub := i.redisClient.Subscribe(ctx, i.channel)
+	defer func() {
+		if err := pubsub.Close(); err != nil {
+			i.logger.Warnf("Invalidator.pubsub.Close: %v", err)
+		}
+	}()
+
+	i.logger.Infof("Listening for cache invalidations on channel: %s", i.channel)
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			i.evict(msg.Payload)
+		}
+	}
+}
+
+// Publish broadcasts the invalidation of a key to all instances
+func (i *Invalidator) Publish(ctx context.Context, key string) error {
+	ctx, span := tracing.StartSpan(ctx, "Invalidator.Publish")
+	defer span.End()
+
+	if err := i.redisClient.Publish(ctx, i.channel, key).Err(); err != nil {
+		return errors.Wrap(err, "Invalidator.Publish.redisClient.Publish")
+	}
+
+	return nil
+}
+
+// Prefixes returns the prefixes of the registered caches
+func (i *Invalidator) Prefixes() []string {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	prefixes := make([]string, len(i.prefixes))
+	copy(prefixes, i.prefixes)
+	return prefixes
+}
+
+// Flush deletes every key of the cache with the prefix and broadcasts the flush to all instances,
+// it returns the number of deleted keys
+func (i *Invalidator) Flush(ctx context.Context, prefix string) (int64, error) {
+	ctx, span := tracing.StartSpan(ctx, "Invalidator.Flush")
+	defer span.End()
+
+	pattern := prefix + ":*"
+
+	var deleted int64
+	iter := i.redisClient.Scan(ctx, 0, pattern, flushBatchSize).Iterator()
+	keys := make([]string, 0, flushBatchSize)
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) == flushBatchSize {
+			count, err := i.redisClient.Del(ctx, keys...).Result()
+			if err != nil {
+				return deleted, errors.Wrap(err, "Invalidator.Flush.redisClient.Del")
+			}
+			deleted += count
+			keys = keys[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return deleted, errors.Wrap(err, "Invalidator.Flush.Scan")
+	}
+	if len(keys) > 0 {
+		count, err := i.redisClient.Del(ctx, keys...).Result()
+		if err != nil {
+			return deleted, errors.Wrap(err, "Invalidator.Flush.redisClient.Del")
+		}
+		deleted += count
+	}
+
+	i.evict(pattern)
+	if err := i.Publish(ctx, pattern); err != nil {
+		return deleted, errors.Wrap(err, "Invalidator.Flush.Publish")
+	}
+
+	return deleted, nil
+}
+
+func (i *Invalidator) register(prefix string, local *localCache) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.prefixes = append(i.prefixes, prefix)
+	if local != nil {
+		i.locals = append(i.locals, local)
+	}
+}
+
+// evict drops the key from the in-process caches, a key ending with * drops all the keys
+// with the prefix before it
+func (i *Invalidator) evict(key string) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	prefix, isPattern := strings.CutSuffix(key, "*")
+	for _, local := range i.locals {
+		if isPattern {
+			local.deletePrefix(prefix)
+			continue
+		}
+		local.delete(key)
+	}
+}
@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+)
+
+// localCache is an in-process LRU cache with a size limit and entries expiration.
+// The generation counts the deletions, a value read before a deletion is not cached after it
+type localCache struct {
+	mu         sync.Mutex
+	size       int
+	ttl        time.Duration
+	items      map[string]*list.Element
+	order      *list.List
+	generation uint64
+}
+
+type localEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+func newLocalCache(size int, ttl time.Duration) *localCache {
+	return &localCache{
+		size:  size,
+		ttl:   ttl,
+		items: make(map[string]*list.Element, size),
+		order: list.New(),
+	}
+}
+
+func (l *localCache) get(key string) ([]byte, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	element, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*localEntry)
+	if time.Now().After(entry.expiresAt) {
+		l.removeElement(element)
+		return nil, false
+	}
+
+	l.order.MoveToFront(element)
+	return entry.value, true
+}
+
+// currentGeneration returns the generation to pass to set when the value is read
+func (l *localCache) currentGeneration() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.generation
+}
+
+// set caches the value unless a deletion happened since the generation was read
+func (l *localCache) set(key string, value []byte, generation uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if generation != l.generation {
+		return
+	}
+
+	expiresAt := time.Now().Add(l.ttl)
+	if element, ok := l.items[key]; ok {
+		entry := element.Value.(*localEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		l.order.MoveToFront(element)
+		return
+	}
+
+	l.items[key] = l.order.PushFront(&localEntry{key: key, value: value, expiresAt: expiresAt})
+	if l.order.Len() > l.size {
+		l.removeElement(l.order.Back())
+	}
+}
+
+func (l *localCache) delete(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.generation++
+
+	if element, ok := l.items[key]; ok {
+		l.removeElement(element)
+	}
+}
+
+func (l *localCache) deletePrefix(prefix string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.generation++
+
+	for key, element := range l.items {
+		if strings.HasPrefix(key, prefix) {
+			l.removeElement(element)
+		}
+	}
+}
+
+func (l *localCache) removeElement(element *list.Element) {
+	l.order.Remove(element)
+	delete(l.items, element.Value.(*localEntry).key)
+}
@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalCache_SetAfterDelete(t *testing.T) {
+	t.Parallel()
+
+	local := newLocalCache(10, time.Minute)
+
+	// A value read before a deletion is not cached after it
+	generation := local.currentGeneration()
+	local.delete("company:1")
+	local.set("company:1", []byte("stale"), generation)
+
+	_, ok := local.get("company:1")
+	require.False(t, ok)
+
+	local.set("company:1", []byte("fresh"), local.currentGeneration())
+	value, ok := local.get("company:1")
+	require.True(t, ok)
+	require.Equal(t, []byte("fresh"), value)
+}
+
+func TestLocalCache_Eviction(t *testing.T) {
+	t.Parallel()
+
+	local := newLocalCache(1, time.Minute)
+	local.set("company:1", []byte("1"), local.currentGeneration())
+	local.set("company:2", []byte("2"), local.currentGeneration())
+
+	_, ok := local.get("company:1")
+	require.False(t, ok)
+	_, ok = local.get("company:2")
+	require.True(t, ok)
+}
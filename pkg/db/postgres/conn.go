@@ -2,11 +2,15 @@ package postgres
 
 import (
 	"companies-service/config"
+	"companies-service/pkg/logger"
+	"companies-service/pkg/metric"
+	"database/sql"
 	"fmt"
 	"time"
 
 	_ "github.com/jackc/pgx/stdlib" // pgx driver
 	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
 )
 
 const (
@@ -16,17 +20,24 @@ const (
 	connMaxIdleTime = 20
 )
 
-// NewPsqlDB returns new Postgresql db instance
-func NewPsqlDB(c *config.Config) (*sqlx.DB, error) {
-	dataSourceName := fmt.Sprintf("host=%s port=%s user=%s dbname=%s sslmode=disable password=%s",
+// NewPsqlDB returns new Postgresql db instance, its queries are traced, measured
+// and logged when they are slower than the configured threshold
+func NewPsqlDB(c *config.Config, metrics metric.StoreMetrics, logger logger.Logger) (*sqlx.DB, error) {
+	dataSourceName := fmt.Sprintf(
+		"host=%s port=%s user=%s dbname=%s sslmode=disable password=%s statement_timeout=%d",
 		c.Postgres.PostgresqlHost,
 		c.Postgres.PostgresqlPort,
 		c.Postgres.PostgresqlUser,
 		c.Postgres.PostgresqlDbname,
 		c.Postgres.PostgresqlPassword,
+		statementTimeout(c).Milliseconds(),
 	)
 
-	db, err := sqlx.Connect(c.Postgres.PgDriver, dataSourceName)
+	db, err := openInstrumented(c.Postgres.PgDriver, dataSourceName, &instrumentation{
+		metrics:       metrics,
+		logger:        logger,
+		slowThreshold: time.Duration(c.Postgres.SlowQueryThreshold) * time.Millisecond,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -41,3 +52,31 @@ func NewPsqlDB(c *config.Config) (*sqlx.DB, error) {
 
 	return db, nil
 }
+
+// statementTimeout bounds the queries by the longest request timeout,
+// so the server stops the queries the clients are no longer waiting for
+func statementTimeout(c *config.Config) time.Duration {
+	timeout := time.Second * c.Server.CtxDefaultTimeout
+	for _, routeTimeout := range c.Server.RouteTimeouts {
+		if routeDuration := time.Duration(routeTimeout.Timeout) * time.Second; routeDuration > timeout {
+			timeout = routeDuration
+		}
+	}
+	return timeout
+}
+
+// openInstrumented opens the db with the instrumented connections of the registered driver
+func openInstrumented(driverName, dataSourceName string, instr *instrumentation) (*sqlx.DB, error) {
+	// The registered driver is looked up through a db that never connects
+	lookup, err := sql.Open(driverName, dataSourceName)
+	if err != nil {
+		return nil, errors.Wrap(err, "sql.Open")
+	}
+	drv := lookup.Driver()
+	if err = lookup.Close(); err != nil {
+		return nil, errors.Wrap(err, "sql.DB.Close")
+	}
+
+	connector := &instrumentedConnector{dsn: dataSourceName, driver: drv, instr: instr}
+	return sqlx.NewDb(sql.OpenDB(connector), driverName), nil
+}
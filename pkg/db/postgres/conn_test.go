@@ -0,0 +1,36 @@
+package postgres
+
+import (
+	"companies-service/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatementTimeout(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		server config.ServerConfig
+		want   time.Duration
+	}{
+		{name: "Default timeout", server: config.ServerConfig{CtxDefaultTimeout: 8},
+			want: 8 * time.Second},
+		{name: "Longer route timeout", server: config.ServerConfig{CtxDefaultTimeout: 8,
+			RouteTimeouts: []config.RouteTimeout{{Route: "/a", Timeout: 4},
+				{Route: "/b", Timeout: 30}}},
+			want: 30 * time.Second},
+		{name: "Shorter route timeouts", server: config.ServerConfig{CtxDefaultTimeout: 8,
+			RouteTimeouts: []config.RouteTimeout{{Route: "/a", Timeout: 4}}},
+			want: 8 * time.Second},
+		{name: "Without timeouts", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, statementTimeout(&config.Config{Server: tt.server}))
+		})
+	}
+}
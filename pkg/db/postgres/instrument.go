@@ -0,0 +1,283 @@
+package postgres
+
+import (
+	"companies-service/pkg/logger"
+	"companies-service/pkg/metric"
+	"companies-service/pkg/tracing"
+	"context"
+	"database/sql/driver"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// unnamedQuery is the name of the queries without a name comment
+const unnamedQuery = "unnamed"
+
+var (
+	// queryNamePattern matches the leading "-- name: <name>" comment of a query
+	queryNamePattern = regexp.MustCompile(`^\s*--\s*name:\s*(\S+)`)
+	commentPattern   = regexp.MustCompile(`--[^\n]*`)
+	// The placeholders are matched to be kept, the literals are replaced
+	literalPattern = regexp.MustCompile(`\$\d+|'(?:[^']|'')*'|\b\d+(?:\.\d+)?\b`)
+	spacesPattern  = regexp.MustCompile(`\s+`)
+)
+
+// QueryName returns the name of the leading "-- name: <name>" comment of the query
+func QueryName(query string) string {
+	if match := queryNamePattern.FindStringSubmatch(query); match != nil {
+		return match[1]
+	}
+	return unnamedQuery
+}
+
+// SanitizeQuery strips the comments and the literals of the query and collapses the spaces,
+// the query parameters are never part of it
+func SanitizeQuery(query string) string {
+	query = commentPattern.ReplaceAllString(query, "")
+	query = literalPattern.ReplaceAllStringFunc(query, func(literal string) string {
+		if strings.HasPrefix(literal, "$") {
+			return literal
+		}
+		return "?"
+	})
+	return strings.TrimSpace(spacesPattern.ReplaceAllString(query, " "))
+}
+
+// queryOperation returns the sql command of the query
+func queryOperation(sanitized string) string {
+	operation, _, _ := strings.Cut(sanitized, " ")
+	return strings.ToUpper(operation)
+}
+
+// instrumentation of the database calls with spans, metrics and the slow query log
+type instrumentation struct {
+	metrics       metric.StoreMetrics
+	logger        logger.Logger
+	slowThreshold time.Duration
+}
+
+// queryObservation of a single query, it is finished when the rows are closed
+type queryObservation struct {
+	instr     *instrumentation
+	ctx       context.Context
+	span      trace.Span
+	name      string
+	operation string
+	sanitized string
+	start     time.Time
+}
+
+func (i *instrumentation) start(ctx context.Context, query string) *queryObservation {
+	sanitized := SanitizeQuery(query)
+	o := &queryObservation{
+		instr:     i,
+		name:      QueryName(query),
+		operation: queryOperation(sanitized),
+		sanitized: sanitized,
+		start:     time.Now(),
+	}
+
+	o.ctx, o.span = tracing.StartSpan(ctx, "postgres."+o.name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNamePostgreSQL,
+			semconv.DBOperationName(o.operation),
+			semconv.DBQuerySummary(o.name),
+			semconv.DBQueryText(sanitized),
+		))
+	return o
+}
+
+func (o *queryObservation) finish(err error, rows int64, rowsKey attribute.Key) {
+	duration := time.Since(o.start)
+	defer o.span.End()
+
+	status := metric.StoreSuccess
+	if err != nil && err != io.EOF {
+		status = metric.StoreError
+		tracing.RecordError(o.span, err)
+	} else {
+		o.span.SetAttributes(rowsKey.Int64(rows))
+	}
+
+	if o.instr.metrics != nil {
+		o.instr.metrics.ObserveQuery(o.name, o.operation, status, duration.Seconds())
+	}
+
+	if o.instr.slowThreshold > 0 && duration >= o.instr.slowThreshold {
+		logger.FromContext(o.ctx, o.instr.logger).Warnw("slow query",
+			"query", o.name,
+			"duration", duration,
+			"sql", o.sanitized,
+		)
+	}
+}
+
+// rowsAffectedKey is the attribute of the rows changed by a statement
+const rowsAffectedKey = attribute.Key("db.response.affected_rows")
+
+// instrumentedConnector opens the instrumented connections of the driver
+type instrumentedConnector struct {
+	dsn    string
+	driver driver.Driver
+	instr  *instrumentation
+}
+
+func (c *instrumentedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.driver.Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedConn{Conn: conn, instr: c.instr}, nil
+}
+
+func (c *instrumentedConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// instrumentedConn observes the queries of the connection,
+// the transactions run their queries on it too
+type instrumentedConn struct {
+	driver.Conn
+	instr *instrumentation
+}
+
+func (c *instrumentedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var (
+		stmt driver.Stmt
+		err  error
+	)
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedStmt{Stmt: stmt, query: query, instr: c.instr}, nil
+}
+
+func (c *instrumentedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c *instrumentedConn) ExecContext(
+	ctx context.Context, query string, args []driver.NamedValue,
+) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	o := c.instr.start(ctx, query)
+	result, err := execer.ExecContext(o.ctx, query, args)
+	o.finish(err, rowsAffected(result, err), rowsAffectedKey)
+	return result, err
+}
+
+func (c *instrumentedConn) QueryContext(
+	ctx context.Context, query string, args []driver.NamedValue,
+) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	o := c.instr.start(ctx, query)
+	rows, err := queryer.QueryContext(o.ctx, query, args)
+	if err != nil {
+		o.finish(err, 0, semconv.DBResponseReturnedRowsKey)
+		return nil, err
+	}
+	return &instrumentedRows{Rows: rows, observation: o}, nil
+}
+
+func (c *instrumentedConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+// instrumentedStmt observes the executions of a prepared statement
+type instrumentedStmt struct {
+	driver.Stmt
+	query string
+	instr *instrumentation
+}
+
+func (s *instrumentedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := s.Stmt.(driver.StmtExecContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	o := s.instr.start(ctx, s.query)
+	result, err := execer.ExecContext(o.ctx, args)
+	o.finish(err, rowsAffected(result, err), rowsAffectedKey)
+	return result, err
+}
+
+func (s *instrumentedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := s.Stmt.(driver.StmtQueryContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	o := s.instr.start(ctx, s.query)
+	rows, err := queryer.QueryContext(o.ctx, args)
+	if err != nil {
+		o.finish(err, 0, semconv.DBResponseReturnedRowsKey)
+		return nil, err
+	}
+	return &instrumentedRows{Rows: rows, observation: o}, nil
+}
+
+// instrumentedRows counts the rows read, the query is observed when they are closed
+type instrumentedRows struct {
+	driver.Rows
+	observation *queryObservation
+	count       int64
+	err         error
+	finished    bool
+}
+
+func (r *instrumentedRows) Next(dest []driver.Value) error {
+	err := r.Rows.Next(dest)
+	if err == nil {
+		r.count++
+	} else if err != io.EOF {
+		r.err = err
+	}
+	return err
+}
+
+func (r *instrumentedRows) Close() error {
+	err := r.Rows.Close()
+	if !r.finished {
+		r.finished = true
+		r.observation.finish(r.err, r.count, semconv.DBResponseReturnedRowsKey)
+	}
+	return err
+}
+
+func rowsAffected(result driver.Result, err error) int64 {
+	if err != nil || result == nil {
+		return 0
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0
+	}
+	return affected
+}
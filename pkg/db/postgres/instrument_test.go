@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "Named", query: "-- name: GetCompany\nSELECT * FROM companies WHERE id = $1",
+			want: "GetCompany"},
+		{name: "Leading spaces", query: "\n\t--name:   CreateCompany\nINSERT INTO companies",
+			want: "CreateCompany"},
+		{name: "Unnamed", query: "SELECT 1", want: unnamedQuery},
+		{name: "Name after the query", query: "SELECT 1 -- name: Other", want: unnamedQuery},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, QueryName(tt.query))
+		})
+	}
+}
+
+func TestSanitizeQuery(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "Placeholders kept",
+			query: "-- name: GetCompany\nSELECT *\n\tFROM companies\n\tWHERE company_id = $1",
+			want:  "SELECT * FROM companies WHERE company_id = $1"},
+		{name: "String literals",
+			query: "SELECT * FROM users WHERE email = 'user@test.com' AND role = 'it''s'",
+			want:  "SELECT * FROM users WHERE email = ? AND role = ?"},
+		{name: "Number literals",
+			query: "SELECT * FROM companies LIMIT 10 OFFSET 2.5",
+			want:  "SELECT * FROM companies LIMIT ? OFFSET ?"},
+		{name: "Identifiers with digits kept",
+			query: "SELECT col1 FROM table2 WHERE id = $12",
+			want:  "SELECT col1 FROM table2 WHERE id = $12"},
+		{name: "Comments stripped",
+			query: "SELECT 1 -- password 'secret'\n, 2",
+			want:  "SELECT ? , ?"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, SanitizeQuery(tt.query))
+		})
+	}
+}
+
+func TestQueryOperation(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "SELECT", queryOperation("select * FROM companies"))
+	assert.Equal(t, "", queryOperation(""))
+}
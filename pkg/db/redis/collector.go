@@ -0,0 +1,62 @@
+package redis
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+)
+
+// poolStatsCollector exports the connection pool stats of a redis client on every scrape
+type poolStatsCollector struct {
+	client     *redis.Client
+	hits       *prometheus.Desc
+	misses     *prometheus.Desc
+	timeouts   *prometheus.Desc
+	totalConns *prometheus.Desc
+	idleConns  *prometheus.Desc
+	staleConns *prometheus.Desc
+}
+
+// NewPoolStatsCollector returns the collector of the connection pool usage of the client,
+// the timeouts show the pool saturation
+func NewPoolStatsCollector(name string, client *redis.Client) prometheus.Collector {
+	return &poolStatsCollector{
+		client: client,
+		hits: prometheus.NewDesc(name+"_redis_pool_hits_total",
+			"Times a free connection was found in the redis pool", nil, nil),
+		misses: prometheus.NewDesc(name+"_redis_pool_misses_total",
+			"Times a free connection was not found in the redis pool", nil, nil),
+		timeouts: prometheus.NewDesc(name+"_redis_pool_timeouts_total",
+			"Times waiting for a redis pool connection timed out", nil, nil),
+		totalConns: prometheus.NewDesc(name+"_redis_pool_connections",
+			"Connections of the redis pool", nil, nil),
+		idleConns: prometheus.NewDesc(name+"_redis_pool_idle_connections",
+			"Idle connections of the redis pool", nil, nil),
+		staleConns: prometheus.NewDesc(name+"_redis_pool_stale_connections_total",
+			"Stale connections removed from the redis pool", nil, nil),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *poolStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.timeouts
+	ch <- c.totalConns
+	ch <- c.idleConns
+	ch <- c.staleConns
+}
+
+// Collect implements prometheus.Collector
+func (c *poolStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.client.PoolStats()
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(c.timeouts, prometheus.CounterValue,
+		float64(stats.Timeouts))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue,
+		float64(stats.TotalConns))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue,
+		float64(stats.IdleConns))
+	ch <- prometheus.MustNewConstMetric(c.staleConns, prometheus.CounterValue,
+		float64(stats.StaleConns))
+}
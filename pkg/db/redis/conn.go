@@ -2,13 +2,18 @@ package redis
 
 import (
 	"companies-service/config"
+	"companies-service/pkg/logger"
+	"companies-service/pkg/metric"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
-// NewRedisClient returns new redis client
-func NewRedisClient(cfg *config.Config) *redis.Client {
+// NewRedisClient returns new redis client, its commands are traced, measured
+// and logged when they are slower than the configured threshold
+func NewRedisClient(
+	cfg *config.Config, metrics metric.StoreMetrics, logger logger.Logger,
+) *redis.Client {
 	redisHost := cfg.Redis.RedisAddr
 
 	if redisHost == "" {
@@ -20,8 +25,15 @@ func NewRedisClient(cfg *config.Config) *redis.Client {
 		MinIdleConns: cfg.Redis.MinIdleConns,
 		PoolSize:     cfg.Redis.PoolSize,
 		PoolTimeout:  time.Duration(cfg.Redis.PoolTimeout) * time.Second,
-		Password:     cfg.Redis.Password, // no password set
-		DB:           cfg.Redis.DB,       // use default DB
+		// Commands honor the deadline of the request context
+		ContextTimeoutEnabled: true,
+		Password:              cfg.Redis.Password, // no password set
+		DB:                    cfg.Redis.DB,       // use default DB
+	})
+	client.AddHook(&instrumentationHook{
+		metrics:       metrics,
+		logger:        logger,
+		slowThreshold: time.Duration(cfg.Redis.SlowCommandThreshold) * time.Millisecond,
 	})
 
 	return client
@@ -0,0 +1,122 @@
+package redis
+
+import (
+	"companies-service/pkg/logger"
+	"companies-service/pkg/metric"
+	"companies-service/pkg/tracing"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// pipelineCommand is the command label of the pipelines
+const pipelineCommand = "pipeline"
+
+// instrumentationHook traces and measures the redis commands and logs the slow ones
+type instrumentationHook struct {
+	metrics       metric.StoreMetrics
+	logger        logger.Logger
+	slowThreshold time.Duration
+}
+
+func (h *instrumentationHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (h *instrumentationHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		sanitized := sanitizeCommand(cmd)
+		ctx, span := tracing.StartSpan(ctx, "redis."+cmd.Name(),
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemNameRedis,
+				semconv.DBOperationName(cmd.Name()),
+				semconv.DBQueryText(sanitized),
+			))
+		defer span.End()
+
+		start := time.Now()
+		err := next(ctx, cmd)
+		h.observe(ctx, span, cmd.Name(), err, time.Since(start), sanitized)
+
+		return err
+	}
+}
+
+func (h *instrumentationHook) ProcessPipelineHook(
+	next redis.ProcessPipelineHook,
+) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		summary := make([]string, 0, len(cmds))
+		for _, cmd := range cmds {
+			summary = append(summary, cmd.Name())
+		}
+
+		ctx, span := tracing.StartSpan(ctx, "redis."+pipelineCommand,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemNameRedis,
+				semconv.DBOperationName(pipelineCommand),
+				semconv.DBOperationBatchSize(len(cmds)),
+				semconv.DBQuerySummary(strings.Join(summary, " ")),
+			))
+		defer span.End()
+
+		start := time.Now()
+		err := next(ctx, cmds)
+		h.observe(ctx, span, pipelineCommand, err, time.Since(start), strings.Join(summary, " "))
+
+		return err
+	}
+}
+
+func (h *instrumentationHook) observe(
+	ctx context.Context, span trace.Span, command string, err error, duration time.Duration,
+	sanitized string,
+) {
+	status := metric.StoreSuccess
+	// A missing key is a result, not a failure
+	if err != nil && !errors.Is(err, redis.Nil) {
+		status = metric.StoreError
+		tracing.RecordError(span, err)
+	}
+
+	if h.metrics != nil {
+		h.metrics.ObserveRedisCommand(command, status, duration.Seconds())
+	}
+
+	if h.slowThreshold > 0 && duration >= h.slowThreshold {
+		logger.FromContext(ctx, h.logger).Warnw("slow redis command",
+			"command", command,
+			"duration", duration,
+			"args", sanitized,
+		)
+	}
+}
+
+// sanitizeCommand returns the command with its key, the values are never logged
+func sanitizeCommand(cmd redis.Cmder) string {
+	args := cmd.Args()
+	// The first argument of the connection commands is a credential
+	if len(args) < 2 || cmd.Name() == "auth" || cmd.Name() == "hello" {
+		return cmd.Name()
+	}
+
+	// The scripts are logged by their sha1, as with evalsha
+	if cmd.Name() == "eval" || cmd.Name() == "eval_ro" {
+		hash := sha1.Sum([]byte(fmt.Sprint(args[1])))
+		return fmt.Sprintf("%s %s ?", cmd.Name(), hex.EncodeToString(hash[:]))
+	}
+	return fmt.Sprintf("%s %v ?", cmd.Name(), args[1])
+}
@@ -0,0 +1,40 @@
+package redis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizeCommand(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	script := redis.NewScript(`return redis.call("GET", KEYS[1])`)
+
+	tests := []struct {
+		name string
+		cmd  redis.Cmder
+		want string
+	}{
+		{name: "Set", cmd: redis.NewStatusCmd(ctx, "set", "users:1", "secret", "ex", 60),
+			want: "set users:1 ?"},
+		{name: "Get", cmd: redis.NewStringCmd(ctx, "get", "users:1"), want: "get users:1 ?"},
+		{name: "Without key", cmd: redis.NewStatusCmd(ctx, "ping"), want: "ping"},
+		{name: "Auth", cmd: redis.NewStatusCmd(ctx, "auth", "user", "password"), want: "auth"},
+		{name: "Hello", cmd: redis.NewMapStringInterfaceCmd(ctx, "hello", 3, "auth", "user",
+			"password"), want: "hello"},
+		{name: "Eval", cmd: redis.NewCmd(ctx, "eval", `return redis.call("GET", KEYS[1])`, 1,
+			"users:1"), want: "eval " + script.Hash() + " ?"},
+		{name: "Evalsha", cmd: redis.NewCmd(ctx, "evalsha", script.Hash(), 1, "users:1"),
+			want: "evalsha " + script.Hash() + " ?"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, sanitizeCommand(tt.cmd))
+		})
+	}
+}
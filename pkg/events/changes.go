@@ -0,0 +1,50 @@
+package events
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// FieldChangeHandler handles a company updated event that changed a subscribed field, the
+// update holds the company before and after the change
+type FieldChangeHandler func(ctx context.Context, event *Envelope, update *CompanyUpdate) error
+
+// FieldChanges dispatches the company updated events to the handlers subscribed to their
+// changed fields, for example to react when a company gets registered
+type FieldChanges struct {
+	handlers map[string][]FieldChangeHandler
+}
+
+// NewFieldChanges returns a dispatcher without subscriptions
+func NewFieldChanges() *FieldChanges {
+	return &FieldChanges{handlers: map[string][]FieldChangeHandler{}}
+}
+
+// Subscribe registers the handler of the changes of a company field
+func (f *FieldChanges) Subscribe(field string, handler FieldChangeHandler) {
+	f.handlers[field] = append(f.handlers[field], handler)
+}
+
+// Dispatch calls the handlers of the changed fields of a company updated event in the order
+// of the changed fields, the other events are ignored. A handler subscribed to several
+// changed fields is called once per field. It stops at the first handler error
+func (f *FieldChanges) Dispatch(ctx context.Context, event *Envelope) error {
+	if event.Type != CompanyUpdated {
+		return nil
+	}
+
+	update, err := event.CompanyUpdatePayload()
+	if err != nil {
+		return err
+	}
+
+	for _, field := range update.ChangedFields {
+		for _, handler := range f.handlers[field] {
+			if err = handler(ctx, event, update); err != nil {
+				return errors.Wrapf(err, "FieldChanges.Dispatch %s", field)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldChanges_Dispatch(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	companyID := uuid.New()
+	company := Company{CompanyID: companyID, CompanyName: "Test Company", Registered: true,
+		AmountOfEmployees: 20}
+	previous := company
+	previous.Registered = false
+	previous.AmountOfEmployees = 10
+
+	newUpdate := func(t *testing.T, changedFields ...string) *Envelope {
+		event, err := NewCompanyEvent(CompanyUpdated, companyID, nil,
+			CompanyUpdate{Company: company, Previous: &previous, ChangedFields: changedFields})
+		require.NoError(t, err)
+		return event
+	}
+
+	t.Run("Order of the changed fields", func(t *testing.T) {
+		var calls []string
+		changes := NewFieldChanges()
+		recordCall := func(name string) FieldChangeHandler {
+			return func(_ context.Context, event *Envelope, update *CompanyUpdate) error {
+				assert.Equal(t, companyID, event.CompanyID)
+				require.NotNil(t, update.Previous)
+				assert.False(t, update.Previous.Registered)
+				calls = append(calls, name)
+				return nil
+			}
+		}
+		changes.Subscribe(FieldRegistered, recordCall("registered 1"))
+		changes.Subscribe(FieldAmountOfEmployees, recordCall("employees"))
+		changes.Subscribe(FieldRegistered, recordCall("registered 2"))
+		changes.Subscribe(FieldCompanyName, recordCall("name"))
+
+		err := changes.Dispatch(ctx, newUpdate(t, FieldAmountOfEmployees, FieldRegistered))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"employees", "registered 1", "registered 2"}, calls)
+	})
+
+	t.Run("Other events ignored", func(t *testing.T) {
+		changes := NewFieldChanges()
+		changes.Subscribe(FieldRegistered,
+			func(context.Context, *Envelope, *CompanyUpdate) error {
+				t.Fatal("handler called for a non update event")
+				return nil
+			})
+
+		for _, eventType := range []string{CompanyCreated, CompanyDeleted} {
+			event, err := NewCompanyEvent(eventType, companyID, nil, company)
+			require.NoError(t, err)
+			require.NoError(t, changes.Dispatch(ctx, event))
+		}
+	})
+
+	t.Run("Stops at the first error", func(t *testing.T) {
+		handlerErr := errors.New("handler error")
+		var calls int
+		changes := NewFieldChanges()
+		changes.Subscribe(FieldAmountOfEmployees,
+			func(context.Context, *Envelope, *CompanyUpdate) error {
+				calls++
+				return handlerErr
+			})
+		changes.Subscribe(FieldRegistered,
+			func(context.Context, *Envelope, *CompanyUpdate) error {
+				calls++
+				return nil
+			})
+
+		err := changes.Dispatch(ctx, newUpdate(t, FieldAmountOfEmployees, FieldRegistered))
+		assert.ErrorIs(t, err, handlerErr)
+		assert.ErrorContains(t, err, FieldAmountOfEmployees)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Invalid payload", func(t *testing.T) {
+		event := newUpdate(t)
+		event.Payload = []byte(`{"changed_fields": "registered"}`)
+
+		assert.Error(t, NewFieldChanges().Dispatch(ctx, event))
+	})
+}
+
+func TestCompanyUpdate_Changed(t *testing.T) {
+	t.Parallel()
+
+	update := &CompanyUpdate{ChangedFields: []string{FieldCompanyName, FieldRegistered}}
+	assert.True(t, update.Changed(FieldCompanyName))
+	assert.True(t, update.Changed(FieldRegistered))
+	assert.False(t, update.Changed(FieldCompanyType))
+	assert.False(t, (&CompanyUpdate{}).Changed(FieldCompanyName))
+}
@@ -0,0 +1,239 @@
+package events
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/segmentio/kafka-go"
+)
+
+// CloudEvents 1.0 kafka protocol binding
+const (
+	CloudEventsSpecVersion = "1.0"
+
+	contentTypeHeader         = "content-type"
+	cloudEventsHeaderPrefix   = "ce_"
+	contentTypeJSON           = "application/json"
+	contentTypeCloudEventJSON = "application/cloudevents+json"
+)
+
+// ErrNotCloudEvent is returned when decoding a message without cloudevents attributes
+var ErrNotCloudEvent = errors.New("message is not a cloudevent")
+
+// CloudEvent is a CloudEvents 1.0 event, the schema version, actor and partition key are
+// extension attributes
+type CloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype,omitempty"`
+	SchemaVersion   int             `json:"schemaversion,omitempty"`
+	ActorID         string          `json:"actorid,omitempty"`
+	ActorRole       string          `json:"actorrole,omitempty"`
+	PartitionKey    string          `json:"partitionkey,omitempty"`
+	Data            json.RawMessage `json:"data,omitempty"`
+}
+
+// cloudEvent returns the cloudevent of the envelope published on the topic
+func (enc *Encoder) cloudEvent(event *Envelope, topic string) *CloudEvent {
+	ce := &CloudEvent{
+		SpecVersion:     CloudEventsSpecVersion,
+		ID:              event.ID.String(),
+		Source:          enc.source + "/" + topic,
+		Type:            enc.typePrefix + "." + topic,
+		Subject:         event.CompanyID.String(),
+		Time:            event.OccurredAt,
+		DataContentType: contentTypeJSON,
+		SchemaVersion:   event.SchemaVersion,
+		PartitionKey:    event.CompanyID.String(),
+		Data:            event.Payload,
+	}
+	if event.Actor != nil {
+		ce.ActorID = event.Actor.UserID.String()
+		ce.ActorRole = event.Actor.Role
+	}
+	return ce
+}
+
+// BinaryMessage returns the kafka message of the event in binary mode
+func (ce *CloudEvent) BinaryMessage(topic string) kafka.Message {
+	attributes := []struct{ name, value string }{
+		{"specversion", ce.SpecVersion},
+		{"id", ce.ID},
+		{"source", ce.Source},
+		{"type", ce.Type},
+		{"subject", ce.Subject},
+		{"time", ce.Time.Format(time.RFC3339Nano)},
+		{"actorid", ce.ActorID},
+		{"actorrole", ce.ActorRole},
+		{"partitionkey", ce.PartitionKey},
+	}
+	if ce.SchemaVersion != 0 {
+		attributes = append(attributes,
+			struct{ name, value string }{"schemaversion", strconv.Itoa(ce.SchemaVersion)})
+	}
+
+	headers := make([]kafka.Header, 0, len(attributes)+1)
+	for _, attribute := range attributes {
+		if attribute.value == "" {
+			continue
+		}
+		headers = append(headers, kafka.Header{Key: cloudEventsHeaderPrefix + attribute.name,
+			Value: []byte(attribute.value)})
+	}
+	if ce.DataContentType != "" {
+		headers = append(headers, kafka.Header{Key: contentTypeHeader,
+			Value: []byte(ce.DataContentType)})
+	}
+
+	return kafka.Message{
+		Topic:   topic,
+		Key:     []byte(ce.PartitionKey),
+		Value:   ce.Data,
+		Time:    ce.Time,
+		Headers: headers,
+	}
+}
+
+// StructuredMessage returns the kafka message of the event in structured mode
+func (ce *CloudEvent) StructuredMessage(topic string) (kafka.Message, error) {
+	value, err := json.Marshal(ce)
+	if err != nil {
+		return kafka.Message{}, errors.Wrap(err, "CloudEvent.StructuredMessage.Marshal")
+	}
+
+	return kafka.Message{
+		Topic: topic,
+		Key:   []byte(ce.PartitionKey),
+		Value: value,
+		Time:  ce.Time,
+		Headers: []kafka.Header{
+			{Key: contentTypeHeader, Value: []byte(contentTypeCloudEventJSON)},
+		},
+	}, nil
+}
+
+// DecodeCloudEvent decodes a cloudevent published in binary or structured mode
+func DecodeCloudEvent(msg kafka.Message) (*CloudEvent, error) {
+	if strings.HasPrefix(headerValue(msg.Headers, contentTypeHeader),
+		contentTypeCloudEventJSON) {
+		var ce CloudEvent
+		if err := json.Unmarshal(msg.Value, &ce); err != nil {
+			return nil, errors.Wrap(err, "events.DecodeCloudEvent.Unmarshal")
+		}
+		return ce.validate()
+	}
+
+	ce := &CloudEvent{
+		SpecVersion:     headerValue(msg.Headers, cloudEventsHeaderPrefix+"specversion"),
+		ID:              headerValue(msg.Headers, cloudEventsHeaderPrefix+"id"),
+		Source:          headerValue(msg.Headers, cloudEventsHeaderPrefix+"source"),
+		Type:            headerValue(msg.Headers, cloudEventsHeaderPrefix+"type"),
+		Subject:         headerValue(msg.Headers, cloudEventsHeaderPrefix+"subject"),
+		DataContentType: headerValue(msg.Headers, contentTypeHeader),
+		ActorID:         headerValue(msg.Headers, cloudEventsHeaderPrefix+"actorid"),
+		ActorRole:       headerValue(msg.Headers, cloudEventsHeaderPrefix+"actorrole"),
+		PartitionKey:    headerValue(msg.Headers, cloudEventsHeaderPrefix+"partitionkey"),
+		Data:            msg.Value,
+	}
+	if ce.SpecVersion == "" {
+		return nil, ErrNotCloudEvent
+	}
+	if ce.PartitionKey == "" {
+		ce.PartitionKey = string(msg.Key)
+	}
+
+	if eventTime := headerValue(msg.Headers, cloudEventsHeaderPrefix+"time"); eventTime != "" {
+		parsedTime, err := time.Parse(time.RFC3339Nano, eventTime)
+		if err != nil {
+			return nil, errors.Wrap(err, "events.DecodeCloudEvent.ParseTime")
+		}
+		ce.Time = parsedTime
+	}
+
+	if schemaVersion := headerValue(msg.Headers,
+		cloudEventsHeaderPrefix+"schemaversion"); schemaVersion != "" {
+		version, err := strconv.Atoi(schemaVersion)
+		if err != nil {
+			return nil, errors.Wrap(err, "events.DecodeCloudEvent.ParseSchemaVersion")
+		}
+		ce.SchemaVersion = version
+	}
+
+	return ce.validate()
+}
+
+// validate checks the required attributes and the spec and schema versions of the event
+func (ce *CloudEvent) validate() (*CloudEvent, error) {
+	if ce.SpecVersion == "" {
+		return nil, ErrNotCloudEvent
+	}
+	if ce.SpecVersion != CloudEventsSpecVersion {
+		return nil, errors.Errorf("events: unsupported cloudevents spec version %q",
+			ce.SpecVersion)
+	}
+	if ce.ID == "" || ce.Source == "" || ce.Type == "" {
+		return nil, errors.New("events: cloudevent without id, source or type")
+	}
+	if ce.SchemaVersion > SchemaVersion {
+		return nil, errors.Wrapf(ErrUnsupportedSchemaVersion, "version %d", ce.SchemaVersion)
+	}
+	return ce, nil
+}
+
+// CompanyID returns the company id of the event subject
+func (ce *CloudEvent) CompanyID() (uuid.UUID, error) {
+	companyID, err := uuid.Parse(ce.Subject)
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, "CloudEvent.CompanyID.Parse")
+	}
+	return companyID, nil
+}
+
+// Envelope returns the envelope of the event, the cloudevent type is derived from the topic
+// so the event type of the topic is given
+func (ce *CloudEvent) Envelope(eventType string) (*Envelope, error) {
+	id, err := uuid.Parse(ce.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "CloudEvent.Envelope.ParseID")
+	}
+	companyID, err := ce.CompanyID()
+	if err != nil {
+		return nil, err
+	}
+
+	envelope := &Envelope{ID: id, Type: eventType, OccurredAt: ce.Time, CompanyID: companyID,
+		SchemaVersion: ce.SchemaVersion, Payload: ce.Data}
+	if ce.ActorID != "" {
+		userID, err := uuid.Parse(ce.ActorID)
+		if err != nil {
+			return nil, errors.Wrap(err, "CloudEvent.Envelope.ParseActorID")
+		}
+		envelope.Actor = &Actor{UserID: userID, Role: ce.ActorRole}
+	}
+	return envelope, nil
+}
+
+// DecodeData decodes the event data into v
+func (ce *CloudEvent) DecodeData(v interface{}) error {
+	if err := json.Unmarshal(ce.Data, v); err != nil {
+		return errors.Wrap(err, "CloudEvent.DecodeData.Unmarshal")
+	}
+	return nil
+}
+
+func headerValue(headers []kafka.Header, key string) string {
+	for _, header := range headers {
+		if strings.EqualFold(header.Key, key) {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
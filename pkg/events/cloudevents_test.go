@@ -0,0 +1,147 @@
+package events
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEncoder(t *testing.T) {
+	t.Parallel()
+
+	encoder, err := NewEncoder("", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, ModeEnvelope, encoder.Mode())
+
+	for _, mode := range []string{ModeBinary, ModeStructured} {
+		_, err = NewEncoder(mode, "", "com.companies")
+		assert.Error(t, err, mode)
+		_, err = NewEncoder(mode, "/companies-service", "")
+		assert.Error(t, err, mode)
+	}
+
+	_, err = NewEncoder(ModeProtobuf, "/companies-service", "com.companies")
+	assert.Error(t, err)
+	_, err = NewEncoder("xml", "/companies-service", "com.companies")
+	assert.Error(t, err)
+}
+
+func TestEncoder_CloudEvents(t *testing.T) {
+	t.Parallel()
+
+	companyID := uuid.New()
+	actor := &Actor{UserID: uuid.New(), Role: "admin"}
+	company := Company{CompanyID: companyID, CompanyName: "Test Company",
+		AmountOfEmployees: 10, Registered: true, CompanyType: "NonProfit"}
+
+	event, err := NewCompanyEvent(CompanyCreated, companyID, actor, company)
+	require.NoError(t, err)
+
+	for _, mode := range []string{ModeBinary, ModeStructured} {
+		t.Run(mode, func(t *testing.T) {
+			t.Parallel()
+
+			encoder, err := NewEncoder(mode, "/companies-service/", "com.companies.")
+			require.NoError(t, err)
+
+			msg, err := encoder.Encode(event, "company_created")
+			require.NoError(t, err)
+			assert.Equal(t, "company_created", msg.Topic)
+			assert.Equal(t, companyID.String(), string(msg.Key))
+
+			if mode == ModeStructured {
+				assert.Equal(t, contentTypeCloudEventJSON, headerValue(msg.Headers,
+					contentTypeHeader))
+				var structured map[string]interface{}
+				require.NoError(t, json.Unmarshal(msg.Value, &structured))
+				assert.Equal(t, CloudEventsSpecVersion, structured["specversion"])
+				assert.Contains(t, structured, "data")
+			} else {
+				assert.Equal(t, contentTypeJSON, headerValue(msg.Headers, contentTypeHeader))
+				assert.JSONEq(t, string(event.Payload), string(msg.Value))
+			}
+
+			ce, err := DecodeCloudEvent(msg)
+			require.NoError(t, err)
+			assert.Equal(t, event.ID.String(), ce.ID)
+			assert.Equal(t, "/companies-service/company_created", ce.Source)
+			assert.Equal(t, "com.companies.company_created", ce.Type)
+			assert.Equal(t, companyID.String(), ce.PartitionKey)
+			assert.Equal(t, SchemaVersion, ce.SchemaVersion)
+			assert.True(t, event.OccurredAt.Equal(ce.Time))
+
+			decoded, err := ce.Envelope(CompanyCreated)
+			require.NoError(t, err)
+			assert.Equal(t, event.ID, decoded.ID)
+			assert.Equal(t, companyID, decoded.CompanyID)
+			assert.Equal(t, actor, decoded.Actor)
+
+			payload, err := decoded.CompanyPayload()
+			require.NoError(t, err)
+			assert.Equal(t, company, *payload)
+		})
+	}
+}
+
+func TestDecodeCloudEvent_Invalid(t *testing.T) {
+	t.Parallel()
+
+	binary := func(headers ...string) kafka.Message {
+		msg := kafka.Message{Value: []byte(`{}`)}
+		for i := 0; i < len(headers); i += 2 {
+			msg.Headers = append(msg.Headers,
+				kafka.Header{Key: headers[i], Value: []byte(headers[i+1])})
+		}
+		return msg
+	}
+	structured := func(value string) kafka.Message {
+		return kafka.Message{Value: []byte(value), Headers: []kafka.Header{
+			{Key: contentTypeHeader, Value: []byte(contentTypeCloudEventJSON)}}}
+	}
+	required := []string{"ce_specversion", "1.0", "ce_id", "1", "ce_source", "/companies",
+		"ce_type", "com.companies.company_created"}
+
+	tests := []struct {
+		name    string
+		msg     kafka.Message
+		wantErr error
+	}{
+		{name: "Envelope", msg: kafka.Message{Value: []byte(`{"type":"company_created"}`)},
+			wantErr: ErrNotCloudEvent},
+		{name: "Structured without spec version",
+			msg:     structured(`{"id":"1","source":"/companies","type":"company"}`),
+			wantErr: ErrNotCloudEvent},
+		{name: "Unsupported spec version", msg: binary("ce_specversion", "0.3", "ce_id", "1",
+			"ce_source", "/companies", "ce_type", "company")},
+		{name: "Structured unsupported spec version",
+			msg: structured(`{"specversion":"0.3","id":"1","source":"/companies","type":"c"}`)},
+		{name: "Without id", msg: binary("ce_specversion", "1.0", "ce_source", "/companies",
+			"ce_type", "company")},
+		{name: "Structured without type",
+			msg: structured(`{"specversion":"1.0","id":"1","source":"/companies"}`)},
+		{name: "Invalid structured event", msg: structured(`{"specversion":`)},
+		{name: "Invalid time", msg: binary(append(required, "ce_time", "yesterday")...)},
+		{name: "Invalid schema version",
+			msg: binary(append(required, "ce_schemaversion", "one")...)},
+		{name: "Newer schema version",
+			msg:     binary(append(required, "ce_schemaversion", "99")...),
+			wantErr: ErrUnsupportedSchemaVersion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ce, err := DecodeCloudEvent(tt.msg)
+			require.Error(t, err)
+			assert.Nil(t, ce)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
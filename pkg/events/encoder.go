@@ -0,0 +1,74 @@
+package events
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/segmentio/kafka-go"
+)
+
+// Publishing modes of the company events
+const (
+	// ModeEnvelope publishes the versioned envelope as the message value
+	ModeEnvelope = "envelope"
+	// ModeBinary publishes the payload as the message value and the cloudevents attributes
+	// as ce_* headers
+	ModeBinary = "binary"
+	// ModeStructured publishes the whole cloudevent as the message value
+	ModeStructured = "structured"
+	// ModeProtobuf publishes the protobuf event in the Confluent wire format, with the id of
+	// its schema in the schema registry
+	ModeProtobuf = "protobuf"
+	// ModeAvro publishes the avro event in the Confluent wire format
+	ModeAvro = "avro"
+)
+
+// Encoder encodes the company events to kafka messages in a publishing mode
+type Encoder struct {
+	mode       string
+	source     string
+	typePrefix string
+	codec      schemaCodec
+	schemaIDs  map[string]int
+}
+
+// NewEncoder returns the encoder of a publishing mode. The cloudevents source is the source
+// followed by the topic and their type is the type prefix followed by the topic
+func NewEncoder(mode, source, typePrefix string) (*Encoder, error) {
+	switch mode {
+	case "", ModeEnvelope:
+		mode = ModeEnvelope
+	case ModeBinary, ModeStructured:
+		if source == "" || typePrefix == "" {
+			return nil, errors.Errorf("events: the %s mode requires a source and a type prefix",
+				mode)
+		}
+	case ModeProtobuf, ModeAvro:
+		return nil, errors.Errorf("events: the %s mode requires a schema registry, "+
+			"use NewSchemaEncoder", mode)
+	default:
+		return nil, errors.Errorf("events: unknown publishing mode %q", mode)
+	}
+
+	return &Encoder{mode: mode, source: strings.TrimSuffix(source, "/"),
+		typePrefix: strings.TrimSuffix(typePrefix, ".")}, nil
+}
+
+// Mode returns the publishing mode of the encoder
+func (enc *Encoder) Mode() string {
+	return enc.mode
+}
+
+// Encode returns the kafka message of the event on the topic, keyed by the company id
+func (enc *Encoder) Encode(event *Envelope, topic string) (kafka.Message, error) {
+	switch enc.mode {
+	case ModeBinary:
+		return enc.cloudEvent(event, topic).BinaryMessage(topic), nil
+	case ModeStructured:
+		return enc.cloudEvent(event, topic).StructuredMessage(topic)
+	case ModeProtobuf, ModeAvro:
+		return enc.encodeWithSchema(event, topic)
+	default:
+		return event.Message(topic)
+	}
+}
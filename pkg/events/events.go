@@ -0,0 +1,171 @@
+// Package events defines the envelope of the company events published on kafka, consumers
+// import it to decode the messages of the company topics.
+package events
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/segmentio/kafka-go"
+)
+
+// SchemaVersion is the version of the envelope and payloads published by this service
+const SchemaVersion = 1
+
+// Company event types
+const (
+	CompanyCreated = "company.created"
+	CompanyUpdated = "company.updated"
+	CompanyDeleted = "company.deleted"
+)
+
+// Company fields, the names of the changed fields of the company updated events
+const (
+	FieldCompanyName        = "company_name"
+	FieldCompanyDescription = "company_description"
+	FieldAmountOfEmployees  = "amount_of_employees"
+	FieldRegistered         = "registered"
+	FieldCompanyType        = "company_type"
+)
+
+// ErrUnsupportedSchemaVersion is returned when decoding an event of a newer schema version
+var ErrUnsupportedSchemaVersion = errors.New("unsupported event schema version")
+
+// Envelope is the message value shared by all the company topics
+type Envelope struct {
+	ID            uuid.UUID       `json:"id"`
+	Type          string          `json:"type"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	CompanyID     uuid.UUID       `json:"company_id"`
+	Actor         *Actor          `json:"actor,omitempty"`
+	SchemaVersion int             `json:"schema_version"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// Actor is the user who caused the event
+type Actor struct {
+	UserID uuid.UUID `json:"user_id"`
+	Role   string    `json:"role,omitempty"`
+}
+
+// Company is the payload of the company created and updated events
+type Company struct {
+	CompanyID          uuid.UUID `json:"company_id"`
+	CompanyName        string    `json:"company_name"`
+	CompanyDescription string    `json:"company_description"`
+	AmountOfEmployees  int       `json:"amount_of_employees"`
+	Registered         bool      `json:"registered"`
+	CompanyType        string    `json:"company_type"`
+}
+
+// CompanyUpdate is the payload of the company updated events. The embedded company is the
+// state after the update, so the payload also decodes as a Company
+type CompanyUpdate struct {
+	Company
+	Previous      *Company `json:"previous,omitempty"`
+	ChangedFields []string `json:"changed_fields"`
+}
+
+// Changed reports whether the field changed in the update
+func (u *CompanyUpdate) Changed(field string) bool {
+	for _, changed := range u.ChangedFields {
+		if changed == field {
+			return true
+		}
+	}
+	return false
+}
+
+// DeletedCompany is the payload of the company deleted events
+type DeletedCompany struct {
+	CompanyID uuid.UUID `json:"company_id"`
+}
+
+// NewCompanyEvent returns the envelope of a company event occurred now
+func NewCompanyEvent(
+	eventType string, companyID uuid.UUID, actor *Actor, payload interface{},
+) (*Envelope, error) {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "events.NewCompanyEvent.Marshal")
+	}
+
+	return &Envelope{
+		ID:            uuid.New(),
+		Type:          eventType,
+		OccurredAt:    time.Now().UTC(),
+		CompanyID:     companyID,
+		Actor:         actor,
+		SchemaVersion: SchemaVersion,
+		Payload:       payloadBytes,
+	}, nil
+}
+
+// Message returns the kafka message of the event, keyed by the company id so that all the
+// events of a company land on the same partition in order
+func (e *Envelope) Message(topic string) (kafka.Message, error) {
+	value, err := json.Marshal(e)
+	if err != nil {
+		return kafka.Message{}, errors.Wrap(err, "Envelope.Message.Marshal")
+	}
+
+	return kafka.Message{
+		Topic: topic,
+		Key:   []byte(e.CompanyID.String()),
+		Value: value,
+		Time:  e.OccurredAt,
+	}, nil
+}
+
+// Decode decodes the envelope of a message value, the payload is decoded separately
+// with DecodePayload according to the event type
+func Decode(value []byte) (*Envelope, error) {
+	var envelope Envelope
+	if err := json.Unmarshal(value, &envelope); err != nil {
+		return nil, errors.Wrap(err, "events.Decode.Unmarshal")
+	}
+
+	if envelope.SchemaVersion > SchemaVersion {
+		return nil, errors.Wrapf(ErrUnsupportedSchemaVersion, "version %d",
+			envelope.SchemaVersion)
+	}
+
+	return &envelope, nil
+}
+
+// DecodePayload decodes the payload into v
+func (e *Envelope) DecodePayload(v interface{}) error {
+	if err := json.Unmarshal(e.Payload, v); err != nil {
+		return errors.Wrap(err, "Envelope.DecodePayload.Unmarshal")
+	}
+	return nil
+}
+
+// CompanyPayload decodes the payload of a company created or updated event
+func (e *Envelope) CompanyPayload() (*Company, error) {
+	var company Company
+	if err := e.DecodePayload(&company); err != nil {
+		return nil, err
+	}
+	return &company, nil
+}
+
+// CompanyUpdatePayload decodes the payload of a company updated event
+func (e *Envelope) CompanyUpdatePayload() (*CompanyUpdate, error) {
+	var update CompanyUpdate
+	if err := e.DecodePayload(&update); err != nil {
+		return nil, err
+	}
+	return &update, nil
+}
+
+// DeletedCompanyPayload decodes the payload of a company deleted event
+func (e *Envelope) DeletedCompanyPayload() (*DeletedCompany, error) {
+	var payload DeletedCompany
+	if err := e.DecodePayload(&payload); err != nil {
+		return nil, err
+	}
+	return &payload, nil
+}
@@ -0,0 +1,626 @@
+package events
+
+import (
+	"companies-service/pkg/schemaregistry"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/linkedin/goavro/v2"
+	"github.com/pkg/errors"
+	"github.com/segmentio/kafka-go"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const avroNamespace = "companies.events.v1."
+
+var (
+	//go:embed schemas/company_event.proto
+	protobufSchema string
+	//go:embed schemas/company_event.avsc
+	avroSchema string
+)
+
+// schemaCodec encodes and decodes the events with a schema
+type schemaCodec interface {
+	encode(event *Envelope) ([]byte, error)
+	decode(payload []byte) (*Envelope, error)
+}
+
+// NewSchemaEncoder returns the encoder of the protobuf or avro mode. The event schema is
+// checked against the latest schema of the topics and registered, it fails when it is
+// incompatible so that a breaking change never reaches the consumers
+func NewSchemaEncoder(
+	ctx context.Context, mode string, registry schemaregistry.SchemaRegistry, topics []string,
+) (*Encoder, error) {
+	schemaType, schema, err := eventSchema(mode)
+	if err != nil {
+		return nil, err
+	}
+
+	codec, err := newSchemaCodec(ctx, schemaType, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	schemaIDs := make(map[string]int, len(topics))
+	for _, topic := range topics {
+		subject := schemaregistry.ValueSubject(topic)
+
+		compatible, err := registry.Compatible(ctx, subject, schemaType, schema)
+		if err != nil {
+			return nil, errors.Wrapf(err, "events.NewSchemaEncoder.Compatible %s", subject)
+		}
+		if !compatible {
+			return nil, errors.Wrapf(schemaregistry.ErrIncompatibleSchema,
+				"the %s schema of the events breaks the latest schema of %s", schemaType, subject)
+		}
+
+		registered, err := registry.Register(ctx, subject, schemaType, schema)
+		if err != nil {
+			return nil, errors.Wrapf(err, "events.NewSchemaEncoder.Register %s", subject)
+		}
+		schemaIDs[topic] = registered.ID
+	}
+
+	return &Encoder{mode: mode, codec: codec, schemaIDs: schemaIDs}, nil
+}
+
+// encodeWithSchema returns the message of the event in the Confluent wire format
+func (enc *Encoder) encodeWithSchema(event *Envelope, topic string) (kafka.Message, error) {
+	schemaID, ok := enc.schemaIDs[topic]
+	if !ok {
+		return kafka.Message{}, errors.Errorf("events: no schema registered for topic %s", topic)
+	}
+
+	payload, err := enc.codec.encode(event)
+	if err != nil {
+		return kafka.Message{}, err
+	}
+
+	return kafka.Message{
+		Topic: topic,
+		Key:   []byte(event.CompanyID.String()),
+		Value: append(schemaregistry.AppendHeader(nil, schemaID), payload...),
+		Time:  event.OccurredAt,
+	}, nil
+}
+
+// Decoder decodes the company event envelopes of the envelope, protobuf and avro modes, the
+// schemas are read from the registry by the id of the messages
+type Decoder struct {
+	registry schemaregistry.SchemaRegistry
+
+	mu     sync.RWMutex
+	codecs map[int]schemaCodec
+}
+
+// NewDecoder returns the decoder of the events, the registry may be nil to decode only the
+// json envelopes
+func NewDecoder(registry schemaregistry.SchemaRegistry) *Decoder {
+	return &Decoder{registry: registry, codecs: map[int]schemaCodec{}}
+}
+
+// Decode decodes the envelope of a message value
+func (d *Decoder) Decode(ctx context.Context, value []byte) (*Envelope, error) {
+	schemaID, payload, err := schemaregistry.ParseHeader(value)
+	if err != nil {
+		return Decode(value)
+	}
+
+	codec, err := d.codec(ctx, schemaID)
+	if err != nil {
+		return nil, err
+	}
+
+	envelope, err := codec.decode(payload)
+	if err != nil {
+		return nil, err
+	}
+	if envelope.SchemaVersion > SchemaVersion {
+		return nil, errors.Wrapf(ErrUnsupportedSchemaVersion, "version %d",
+			envelope.SchemaVersion)
+	}
+	return envelope, nil
+}
+
+// DecodeMessage decodes the envelope of a message published in any mode, the event type of
+// the topic is the type of the cloudevents
+func (d *Decoder) DecodeMessage(
+	ctx context.Context, msg kafka.Message, eventType string,
+) (*Envelope, error) {
+	ce, err := DecodeCloudEvent(msg)
+	if errors.Is(err, ErrNotCloudEvent) {
+		return d.Decode(ctx, msg.Value)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ce.Envelope(eventType)
+}
+
+func (d *Decoder) codec(ctx context.Context, schemaID int) (schemaCodec, error) {
+	d.mu.RLock()
+	codec, ok := d.codecs[schemaID]
+	d.mu.RUnlock()
+	if ok {
+		return codec, nil
+	}
+
+	if d.registry == nil {
+		return nil, errors.New("events: a schema registry is required to decode the message")
+	}
+
+	schema, err := d.registry.ByID(ctx, schemaID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Decoder.codec.ByID")
+	}
+
+	codec, err = newSchemaCodec(ctx, schema.Type, schema.Schema)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	d.codecs[schemaID] = codec
+	d.mu.Unlock()
+
+	return codec, nil
+}
+
+func eventSchema(mode string) (string, string, error) {
+	switch mode {
+	case ModeProtobuf:
+		return schemaregistry.TypeProtobuf, protobufSchema, nil
+	case ModeAvro:
+		return schemaregistry.TypeAvro, avroSchema, nil
+	default:
+		return "", "", errors.Errorf("events: %q is not a schema publishing mode", mode)
+	}
+}
+
+func newSchemaCodec(ctx context.Context, schemaType, schema string) (schemaCodec, error) {
+	switch schemaType {
+	case schemaregistry.TypeProtobuf:
+		file, err := schemaregistry.CompileProtobuf(ctx, schema)
+		if err != nil {
+			return nil, err
+		}
+		if file.Messages().Len() == 0 {
+			return nil, errors.New("events: protobuf schema without messages")
+		}
+		return &protobufCodec{file: file}, nil
+	case schemaregistry.TypeAvro:
+		codec, err := goavro.NewCodec(schema)
+		if err != nil {
+			return nil, errors.Wrap(err, "events.newSchemaCodec.NewCodec")
+		}
+		return &avroCodec{codec: codec}, nil
+	default:
+		return nil, errors.Errorf("events: unsupported schema type %q", schemaType)
+	}
+}
+
+// eventPayload returns the typed payload of the event, the company payloads are decoded as
+// updates without previous state and changed fields unless they are updates
+func eventPayload(event *Envelope) (*CompanyUpdate, *DeletedCompany, error) {
+	if event.Type == CompanyDeleted {
+		deleted, err := event.DeletedCompanyPayload()
+		return nil, deleted, err
+	}
+	company, err := event.CompanyUpdatePayload()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = checkInt32("amount_of_employees", company.AmountOfEmployees); err != nil {
+		return nil, nil, err
+	}
+	if company.Previous != nil {
+		if err = checkInt32("previous.amount_of_employees",
+			company.Previous.AmountOfEmployees); err != nil {
+			return nil, nil, err
+		}
+	}
+	return company, nil, nil
+}
+
+// checkInt32 fails for the values of the int32 schema fields that would be truncated
+func checkInt32(field string, value int) error {
+	if value < math.MinInt32 || value > math.MaxInt32 {
+		return errors.Errorf("events: %s %d overflows the int32 schema field", field, value)
+	}
+	return nil
+}
+
+// decodedCompanyPayload returns the payload of a decoded company event, the previous state
+// and changed fields are only part of the updated events
+func decodedCompanyPayload(
+	eventType string, company map[string]interface{}, previous map[string]interface{},
+	changedFields []string,
+) map[string]interface{} {
+	if eventType != CompanyUpdated {
+		return company
+	}
+	if previous != nil {
+		company["previous"] = previous
+	}
+	company["changed_fields"] = changedFields
+	return company
+}
+
+// newEnvelope returns the envelope of the decoded fields and payload
+func newEnvelope(
+	id, eventType, companyID string,
+	occurredAt time.Time,
+	actor *Actor,
+	schemaVersion int,
+	payload interface{},
+) (*Envelope, error) {
+	envelope := &Envelope{Type: eventType, OccurredAt: occurredAt.UTC(), Actor: actor,
+		SchemaVersion: schemaVersion}
+
+	var err error
+	if envelope.ID, err = uuid.Parse(id); err != nil {
+		return nil, errors.Wrap(err, "events.newEnvelope.ParseID")
+	}
+	if envelope.CompanyID, err = uuid.Parse(companyID); err != nil {
+		return nil, errors.Wrap(err, "events.newEnvelope.ParseCompanyID")
+	}
+	if envelope.Payload, err = json.Marshal(payload); err != nil {
+		return nil, errors.Wrap(err, "events.newEnvelope.Marshal")
+	}
+	return envelope, nil
+}
+
+// protobufCodec encodes the events with the first message of the protobuf schema
+type protobufCodec struct {
+	file protoreflect.FileDescriptor
+}
+
+func (c *protobufCodec) encode(event *Envelope) ([]byte, error) {
+	company, deleted, err := eventPayload(event)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkInt32("schema_version", event.SchemaVersion); err != nil {
+		return nil, err
+	}
+
+	msg := dynamicpb.NewMessage(c.file.Messages().Get(0))
+	setProtoField(msg, "id", protoreflect.ValueOfString(event.ID.String()))
+	setProtoField(msg, "type", protoreflect.ValueOfString(event.Type))
+	setProtoField(msg, "occurred_at", protoreflect.ValueOfInt64(event.OccurredAt.UnixMicro()))
+	setProtoField(msg, "company_id", protoreflect.ValueOfString(event.CompanyID.String()))
+	setProtoField(msg, "schema_version", protoreflect.ValueOfInt32(int32(event.SchemaVersion)))
+
+	if event.Actor != nil {
+		actor := mutableProtoMessage(msg, "actor")
+		setProtoField(actor, "user_id", protoreflect.ValueOfString(event.Actor.UserID.String()))
+		setProtoField(actor, "role", protoreflect.ValueOfString(event.Actor.Role))
+	}
+
+	if deleted != nil {
+		payload := mutableProtoMessage(msg, "deleted_company")
+		setProtoField(payload, "company_id",
+			protoreflect.ValueOfString(deleted.CompanyID.String()))
+	} else {
+		setProtoCompany(mutableProtoMessage(msg, "company"), &company.Company)
+		if company.Previous != nil {
+			setProtoCompany(mutableProtoMessage(msg, "previous"), company.Previous)
+		}
+		if field := msg.Descriptor().Fields().ByName("changed_fields"); field != nil {
+			changedFields := msg.Mutable(field).List()
+			for _, changed := range company.ChangedFields {
+				changedFields.Append(protoreflect.ValueOfString(changed))
+			}
+		}
+	}
+
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		return nil, errors.Wrap(err, "protobufCodec.encode.Marshal")
+	}
+	return append(schemaregistry.AppendMessageIndexes(nil, []int{0}), data...), nil
+}
+
+func (c *protobufCodec) decode(payload []byte) (*Envelope, error) {
+	indexes, data, err := schemaregistry.ReadMessageIndexes(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	descriptor := c.messageByIndexes(indexes)
+	if descriptor == nil {
+		return nil, errors.Errorf("events: no protobuf message at indexes %v", indexes)
+	}
+
+	msg := dynamicpb.NewMessage(descriptor)
+	if err = proto.Unmarshal(data, msg); err != nil {
+		return nil, errors.Wrap(err, "protobufCodec.decode.Unmarshal")
+	}
+
+	var actor *Actor
+	if actorMsg := protoMessageField(msg, "actor"); actorMsg != nil {
+		userID, err := uuid.Parse(protoString(actorMsg, "user_id"))
+		if err != nil {
+			return nil, errors.Wrap(err, "protobufCodec.decode.ParseActor")
+		}
+		actor = &Actor{UserID: userID, Role: protoString(actorMsg, "role")}
+	}
+
+	var payloadValue interface{}
+	if deletedMsg := protoMessageField(msg, "deleted_company"); deletedMsg != nil {
+		payloadValue = map[string]string{
+			"company_id": protoString(deletedMsg, "company_id"),
+		}
+	} else if companyMsg := protoMessageField(msg, "company"); companyMsg != nil {
+		var changedFields []string
+		if field := msg.Descriptor().Fields().ByName("changed_fields"); field != nil {
+			list := msg.Get(field).List()
+			changedFields = make([]string, 0, list.Len())
+			for i := 0; i < list.Len(); i++ {
+				changedFields = append(changedFields, list.Get(i).String())
+			}
+		}
+		payloadValue = decodedCompanyPayload(protoString(msg, "type"),
+			protoCompany(companyMsg), protoCompany(protoMessageField(msg, "previous")),
+			changedFields)
+	}
+
+	return newEnvelope(
+		protoString(msg, "id"),
+		protoString(msg, "type"),
+		protoString(msg, "company_id"),
+		time.UnixMicro(protoInt(msg, "occurred_at")),
+		actor,
+		int(protoInt(msg, "schema_version")),
+		payloadValue,
+	)
+}
+
+// messageByIndexes returns the message of the Confluent message indexes of the schema
+func (c *protobufCodec) messageByIndexes(indexes []int) protoreflect.MessageDescriptor {
+	messages := c.file.Messages()
+	var message protoreflect.MessageDescriptor
+	for _, index := range indexes {
+		if index >= messages.Len() {
+			return nil
+		}
+		message = messages.Get(index)
+		messages = message.Messages()
+	}
+	return message
+}
+
+// setProtoCompany sets the fields of a Company message
+func setProtoCompany(msg protoreflect.Message, company *Company) {
+	setProtoField(msg, "company_id", protoreflect.ValueOfString(company.CompanyID.String()))
+	setProtoField(msg, "company_name", protoreflect.ValueOfString(company.CompanyName))
+	setProtoField(msg, "company_description",
+		protoreflect.ValueOfString(company.CompanyDescription))
+	setProtoField(msg, "amount_of_employees",
+		protoreflect.ValueOfInt32(int32(company.AmountOfEmployees)))
+	setProtoField(msg, "registered", protoreflect.ValueOfBool(company.Registered))
+	setProtoField(msg, "company_type", protoreflect.ValueOfString(company.CompanyType))
+}
+
+// protoCompany returns the json fields of a Company message, nil for a missing message
+func protoCompany(msg protoreflect.Message) map[string]interface{} {
+	if msg == nil {
+		return nil
+	}
+	return map[string]interface{}{
+		"company_id":          protoString(msg, "company_id"),
+		"company_name":        protoString(msg, "company_name"),
+		"company_description": protoString(msg, "company_description"),
+		"amount_of_employees": protoInt(msg, "amount_of_employees"),
+		"registered":          protoBool(msg, "registered"),
+		"company_type":        protoString(msg, "company_type"),
+	}
+}
+
+// setProtoField sets the field of the message, the fields missing in the schema are skipped
+func setProtoField(msg protoreflect.Message, name string, value protoreflect.Value) {
+	if msg == nil {
+		return
+	}
+	if field := msg.Descriptor().Fields().ByName(protoreflect.Name(name)); field != nil {
+		msg.Set(field, value)
+	}
+}
+
+func mutableProtoMessage(msg protoreflect.Message, name string) protoreflect.Message {
+	field := msg.Descriptor().Fields().ByName(protoreflect.Name(name))
+	if field == nil || field.Message() == nil {
+		return nil
+	}
+	return msg.Mutable(field).Message()
+}
+
+// protoField returns the descriptor of a singular field of one of the kinds, nil when the
+// schema has no such field
+func protoField(
+	msg protoreflect.Message, name string, kinds ...protoreflect.Kind,
+) protoreflect.FieldDescriptor {
+	field := msg.Descriptor().Fields().ByName(protoreflect.Name(name))
+	if field == nil || field.IsList() || field.IsMap() {
+		return nil
+	}
+	for _, kind := range kinds {
+		if field.Kind() == kind {
+			return field
+		}
+	}
+	return nil
+}
+
+// protoString returns the value of a string field, empty when it is missing in the schema
+func protoString(msg protoreflect.Message, name string) string {
+	field := protoField(msg, name, protoreflect.StringKind)
+	if field == nil {
+		return ""
+	}
+	return msg.Get(field).String()
+}
+
+// protoInt returns the value of an integer field, zero when it is missing in the schema
+func protoInt(msg protoreflect.Message, name string) int64 {
+	field := protoField(msg, name, protoreflect.Int32Kind, protoreflect.Sint32Kind,
+		protoreflect.Sfixed32Kind, protoreflect.Int64Kind, protoreflect.Sint64Kind,
+		protoreflect.Sfixed64Kind)
+	if field == nil {
+		return 0
+	}
+	return msg.Get(field).Int()
+}
+
+// protoBool returns the value of a bool field, false when it is missing in the schema
+func protoBool(msg protoreflect.Message, name string) bool {
+	field := protoField(msg, name, protoreflect.BoolKind)
+	if field == nil {
+		return false
+	}
+	return msg.Get(field).Bool()
+}
+
+func protoMessageField(msg protoreflect.Message, name string) protoreflect.Message {
+	field := msg.Descriptor().Fields().ByName(protoreflect.Name(name))
+	if field == nil || field.Message() == nil || !msg.Has(field) {
+		return nil
+	}
+	return msg.Get(field).Message()
+}
+
+// avroCodec encodes the events with the avro schema
+type avroCodec struct {
+	codec *goavro.Codec
+}
+
+func (c *avroCodec) encode(event *Envelope) ([]byte, error) {
+	company, deleted, err := eventPayload(event)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkInt32("schema_version", event.SchemaVersion); err != nil {
+		return nil, err
+	}
+
+	native := map[string]interface{}{
+		"id":             event.ID.String(),
+		"type":           event.Type,
+		"occurred_at":    event.OccurredAt,
+		"company_id":     event.CompanyID.String(),
+		"actor":          goavro.Union("null", nil),
+		"schema_version": int32(event.SchemaVersion),
+		"previous":       goavro.Union("null", nil),
+		"changed_fields": []interface{}{},
+	}
+	if event.Actor != nil {
+		native["actor"] = goavro.Union(avroNamespace+"Actor", map[string]interface{}{
+			"user_id": event.Actor.UserID.String(),
+			"role":    event.Actor.Role,
+		})
+	}
+	if deleted != nil {
+		native["payload"] = goavro.Union(avroNamespace+"DeletedCompany",
+			map[string]interface{}{"company_id": deleted.CompanyID.String()})
+	} else {
+		native["payload"] = goavro.Union(avroNamespace+"Company", avroCompany(&company.Company))
+		if company.Previous != nil {
+			native["previous"] = goavro.Union(avroNamespace+"Company",
+				avroCompany(company.Previous))
+		}
+		changedFields := make([]interface{}, 0, len(company.ChangedFields))
+		for _, changed := range company.ChangedFields {
+			changedFields = append(changedFields, changed)
+		}
+		native["changed_fields"] = changedFields
+	}
+
+	data, err := c.codec.BinaryFromNative(nil, native)
+	if err != nil {
+		return nil, errors.Wrap(err, "avroCodec.encode.BinaryFromNative")
+	}
+	return data, nil
+}
+
+func (c *avroCodec) decode(payload []byte) (*Envelope, error) {
+	native, _, err := c.codec.NativeFromBinary(payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "avroCodec.decode.NativeFromBinary")
+	}
+	record, ok := native.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("events: avro event is not a record")
+	}
+
+	var actor *Actor
+	if actorRecord := avroUnionRecord(record["actor"]); actorRecord != nil {
+		userID, err := uuid.Parse(avroString(actorRecord["user_id"]))
+		if err != nil {
+			return nil, errors.Wrap(err, "avroCodec.decode.ParseActor")
+		}
+		actor = &Actor{UserID: userID, Role: avroString(actorRecord["role"])}
+	}
+
+	occurredAt, _ := record["occurred_at"].(time.Time)
+	schemaVersion, _ := record["schema_version"].(int32)
+
+	payloadValue := avroUnionRecord(record["payload"])
+	if _, isCompany := payloadValue["company_name"]; isCompany {
+		changedValues, _ := record["changed_fields"].([]interface{})
+		changedFields := make([]string, 0, len(changedValues))
+		for _, changed := range changedValues {
+			changedFields = append(changedFields, avroString(changed))
+		}
+		payloadValue = decodedCompanyPayload(avroString(record["type"]), payloadValue,
+			avroUnionRecord(record["previous"]), changedFields)
+	}
+
+	return newEnvelope(
+		avroString(record["id"]),
+		avroString(record["type"]),
+		avroString(record["company_id"]),
+		occurredAt,
+		actor,
+		int(schemaVersion),
+		payloadValue,
+	)
+}
+
+// avroCompany returns the native record of a company
+func avroCompany(company *Company) map[string]interface{} {
+	return map[string]interface{}{
+		"company_id":          company.CompanyID.String(),
+		"company_name":        company.CompanyName,
+		"company_description": company.CompanyDescription,
+		"amount_of_employees": int32(company.AmountOfEmployees),
+		"registered":          company.Registered,
+		"company_type":        company.CompanyType,
+	}
+}
+
+// avroUnionRecord returns the record of a decoded union, nil for the null branch
+func avroUnionRecord(value interface{}) map[string]interface{} {
+	union, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	for _, branch := range union {
+		if record, ok := branch.(map[string]interface{}); ok {
+			return record
+		}
+	}
+	return nil
+}
+
+func avroString(value interface{}) string {
+	s, _ := value.(string)
+	return s
+}
@@ -0,0 +1,252 @@
+package events
+
+import (
+	"companies-service/pkg/schemaregistry"
+	"context"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// protobufSchemaV0 is a previous protobuf schema without the schema version, the employees and
+// the registration of the companies
+const protobufSchemaV0 = `syntax = "proto3";
+package companies.events.v1;
+
+message CompanyEvent {
+  string id = 1;
+  string type = 2;
+  int64 occurred_at = 3;
+  string company_id = 4;
+  Actor actor = 5;
+
+  oneof payload {
+    Company company = 7;
+    DeletedCompany deleted_company = 8;
+  }
+}
+
+message Actor {
+  string user_id = 1;
+  string role = 2;
+}
+
+message Company {
+  string company_id = 1;
+  string company_name = 2;
+  string company_description = 3;
+  string company_type = 6;
+}
+
+message DeletedCompany {
+  string company_id = 1;
+}
+`
+
+func newFileRegistry(t *testing.T) schemaregistry.SchemaRegistry {
+	t.Helper()
+
+	registry, err := schemaregistry.NewFileRegistry(filepath.Join(t.TempDir(), "schemas.json"))
+	require.NoError(t, err)
+	return registry
+}
+
+func TestSchemaEncoder(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	companyID := uuid.New()
+	actor := &Actor{UserID: uuid.New(), Role: "admin"}
+	company := Company{CompanyID: companyID, CompanyName: "Test Company",
+		CompanyDescription: "It is a test company", AmountOfEmployees: 10, Registered: true,
+		CompanyType: "NonProfit"}
+
+	for _, mode := range []string{ModeProtobuf, ModeAvro} {
+		t.Run(mode, func(t *testing.T) {
+			t.Parallel()
+
+			registry := newFileRegistry(t)
+			encoder, err := NewSchemaEncoder(ctx, mode, registry,
+				[]string{"company_created", "company_updated", "company_deleted"})
+			require.NoError(t, err)
+			assert.Equal(t, mode, encoder.Mode())
+			decoder := NewDecoder(registry)
+
+			t.Run("Company created", func(t *testing.T) {
+				event, err := NewCompanyEvent(CompanyCreated, companyID, actor, company)
+				require.NoError(t, err)
+
+				msg, err := encoder.Encode(event, "company_created")
+				require.NoError(t, err)
+				assert.Equal(t, companyID.String(), string(msg.Key))
+
+				schemaID, _, err := schemaregistry.ParseHeader(msg.Value)
+				require.NoError(t, err)
+				schema, err := registry.ByID(ctx, schemaID)
+				require.NoError(t, err)
+				assert.Equal(t, schemaregistry.ValueSubject("company_created"), schema.Subject)
+
+				decoded, err := decoder.Decode(ctx, msg.Value)
+				require.NoError(t, err)
+				assert.Equal(t, event.ID, decoded.ID)
+				assert.Equal(t, CompanyCreated, decoded.Type)
+				assert.Equal(t, companyID, decoded.CompanyID)
+				assert.Equal(t, actor, decoded.Actor)
+				assert.Equal(t, SchemaVersion, decoded.SchemaVersion)
+				assert.True(t, event.OccurredAt.Truncate(time.Microsecond).Equal(decoded.OccurredAt))
+
+				payload, err := decoded.CompanyPayload()
+				require.NoError(t, err)
+				assert.Equal(t, company, *payload)
+			})
+
+			t.Run("Company updated", func(t *testing.T) {
+				previous := company
+				previous.CompanyName = "Old Company"
+				previous.Registered = false
+				changedFields := []string{FieldCompanyName, FieldRegistered}
+				event, err := NewCompanyEvent(CompanyUpdated, companyID, actor,
+					CompanyUpdate{Company: company, Previous: &previous,
+						ChangedFields: changedFields})
+				require.NoError(t, err)
+
+				msg, err := encoder.Encode(event, "company_updated")
+				require.NoError(t, err)
+
+				decoded, err := decoder.Decode(ctx, msg.Value)
+				require.NoError(t, err)
+
+				update, err := decoded.CompanyUpdatePayload()
+				require.NoError(t, err)
+				assert.Equal(t, company, update.Company)
+				require.NotNil(t, update.Previous)
+				assert.Equal(t, previous, *update.Previous)
+				assert.Equal(t, changedFields, update.ChangedFields)
+			})
+
+			t.Run("Company updated without changes", func(t *testing.T) {
+				event, err := NewCompanyEvent(CompanyUpdated, companyID, nil,
+					CompanyUpdate{Company: company})
+				require.NoError(t, err)
+
+				msg, err := encoder.Encode(event, "company_updated")
+				require.NoError(t, err)
+
+				decoded, err := decoder.Decode(ctx, msg.Value)
+				require.NoError(t, err)
+
+				update, err := decoded.CompanyUpdatePayload()
+				require.NoError(t, err)
+				assert.Nil(t, update.Previous)
+				assert.Empty(t, update.ChangedFields)
+			})
+
+			t.Run("Company deleted", func(t *testing.T) {
+				event, err := NewCompanyEvent(CompanyDeleted, companyID, nil,
+					DeletedCompany{CompanyID: companyID})
+				require.NoError(t, err)
+
+				msg, err := encoder.Encode(event, "company_deleted")
+				require.NoError(t, err)
+
+				decoded, err := decoder.Decode(ctx, msg.Value)
+				require.NoError(t, err)
+				assert.Nil(t, decoded.Actor)
+
+				payload, err := decoded.DeletedCompanyPayload()
+				require.NoError(t, err)
+				assert.Equal(t, companyID, payload.CompanyID)
+			})
+
+			t.Run("Employees overflow", func(t *testing.T) {
+				overflowing := company
+				overflowing.AmountOfEmployees = math.MaxInt32 + 1
+				event, err := NewCompanyEvent(CompanyCreated, companyID, nil, overflowing)
+				require.NoError(t, err)
+
+				_, err = encoder.Encode(event, "company_created")
+				assert.ErrorContains(t, err, "amount_of_employees")
+			})
+
+			t.Run("Topic without schema", func(t *testing.T) {
+				event, err := NewCompanyEvent(CompanyCreated, companyID, nil, company)
+				require.NoError(t, err)
+
+				_, err = encoder.Encode(event, "company_renamed")
+				assert.Error(t, err)
+			})
+
+			t.Run("Json envelope", func(t *testing.T) {
+				event, err := NewCompanyEvent(CompanyCreated, companyID, nil, company)
+				require.NoError(t, err)
+				msg, err := event.Message("company_created")
+				require.NoError(t, err)
+
+				decoded, err := decoder.Decode(ctx, msg.Value)
+				require.NoError(t, err)
+				assert.Equal(t, event.ID, decoded.ID)
+			})
+		})
+	}
+}
+
+func TestNewSchemaEncoder_Incompatible(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	registry := newFileRegistry(t)
+	_, err := registry.Register(ctx, schemaregistry.ValueSubject("company_created"),
+		schemaregistry.TypeProtobuf, `syntax = "proto3";
+package companies.events.v1;
+message CompanyEvent {
+  string id = 1;
+  bool type = 2;
+}`)
+	require.NoError(t, err)
+
+	_, err = NewSchemaEncoder(ctx, ModeProtobuf, registry, []string{"company_created"})
+	assert.ErrorIs(t, err, schemaregistry.ErrIncompatibleSchema)
+
+	_, err = NewSchemaEncoder(ctx, ModeBinary, registry, []string{"company_created"})
+	assert.Error(t, err)
+}
+
+func TestProtobufCodec_MissingFields(t *testing.T) {
+	t.Parallel()
+
+	codec, err := newSchemaCodec(context.Background(), schemaregistry.TypeProtobuf,
+		protobufSchemaV0)
+	require.NoError(t, err)
+
+	companyID := uuid.New()
+	event, err := NewCompanyEvent(CompanyCreated, companyID, nil, Company{
+		CompanyID: companyID, CompanyName: "Test Company", AmountOfEmployees: 10,
+		Registered: true, CompanyType: "NonProfit",
+	})
+	require.NoError(t, err)
+
+	payload, err := codec.encode(event)
+	require.NoError(t, err)
+
+	decoded, err := codec.decode(payload)
+	require.NoError(t, err)
+	assert.Equal(t, 0, decoded.SchemaVersion)
+
+	company, err := decoded.CompanyPayload()
+	require.NoError(t, err)
+	assert.Equal(t, Company{CompanyID: companyID, CompanyName: "Test Company",
+		CompanyType: "NonProfit"}, *company)
+}
+
+func TestDecoder_WithoutRegistry(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewDecoder(nil).Decode(context.Background(),
+		schemaregistry.AppendHeader(nil, 1))
+	assert.Error(t, err)
+}
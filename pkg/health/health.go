@@ -0,0 +1,118 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Check of a dependency
+type Check struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// Result of a dependency check
+type Result struct {
+	Name        string     `json:"name"`
+	Status      string     `json:"status"`
+	LatencyMs   float64    `json:"latency_ms"`
+	Error       string     `json:"error,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
+	CheckedAt   time.Time  `json:"checked_at"`
+}
+
+// Checker runs the dependency checks and caches their results,
+// so the probes of many callers do not hammer the dependencies
+type Checker struct {
+	checks    []Check
+	timeout   time.Duration
+	ttl       time.Duration
+	mu        sync.Mutex
+	results   []Result
+	checkedAt time.Time
+	// lastErrors keeps the latest failure of every check, even after it recovers
+	lastErrors map[string]Result
+}
+
+// Checker constructor
+func NewChecker(timeout, ttl time.Duration, checks ...Check) *Checker {
+	return &Checker{
+		checks:     checks,
+		timeout:    timeout,
+		ttl:        ttl,
+		lastErrors: make(map[string]Result, len(checks)),
+	}
+}
+
+// Check returns the results of the checks, running them when the cached results expired,
+// and whether all the dependencies are up
+func (c *Checker) Check() ([]Result, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.results == nil || time.Since(c.checkedAt) >= c.ttl {
+		c.results = c.run()
+		c.checkedAt = time.Now()
+	}
+
+	results := make([]Result, len(c.results))
+	copy(results, c.results)
+
+	healthy := true
+	for _, result := range results {
+		if result.Status != StatusUp {
+			healthy = false
+		}
+	}
+
+	return results, healthy
+}
+
+// run runs the checks concurrently, detached from the callers contexts
+func (c *Checker) run() []Result {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	results := make([]Result, len(c.checks))
+	var wg sync.WaitGroup
+	for i, check := range c.checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+
+			start := time.Now()
+			err := check.Check(ctx)
+			results[i] = Result{
+				Name:      check.Name,
+				Status:    StatusUp,
+				LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+				CheckedAt: start,
+			}
+			if err != nil {
+				results[i].Status = StatusDown
+				results[i].Error = err.Error()
+			}
+		}(i, check)
+	}
+	wg.Wait()
+
+	for i, result := range results {
+		if result.Error != "" {
+			checkedAt := result.CheckedAt
+			c.lastErrors[result.Name] = Result{LastError: result.Error, LastErrorAt: &checkedAt}
+		}
+		if lastError, ok := c.lastErrors[result.Name]; ok {
+			results[i].LastError = lastError.LastError
+			results[i].LastErrorAt = lastError.LastErrorAt
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,95 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecker_Check(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Aggregate status", func(t *testing.T) {
+		t.Parallel()
+
+		var redisDown atomic.Bool
+		redisDown.Store(true)
+		checker := NewChecker(time.Second, 0,
+			Check{Name: "postgres", Check: func(context.Context) error { return nil }},
+			Check{Name: "redis", Check: func(context.Context) error {
+				if redisDown.Load() {
+					return errors.New("connection refused")
+				}
+				return nil
+			}},
+		)
+
+		results, healthy := checker.Check()
+		assert.False(t, healthy)
+		require.Len(t, results, 2)
+		assert.Equal(t, "postgres", results[0].Name)
+		assert.Equal(t, StatusUp, results[0].Status)
+		assert.Empty(t, results[0].LastError)
+		assert.Equal(t, "redis", results[1].Name)
+		assert.Equal(t, StatusDown, results[1].Status)
+		assert.Equal(t, "connection refused", results[1].Error)
+
+		// The last error is kept after the dependency recovers
+		redisDown.Store(false)
+		results, healthy = checker.Check()
+		assert.True(t, healthy)
+		assert.Equal(t, StatusUp, results[1].Status)
+		assert.Empty(t, results[1].Error)
+		assert.Equal(t, "connection refused", results[1].LastError)
+		assert.NotNil(t, results[1].LastErrorAt)
+	})
+
+	t.Run("Cached results", func(t *testing.T) {
+		t.Parallel()
+
+		var calls atomic.Int32
+		checker := NewChecker(time.Second, 50*time.Millisecond,
+			Check{Name: "postgres", Check: func(context.Context) error {
+				calls.Add(1)
+				return nil
+			}})
+
+		for i := 0; i < 3; i++ {
+			_, healthy := checker.Check()
+			assert.True(t, healthy)
+		}
+		assert.Equal(t, int32(1), calls.Load())
+
+		// The results are copied, the callers can not change the cached ones
+		results, _ := checker.Check()
+		results[0].Status = StatusDown
+		_, healthy := checker.Check()
+		assert.True(t, healthy)
+
+		time.Sleep(60 * time.Millisecond)
+		checker.Check()
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		t.Parallel()
+
+		checker := NewChecker(50*time.Millisecond, time.Minute,
+			Check{Name: "kafka", Check: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			}})
+
+		start := time.Now()
+		results, healthy := checker.Check()
+		assert.Less(t, time.Since(start), time.Second)
+		assert.False(t, healthy)
+		assert.Equal(t, StatusDown, results[0].Status)
+		assert.Equal(t, context.DeadlineExceeded.Error(), results[0].Error)
+	})
+}
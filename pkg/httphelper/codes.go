@@ -0,0 +1,57 @@
+package httphelper
+
+import "net/http"
+
+// Stable machine readable error codes of the error responses,
+// clients must rely on them instead of the error messages
+const (
+	CodeBadRequest         = "bad_request"
+	CodeInvalidBody        = "invalid_body"
+	CodeInvalidID          = "invalid_id"
+	CodeValidationFailed   = "validation_failed"
+	CodeInvalidInput       = "invalid_input"
+	CodeUnauthorized       = "unauthorized"
+	CodeInvalidToken       = "invalid_token"
+	CodeWrongCredentials   = "wrong_credentials"
+	CodeForbidden          = "forbidden"
+	CodePermissionDenied   = "permission_denied"
+	CodeInvalidCSRFToken   = "invalid_csrf_token"
+	CodeNotFound           = "not_found"
+	CodeConflict           = "conflict"
+	CodeUserEmailExists    = "user_email_exists"
+	CodeCompanyNameExists  = "company_name_exists"
+	CodeReferenceNotFound  = "reference_not_found"
+	CodeRequestTimeout     = "request_timeout"
+	CodeTooManyRequests    = "too_many_requests"
+	CodeInternalError      = "internal_error"
+	CodeServiceUnavailable = "service_unavailable"
+)
+
+// codeFromStatus returns the generic code of a http status
+func codeFromStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusRequestTimeout:
+		return CodeRequestTimeout
+	case http.StatusTooManyRequests:
+		return CodeTooManyRequests
+	case http.StatusGatewayTimeout:
+		return CodeRequestTimeout
+	case http.StatusServiceUnavailable:
+		return CodeServiceUnavailable
+	default:
+		if status >= http.StatusBadRequest && status < http.StatusInternalServerError {
+			return CodeBadRequest
+		}
+		return CodeInternalError
+	}
+}
@@ -6,8 +6,14 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
-	"strings"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx"
+	"golang.org/x/crypto/bcrypt"
 )
 
 const (
@@ -16,25 +22,36 @@ const (
 )
 
 var (
-	ErrBadRequest            = errors.New("bad request")
-	ErrWrongCredentials      = errors.New("wrong credentials")
-	ErrNotFound              = errors.New("not found")
-	ErrUnauthorized          = errors.New("unauthorized")
-	ErrForbidden             = errors.New("forbidden")
-	ErrPermissionDenied      = errors.New("permission denied")
-	ErrNotRequiredFields     = errors.New("no such required fields")
-	ErrBadQueryParams        = errors.New("invalid query params")
-	ErrInternalServerError   = errors.New("internal server error")
-	ErrRequestTimeoutError   = errors.New("request timeout")
-	ErrExistsEmailError      = errors.New("user with given email already exists")
-	ErrInvalidJWTToken       = errors.New("invalid JWT token")
-	ErrInvalidJWTClaims      = errors.New("invalid JWT claims")
-	ErrNoCookie              = errors.New("not found cookie header")
+	ErrBadRequest             = errors.New("bad request")
+	ErrWrongCredentials       = errors.New("wrong credentials")
+	ErrNotFound               = errors.New("not found")
+	ErrUnauthorized           = errors.New("unauthorized")
+	ErrForbidden              = errors.New("forbidden")
+	ErrPermissionDenied       = errors.New("permission denied")
+	ErrNotRequiredFields      = errors.New("no such required fields")
+	ErrBadQueryParams         = errors.New("invalid query params")
+	ErrInternalServerError    = errors.New("internal server error")
+	ErrRequestTimeoutError    = errors.New("request timeout")
+	ErrExistsEmailError       = errors.New("user with given email already exists")
+	ErrInvalidJWTToken        = errors.New("invalid JWT token")
+	ErrInvalidJWTClaims       = errors.New("invalid JWT claims")
+	ErrNoCookie               = errors.New("not found cookie header")
+	ErrTooManyRequests        = errors.New("too many requests")
+	ErrConflict               = errors.New("conflict")
+	ErrExistsCompanyNameError = errors.New("company with given name already exists")
+	ErrInvalidBody            = errors.New("invalid request body")
+	ErrInvalidID              = errors.New("invalid id")
+	ErrValidation             = errors.New("validation failed")
+	ErrInvalidInput           = errors.New("invalid input")
+	ErrReferenceNotFound      = errors.New("referenced resource not found")
+	ErrInvalidCSRFToken       = errors.New("invalid CSRF token")
 )
 
 // Rest error interface
 type RestErr interface {
 	Status() int
+	Code() string
+	Message() string
 	Error() string
 	Causes() interface{}
 }
@@ -42,13 +59,15 @@ type RestErr interface {
 // Rest error struct
 type RestError struct {
 	ErrStatus int         `json:"status,omitempty"`
+	ErrCode   string      `json:"code,omitempty"`
 	ErrError  string      `json:"error,omitempty"`
 	ErrCauses interface{} `json:"-"`
 }
 
 // Error  Error() interface method
 func (e RestError) Error() string {
-	return fmt.Sprintf("status: %d - errors: %s - causes: %v", e.ErrStatus, e.ErrError, e.ErrCauses)
+	return fmt.Sprintf("status: %d - code: %s - errors: %s - causes: %v",
+		e.ErrStatus, e.ErrCode, e.ErrError, e.ErrCauses)
 }
 
 // Error status
@@ -56,15 +75,34 @@ func (e RestError) Status() int {
 	return e.ErrStatus
 }
 
+// Error code
+func (e RestError) Code() string {
+	return e.ErrCode
+}
+
+// Error message, safe to be returned to the client
+func (e RestError) Message() string {
+	return e.ErrError
+}
+
 // RestError Causes
 func (e RestError) Causes() interface{} {
 	return e.ErrCauses
 }
 
+// Unwrap returns the cause when it is an error
+func (e RestError) Unwrap() error {
+	if err, ok := e.ErrCauses.(error); ok {
+		return err
+	}
+	return nil
+}
+
 // New Rest Error
 func NewRestError(status int, err string, causes interface{}) RestErr {
 	return RestError{
 		ErrStatus: status,
+		ErrCode:   codeFromStatus(status),
 		ErrError:  err,
 		ErrCauses: causes,
 	}
@@ -74,6 +112,17 @@ func NewRestError(status int, err string, causes interface{}) RestErr {
 func NewRestErrorWithMessage(status int, err string, causes interface{}) RestErr {
 	return RestError{
 		ErrStatus: status,
+		ErrCode:   codeFromStatus(status),
+		ErrError:  err,
+		ErrCauses: causes,
+	}
+}
+
+// New Rest Error With Code
+func NewRestErrorWithCode(status int, code string, err string, causes interface{}) RestErr {
+	return RestError{
+		ErrStatus: status,
+		ErrCode:   code,
 		ErrError:  err,
 		ErrCauses: causes,
 	}
@@ -92,6 +141,7 @@ func NewRestErrorFromBytes(bytes []byte) (RestErr, error) {
 func NewBadRequestError(causes interface{}) RestErr {
 	return RestError{
 		ErrStatus: http.StatusBadRequest,
+		ErrCode:   CodeBadRequest,
 		ErrError:  ErrBadRequest.Error(),
 		ErrCauses: causes,
 	}
@@ -101,6 +151,7 @@ func NewBadRequestError(causes interface{}) RestErr {
 func NewNotFoundError(causes interface{}) RestErr {
 	return RestError{
 		ErrStatus: http.StatusNotFound,
+		ErrCode:   CodeNotFound,
 		ErrError:  ErrNotFound.Error(),
 		ErrCauses: causes,
 	}
@@ -110,6 +161,7 @@ func NewNotFoundError(causes interface{}) RestErr {
 func NewUnauthorizedError(causes interface{}) RestErr {
 	return RestError{
 		ErrStatus: http.StatusUnauthorized,
+		ErrCode:   CodeUnauthorized,
 		ErrError:  ErrUnauthorized.Error(),
 		ErrCauses: causes,
 	}
@@ -119,6 +171,7 @@ func NewUnauthorizedError(causes interface{}) RestErr {
 func NewForbiddenError(causes interface{}) RestErr {
 	return RestError{
 		ErrStatus: http.StatusForbidden,
+		ErrCode:   CodeForbidden,
 		ErrError:  ErrForbidden.Error(),
 		ErrCauses: causes,
 	}
@@ -128,59 +181,97 @@ func NewForbiddenError(causes interface{}) RestErr {
 func NewInternalServerError(causes interface{}) RestErr {
 	result := RestError{
 		ErrStatus: http.StatusInternalServerError,
+		ErrCode:   CodeInternalError,
 		ErrError:  ErrInternalServerError.Error(),
 		ErrCauses: causes,
 	}
 	return result
 }
 
-// Parser of error string messages returns RestError
-func ParseErrors(err error) RestErr {
-	switch {
-	case errors.Is(err, sql.ErrNoRows):
-		return NewRestError(http.StatusNotFound, ErrNotFound.Error(), err)
-	case errors.Is(err, context.DeadlineExceeded):
-		return NewRestError(http.StatusRequestTimeout, ErrRequestTimeoutError.Error(), err)
-	case strings.Contains(err.Error(), "SQLSTATE"):
-		return parseSqlErrors(err)
-	case strings.Contains(err.Error(), "Field validation"):
-		return parseValidatorError(err)
-	case strings.Contains(err.Error(), "Unmarshal"):
-		return NewRestError(http.StatusBadRequest, ErrBadRequest.Error(), err)
-	case strings.Contains(err.Error(), "UUID"):
-		return NewRestError(http.StatusBadRequest, err.Error(), err)
-	case strings.Contains(strings.ToLower(err.Error()), "cookie"):
-		return NewRestError(http.StatusUnauthorized, ErrUnauthorized.Error(), err)
-	case strings.Contains(strings.ToLower(err.Error()), "token"):
-		return NewRestError(http.StatusUnauthorized, ErrUnauthorized.Error(), err)
-	case strings.Contains(strings.ToLower(err.Error()), "bcrypt"):
-		return NewRestError(http.StatusBadRequest, ErrBadRequest.Error(), err)
-	default:
-		if restErr, ok := err.(RestErr); ok {
-			return restErr
-		}
-		return NewInternalServerError(err)
+// New Conflict Error
+func NewConflictError(causes interface{}) RestErr {
+	return RestError{
+		ErrStatus: http.StatusConflict,
+		ErrCode:   CodeConflict,
+		ErrError:  ErrConflict.Error(),
+		ErrCauses: causes,
 	}
 }
 
-func parseSqlErrors(err error) RestErr {
-	if strings.Contains(err.Error(), "23505") {
-		return NewRestError(http.StatusBadRequest, ErrExistsEmailError.Error(), err)
-	}
-
-	return NewRestError(http.StatusBadRequest, ErrBadRequest.Error(), err)
+// sentinelErrors maps the package errors returned as is to the rest errors
+var sentinelErrors = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{ErrBadRequest, http.StatusBadRequest, CodeBadRequest},
+	{ErrBadQueryParams, http.StatusBadRequest, CodeBadRequest},
+	{ErrNotRequiredFields, http.StatusBadRequest, CodeValidationFailed},
+	{ErrWrongCredentials, http.StatusUnauthorized, CodeWrongCredentials},
+	{ErrUnauthorized, http.StatusUnauthorized, CodeUnauthorized},
+	{ErrInvalidJWTToken, http.StatusUnauthorized, CodeInvalidToken},
+	{ErrInvalidJWTClaims, http.StatusUnauthorized, CodeInvalidToken},
+	{ErrNoCookie, http.StatusUnauthorized, CodeUnauthorized},
+	{ErrForbidden, http.StatusForbidden, CodeForbidden},
+	{ErrPermissionDenied, http.StatusForbidden, CodePermissionDenied},
+	{ErrInvalidCSRFToken, http.StatusForbidden, CodeInvalidCSRFToken},
+	{ErrNotFound, http.StatusNotFound, CodeNotFound},
+	{ErrExistsEmailError, http.StatusConflict, CodeUserEmailExists},
+	{ErrExistsCompanyNameError, http.StatusConflict, CodeCompanyNameExists},
+	{ErrConflict, http.StatusConflict, CodeConflict},
+	{ErrRequestTimeoutError, http.StatusGatewayTimeout, CodeRequestTimeout},
+	{ErrTooManyRequests, http.StatusTooManyRequests, CodeTooManyRequests},
 }
 
-func parseValidatorError(err error) RestErr {
-	if strings.Contains(err.Error(), "Password") {
-		return NewRestError(http.StatusBadRequest, "Invalid password, min length 6", err)
+// ParseErrors classifies an error by its type and returns the RestError
+func ParseErrors(err error) RestErr {
+	var restErr RestErr
+	if errors.As(err, &restErr) {
+		return restErr
 	}
 
-	if strings.Contains(err.Error(), "Email") {
-		return NewRestError(http.StatusBadRequest, "Invalid email", err)
+	for _, sentinel := range sentinelErrors {
+		if errors.Is(err, sentinel.err) {
+			return NewRestErrorWithCode(sentinel.status, sentinel.code, sentinel.err.Error(), err)
+		}
 	}
 
-	return NewRestError(http.StatusBadRequest, ErrBadRequest.Error(), err)
+	var (
+		pgErr            pgx.PgError
+		validationErrs   validator.ValidationErrors
+		syntaxErr        *json.SyntaxError
+		unmarshalTypeErr *json.UnmarshalTypeError
+		jwtErr           *jwt.ValidationError
+		netErr           net.Error
+	)
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return NewRestErrorWithCode(http.StatusNotFound, CodeNotFound, ErrNotFound.Error(), err)
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return NewRestErrorWithCode(http.StatusGatewayTimeout, CodeRequestTimeout,
+			ErrRequestTimeoutError.Error(), err)
+	case errors.As(err, &pgErr):
+		return parsePgError(pgErr, err)
+	case errors.As(err, &validationErrs):
+		return NewRestErrorWithCode(http.StatusBadRequest, CodeValidationFailed,
+			ErrValidation.Error(), err)
+	case errors.As(err, &syntaxErr), errors.As(err, &unmarshalTypeErr),
+		errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return NewRestErrorWithCode(http.StatusBadRequest, CodeInvalidBody, ErrInvalidBody.Error(), err)
+	case errors.As(err, &jwtErr):
+		return NewRestErrorWithCode(http.StatusUnauthorized, CodeInvalidToken,
+			ErrInvalidJWTToken.Error(), err)
+	case errors.Is(err, http.ErrNoCookie):
+		return NewRestErrorWithCode(http.StatusUnauthorized, CodeUnauthorized, ErrUnauthorized.Error(), err)
+	case errors.Is(err, bcrypt.ErrMismatchedHashAndPassword):
+		return NewRestErrorWithCode(http.StatusUnauthorized, CodeWrongCredentials,
+			ErrWrongCredentials.Error(), err)
+	case errors.Is(err, bcrypt.ErrPasswordTooLong):
+		return NewRestErrorWithCode(http.StatusBadRequest, CodeInvalidInput, ErrBadRequest.Error(), err)
+	default:
+		return NewInternalServerError(err)
+	}
 }
 
 // Error response
@@ -4,35 +4,33 @@ import (
 	"companies-service/config"
 	"companies-service/pkg/logger"
 	"companies-service/pkg/sanitize"
-	"context"
+	"companies-service/pkg/tracing"
 	"encoding/json"
+	"fmt"
 	"net/http"
-	"time"
 
+	"github.com/gin-contrib/requestid"
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
 )
 
+// GetRequestID returns the request id generated by the request id middleware
+// or the one sent by the client
 func GetRequestID(ctx *gin.Context) string {
-	// Get the request ID is in the headers
-	requestID := ctx.Request.Header.Get("X-Request-ID")
-
-	return requestID
-}
-
-// ReqIDCtxKey is a key used for the Request ID in context
-type ReqIDCtxKey struct{}
-
-// Get ctx with timeout and request id from echo context
-func GetCtxWithReqID(c *gin.Context) (context.Context, context.CancelFunc) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), time.Second*15)
-	ctx = context.WithValue(ctx, ReqIDCtxKey{}, GetRequestID(c))
-	return ctx, cancel
+	if requestID := requestid.Get(ctx); requestID != "" {
+		return requestID
+	}
+	return ctx.Request.Header.Get(tracing.RequestIDHeader)
 }
 
-// Get context  with request id
-func GetRequestCtx(c *gin.Context) context.Context {
-	return context.WithValue(c.Request.Context(), ReqIDCtxKey{}, GetRequestID(c))
+// ParseUUIDParam parses the uuid path param
+func ParseUUIDParam(c *gin.Context, name string) (uuid.UUID, error) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		return uuid.Nil, NewRestErrorWithCode(http.StatusBadRequest, CodeInvalidID,
+			fmt.Sprintf("%s: %s", ErrInvalidID.Error(), name), err)
+	}
+	return id, nil
 }
 
 // Configure jwt cookie
@@ -49,14 +47,25 @@ func ConfigureJWTCookie(cfg *config.Config, jwtToken string) *http.Cookie {
 	}
 }
 
+// Configure csrf cookie, it is readable by the client scripts which send it back in a header
+func ConfigureCSRFCookie(cfg *config.Config, csrfToken string) *http.Cookie {
+	return &http.Cookie{
+		Name:     cfg.CSRF.CookieName,
+		Value:    csrfToken,
+		Path:     "/",
+		MaxAge:   cfg.Cookie.MaxAge,
+		Secure:   cfg.Cookie.Secure,
+		HttpOnly: false,
+		SameSite: http.SameSiteStrictMode,
+	}
+}
+
 // ReadRequest gets body and validate
 func ReadRequest(c *gin.Context, request interface{}) error {
 	if err := c.Bind(request); err != nil {
 		return err
 	}
 
-	validate := validator.New()
-
 	return validate.StructCtx(c, request)
 }
 
@@ -75,28 +84,25 @@ func SanitizeRequest(c *gin.Context, request interface{}) error {
 		return err
 	}
 
-	validate := validator.New()
-
 	return validate.StructCtx(c, request)
 }
 
-// ErrResponseWithLog writes error response with logging error for gin context
-func ErrResponseWithLog(c *gin.Context, logger logger.Logger, err error) {
-	logger.Errorf(
-		"ErrResponseWithLog, RequestID: %s, IPAddress: %s, Error: %s",
-		GetRequestID(c),
+// ErrResponseWithLog writes error response with logging error for gin context,
+// the error is logged by the request logger when there is one
+func ErrResponseWithLog(c *gin.Context, l logger.Logger, err error) {
+	logger.FromContext(c.Request.Context(), l).Errorf(
+		"ErrResponseWithLog, IPAddress: %s, Error: %s",
 		c.ClientIP(),
 		err,
 	)
 
-	c.JSON(ErrorResponse(err))
+	ProblemResponse(c, err)
 }
 
 // LogResponseError logs the error response with logging error for gin context
-func LogResponseError(ctx *gin.Context, logger logger.Logger, err error) {
-	logger.Errorf(
-		"ErrResponseWithLog, RequestID: %s, IPAddress: %s, Error: %s",
-		GetRequestID(ctx),
+func LogResponseError(ctx *gin.Context, l logger.Logger, err error) {
+	logger.FromContext(ctx.Request.Context(), l).Errorf(
+		"ErrResponseWithLog, IPAddress: %s, Error: %s",
 		ctx.ClientIP(),
 		err,
 	)
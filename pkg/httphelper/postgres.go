@@ -0,0 +1,57 @@
+package httphelper
+
+import (
+	"net/http"
+
+	"github.com/jackc/pgx"
+)
+
+// Postgres error codes, https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	pgUniqueViolation           = "23505"
+	pgForeignKeyViolation       = "23503"
+	pgNotNullViolation          = "23502"
+	pgCheckViolation            = "23514"
+	pgInvalidTextRepresentation = "22P02"
+	pgStringDataRightTruncation = "22001"
+	pgNumericValueOutOfRange    = "22003"
+	pgInvalidDatetimeFormat     = "22007"
+	pgDatetimeFieldOverflow     = "22008"
+	pgQueryCanceled             = "57014"
+)
+
+// pgConstraintErrors maps the constraint names of the schema to the rest errors,
+// unknown constraints fall back to the generic error of the postgres error code
+var pgConstraintErrors = map[string]struct {
+	status int
+	code   string
+	err    error
+}{
+	"users_email_key":            {http.StatusConflict, CodeUserEmailExists, ErrExistsEmailError},
+	"companies_company_name_key": {http.StatusConflict, CodeCompanyNameExists, ErrExistsCompanyNameError},
+}
+
+// parsePgError returns the RestError of a postgres error by its code and constraint name
+func parsePgError(pgErr pgx.PgError, err error) RestErr {
+	if constraintErr, ok := pgConstraintErrors[pgErr.ConstraintName]; ok {
+		return NewRestErrorWithCode(constraintErr.status, constraintErr.code,
+			constraintErr.err.Error(), err)
+	}
+
+	switch pgErr.Code {
+	case pgUniqueViolation:
+		return NewRestErrorWithCode(http.StatusConflict, CodeConflict, ErrConflict.Error(), err)
+	case pgForeignKeyViolation:
+		return NewRestErrorWithCode(http.StatusConflict, CodeReferenceNotFound,
+			ErrReferenceNotFound.Error(), err)
+	case pgNotNullViolation, pgCheckViolation, pgInvalidTextRepresentation,
+		pgStringDataRightTruncation, pgNumericValueOutOfRange,
+		pgInvalidDatetimeFormat, pgDatetimeFieldOverflow:
+		return NewRestErrorWithCode(http.StatusBadRequest, CodeInvalidInput, ErrInvalidInput.Error(), err)
+	case pgQueryCanceled:
+		return NewRestErrorWithCode(http.StatusGatewayTimeout, CodeRequestTimeout,
+			ErrRequestTimeoutError.Error(), err)
+	default:
+		return NewInternalServerError(err)
+	}
+}
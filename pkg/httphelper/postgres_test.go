@@ -0,0 +1,71 @@
+package httphelper
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/jackc/pgx"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseErrors_Postgres(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		pgErr      pgx.PgError
+		wantStatus int
+		wantCode   string
+		wantError  error
+	}{
+		{name: "Unique violation", pgErr: pgx.PgError{Code: pgUniqueViolation,
+			ConstraintName: "other_key"},
+			wantStatus: http.StatusConflict, wantCode: CodeConflict, wantError: ErrConflict},
+		{name: "Unique email", pgErr: pgx.PgError{Code: pgUniqueViolation,
+			ConstraintName: "users_email_key"},
+			wantStatus: http.StatusConflict, wantCode: CodeUserEmailExists,
+			wantError: ErrExistsEmailError},
+		{name: "Unique company name", pgErr: pgx.PgError{Code: pgUniqueViolation,
+			ConstraintName: "companies_company_name_key"},
+			wantStatus: http.StatusConflict, wantCode: CodeCompanyNameExists,
+			wantError: ErrExistsCompanyNameError},
+		{name: "Foreign key violation", pgErr: pgx.PgError{Code: pgForeignKeyViolation,
+			ConstraintName: "login_events_user_id_fkey"},
+			wantStatus: http.StatusConflict, wantCode: CodeReferenceNotFound,
+			wantError: ErrReferenceNotFound},
+		{name: "Check violation", pgErr: pgx.PgError{Code: pgCheckViolation,
+			ConstraintName: "companies_amount_of_employees_check"},
+			wantStatus: http.StatusBadRequest, wantCode: CodeInvalidInput,
+			wantError: ErrInvalidInput},
+		{name: "Not null violation", pgErr: pgx.PgError{Code: pgNotNullViolation,
+			ColumnName: "company_name"},
+			wantStatus: http.StatusBadRequest, wantCode: CodeInvalidInput,
+			wantError: ErrInvalidInput},
+		{name: "Invalid text representation", pgErr: pgx.PgError{
+			Code: pgInvalidTextRepresentation},
+			wantStatus: http.StatusBadRequest, wantCode: CodeInvalidInput,
+			wantError: ErrInvalidInput},
+		{name: "String data right truncation", pgErr: pgx.PgError{
+			Code: pgStringDataRightTruncation},
+			wantStatus: http.StatusBadRequest, wantCode: CodeInvalidInput,
+			wantError: ErrInvalidInput},
+		{name: "Query canceled", pgErr: pgx.PgError{Code: pgQueryCanceled},
+			wantStatus: http.StatusGatewayTimeout, wantCode: CodeRequestTimeout,
+			wantError: ErrRequestTimeoutError},
+		{name: "Unknown code", pgErr: pgx.PgError{Code: "40P01"},
+			wantStatus: http.StatusInternalServerError, wantCode: CodeInternalError,
+			wantError: ErrInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The repositories wrap the errors of the driver
+			restErr := ParseErrors(errors.Wrap(tt.pgErr, "companiesRepo.Create.QueryRowxContext"))
+
+			assert.Equal(t, tt.wantStatus, restErr.Status())
+			assert.Equal(t, tt.wantCode, restErr.Code())
+			assert.Equal(t, tt.wantError.Error(), restErr.Message())
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package httphelper
+
+import (
+	"companies-service/pkg/tracing"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// ProblemContentType of the error responses, RFC 7807
+	ProblemContentType = "application/problem+json"
+	problemTypePrefix  = "urn:companies-service:problem:"
+)
+
+// ProblemDetails error response body, RFC 7807
+type ProblemDetails struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	Code      string `json:"code"`
+	RequestID string `json:"request_id,omitempty"`
+	TraceID   string `json:"trace_id,omitempty"`
+	// Errors of the invalid fields of a validation error
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// NewProblemDetails builds the problem details of the RestError for the request,
+// the details of server errors are never exposed
+func NewProblemDetails(c *gin.Context, restErr RestErr) *ProblemDetails {
+	detail := restErr.Message()
+	if restErr.Status() >= http.StatusInternalServerError {
+		detail = ErrInternalServerError.Error()
+	}
+
+	trans := Translator(c.GetHeader("Accept-Language"))
+
+	return &ProblemDetails{
+		Type:      problemTypePrefix + restErr.Code(),
+		Title:     http.StatusText(restErr.Status()),
+		Status:    restErr.Status(),
+		Detail:    detail,
+		Instance:  c.Request.URL.Path,
+		Code:      restErr.Code(),
+		RequestID: GetRequestID(c),
+		TraceID:   tracing.TraceIDFromContext(c.Request.Context()),
+		Errors:    NewFieldErrors(restErr, trans),
+	}
+}
+
+// ProblemResponse writes the problem details response of the error
+func ProblemResponse(c *gin.Context, err error) {
+	restErr := ParseErrors(err)
+	c.Header("Content-Type", ProblemContentType)
+	c.JSON(restErr.Status(), NewProblemDetails(c, restErr))
+}
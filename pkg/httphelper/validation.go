@@ -0,0 +1,121 @@
+package httphelper
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
+	"github.com/go-playground/locales/fr"
+	"github.com/go-playground/locales/ru"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	esTranslations "github.com/go-playground/validator/v10/translations/es"
+	frTranslations "github.com/go-playground/validator/v10/translations/fr"
+	ruTranslations "github.com/go-playground/validator/v10/translations/ru"
+	"golang.org/x/text/language"
+)
+
+// FieldError of a request validation error
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// translations registers the validator messages of a locale
+type translations struct {
+	locale   locales.Translator
+	register func(v *validator.Validate, trans ut.Translator) error
+}
+
+var (
+	validate   *validator.Validate
+	translator *ut.UniversalTranslator
+)
+
+func init() {
+	validate = validator.New()
+	// Report the fields by the json names the clients send
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+
+	supported := []translations{
+		{en.New(), enTranslations.RegisterDefaultTranslations},
+		{es.New(), esTranslations.RegisterDefaultTranslations},
+		{fr.New(), frTranslations.RegisterDefaultTranslations},
+		{ru.New(), ruTranslations.RegisterDefaultTranslations},
+	}
+
+	localeTranslators := make([]locales.Translator, 0, len(supported))
+	for _, t := range supported {
+		localeTranslators = append(localeTranslators, t.locale)
+	}
+	// The first locale is the fallback
+	translator = ut.New(localeTranslators[0], localeTranslators...)
+
+	for _, t := range supported {
+		trans, _ := translator.GetTranslator(t.locale.Locale())
+		if err := t.register(validate, trans); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Translator returns the translator of the best supported language of the Accept-Language header
+func Translator(acceptLanguage string) ut.Translator {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil {
+		return translator.GetFallback()
+	}
+
+	bases := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		base, _ := tag.Base()
+		bases = append(bases, base.String())
+	}
+
+	trans, _ := translator.FindTranslator(bases...)
+	return trans
+}
+
+// NewFieldErrors translates the validation errors of the error,
+// nil when the error is not a validation error
+func NewFieldErrors(err error, trans ut.Translator) []FieldError {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return nil
+	}
+
+	fieldErrors := make([]FieldError, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		fieldErrors = append(fieldErrors, FieldError{
+			Field:   fieldPath(fe.Namespace()),
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: fe.Translate(trans),
+		})
+	}
+
+	return fieldErrors
+}
+
+// fieldPath strips the struct name from the namespace of the field, User.first_name -> first_name
+func fieldPath(namespace string) string {
+	if idx := strings.Index(namespace, "."); idx >= 0 {
+		return namespace[idx+1:]
+	}
+	return namespace
+}
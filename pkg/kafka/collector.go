@@ -0,0 +1,106 @@
+package kafka
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/segmentio/kafka-go"
+)
+
+// writerStatsCollector exports the stats of a kafka writer on every scrape. The writer
+// resets its counters when its stats are read, so the collector keeps the totals
+type writerStatsCollector struct {
+	stats func() kafka.WriterStats
+
+	mu       sync.Mutex
+	writes   int64
+	messages int64
+	bytes    int64
+	errors   int64
+	retries  int64
+
+	writesDesc     *prometheus.Desc
+	messagesDesc   *prometheus.Desc
+	bytesDesc      *prometheus.Desc
+	errorsDesc     *prometheus.Desc
+	retriesDesc    *prometheus.Desc
+	queueTimeDesc  *prometheus.Desc
+	writeTimeDesc  *prometheus.Desc
+	batchSizeDesc  *prometheus.Desc
+	maxAttemptDesc *prometheus.Desc
+}
+
+// NewWriterStatsCollector returns the collector of the writer stats, the batch queue time
+// shows the writer saturation. The time and size gauges are measured since the last scrape
+func NewWriterStatsCollector(name string, stats func() kafka.WriterStats) prometheus.Collector {
+	return &writerStatsCollector{
+		stats: stats,
+		writesDesc: prometheus.NewDesc(name+"_kafka_writer_writes_total",
+			"Writes of the kafka writer", nil, nil),
+		messagesDesc: prometheus.NewDesc(name+"_kafka_writer_messages_total",
+			"Messages written by the kafka writer", nil, nil),
+		bytesDesc: prometheus.NewDesc(name+"_kafka_writer_message_bytes_total",
+			"Message bytes written by the kafka writer", nil, nil),
+		errorsDesc: prometheus.NewDesc(name+"_kafka_writer_errors_total",
+			"Errors of the kafka writer", nil, nil),
+		retriesDesc: prometheus.NewDesc(name+"_kafka_writer_retries_total",
+			"Write retries of the kafka writer", nil, nil),
+		queueTimeDesc: prometheus.NewDesc(name+"_kafka_writer_batch_queue_seconds",
+			"Time the batches of the kafka writer waited in the queue", []string{"stat"}, nil),
+		writeTimeDesc: prometheus.NewDesc(name+"_kafka_writer_write_seconds",
+			"Time the writes of the kafka writer took", []string{"stat"}, nil),
+		batchSizeDesc: prometheus.NewDesc(name+"_kafka_writer_batch_size",
+			"Messages per batch of the kafka writer", []string{"stat"}, nil),
+		maxAttemptDesc: prometheus.NewDesc(name+"_kafka_writer_max_attempts",
+			"Max write attempts of the kafka writer", nil, nil),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *writerStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.writesDesc
+	ch <- c.messagesDesc
+	ch <- c.bytesDesc
+	ch <- c.errorsDesc
+	ch <- c.retriesDesc
+	ch <- c.queueTimeDesc
+	ch <- c.writeTimeDesc
+	ch <- c.batchSizeDesc
+	ch <- c.maxAttemptDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *writerStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats()
+	c.writes += stats.Writes
+	c.messages += stats.Messages
+	c.bytes += stats.Bytes
+	c.errors += stats.Errors
+	c.retries += stats.Retries
+
+	ch <- prometheus.MustNewConstMetric(c.writesDesc, prometheus.CounterValue, float64(c.writes))
+	ch <- prometheus.MustNewConstMetric(c.messagesDesc, prometheus.CounterValue,
+		float64(c.messages))
+	ch <- prometheus.MustNewConstMetric(c.bytesDesc, prometheus.CounterValue, float64(c.bytes))
+	ch <- prometheus.MustNewConstMetric(c.errorsDesc, prometheus.CounterValue, float64(c.errors))
+	ch <- prometheus.MustNewConstMetric(c.retriesDesc, prometheus.CounterValue,
+		float64(c.retries))
+
+	ch <- prometheus.MustNewConstMetric(c.queueTimeDesc, prometheus.GaugeValue,
+		stats.BatchQueueTime.Avg.Seconds(), "avg")
+	ch <- prometheus.MustNewConstMetric(c.queueTimeDesc, prometheus.GaugeValue,
+		stats.BatchQueueTime.Max.Seconds(), "max")
+	ch <- prometheus.MustNewConstMetric(c.writeTimeDesc, prometheus.GaugeValue,
+		stats.WriteTime.Avg.Seconds(), "avg")
+	ch <- prometheus.MustNewConstMetric(c.writeTimeDesc, prometheus.GaugeValue,
+		stats.WriteTime.Max.Seconds(), "max")
+	ch <- prometheus.MustNewConstMetric(c.batchSizeDesc, prometheus.GaugeValue,
+		float64(stats.BatchSize.Avg), "avg")
+	ch <- prometheus.MustNewConstMetric(c.batchSizeDesc, prometheus.GaugeValue,
+		float64(stats.BatchSize.Max), "max")
+	ch <- prometheus.MustNewConstMetric(c.maxAttemptDesc, prometheus.GaugeValue,
+		float64(stats.MaxAttempts))
+}
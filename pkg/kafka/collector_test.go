@@ -0,0 +1,74 @@
+package kafka
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriterStatsCollector(t *testing.T) {
+	t.Parallel()
+
+	// The writer stats are reset on every read, each read returns the writes since the last one
+	reads := []kafka.WriterStats{
+		{Writes: 2, Messages: 10, Bytes: 1000, Errors: 1, Retries: 1, MaxAttempts: 3,
+			BatchSize: kafka.SummaryStats{Avg: 5, Max: 8},
+			WriteTime: kafka.DurationStats{Avg: 10 * time.Millisecond, Max: 20 * time.Millisecond}},
+		{Writes: 3, Messages: 5, Bytes: 500, MaxAttempts: 3,
+			BatchSize: kafka.SummaryStats{Avg: 2, Max: 3}},
+	}
+	collector := NewWriterStatsCollector("test", func() kafka.WriterStats {
+		stats := reads[0]
+		reads = reads[1:]
+		return stats
+	})
+
+	require.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(`
+# HELP test_kafka_writer_writes_total Writes of the kafka writer
+# TYPE test_kafka_writer_writes_total counter
+test_kafka_writer_writes_total 2
+# HELP test_kafka_writer_messages_total Messages written by the kafka writer
+# TYPE test_kafka_writer_messages_total counter
+test_kafka_writer_messages_total 10
+# HELP test_kafka_writer_errors_total Errors of the kafka writer
+# TYPE test_kafka_writer_errors_total counter
+test_kafka_writer_errors_total 1
+# HELP test_kafka_writer_batch_size Messages per batch of the kafka writer
+# TYPE test_kafka_writer_batch_size gauge
+test_kafka_writer_batch_size{stat="avg"} 5
+test_kafka_writer_batch_size{stat="max"} 8
+`), "test_kafka_writer_writes_total", "test_kafka_writer_messages_total",
+		"test_kafka_writer_errors_total", "test_kafka_writer_batch_size"))
+
+	// The counters accumulate the reads, the gauges are the stats of the last read
+	require.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(`
+# HELP test_kafka_writer_writes_total Writes of the kafka writer
+# TYPE test_kafka_writer_writes_total counter
+test_kafka_writer_writes_total 5
+# HELP test_kafka_writer_messages_total Messages written by the kafka writer
+# TYPE test_kafka_writer_messages_total counter
+test_kafka_writer_messages_total 15
+# HELP test_kafka_writer_message_bytes_total Message bytes written by the kafka writer
+# TYPE test_kafka_writer_message_bytes_total counter
+test_kafka_writer_message_bytes_total 1500
+# HELP test_kafka_writer_errors_total Errors of the kafka writer
+# TYPE test_kafka_writer_errors_total counter
+test_kafka_writer_errors_total 1
+# HELP test_kafka_writer_batch_size Messages per batch of the kafka writer
+# TYPE test_kafka_writer_batch_size gauge
+test_kafka_writer_batch_size{stat="avg"} 2
+test_kafka_writer_batch_size{stat="max"} 3
+# HELP test_kafka_writer_write_seconds Time the writes of the kafka writer took
+# TYPE test_kafka_writer_write_seconds gauge
+test_kafka_writer_write_seconds{stat="avg"} 0
+test_kafka_writer_write_seconds{stat="max"} 0
+`), "test_kafka_writer_writes_total", "test_kafka_writer_messages_total",
+		"test_kafka_writer_message_bytes_total", "test_kafka_writer_errors_total",
+		"test_kafka_writer_batch_size", "test_kafka_writer_write_seconds"))
+
+	require.Empty(t, reads)
+}
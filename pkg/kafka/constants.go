@@ -17,4 +17,10 @@ const (
 	writerWriteTimeout = 10 * time.Second
 	writerRequiredAcks = -1
 	writerMaxAttempts  = 3
+
+	consumerPoolSize       = 1
+	handlerMaxAttempts     = 3
+	handlerRetryBackoff    = 500 * time.Millisecond
+	fetchRetryBackoff      = 1 * time.Second
+	deadLetterRetryBackoff = 1 * time.Second
 )
\ No newline at end of file
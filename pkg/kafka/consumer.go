@@ -0,0 +1,252 @@
+package kafka
+
+import (
+	"companies-service/pkg/logger"
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/segmentio/kafka-go"
+)
+
+// ErrInvalidMessage is returned by the handlers of the messages that can not be decoded,
+// they are not retried
+var ErrInvalidMessage = errors.New("invalid kafka message")
+
+// Handler processes a message of a topic
+type Handler func(ctx context.Context, msg kafka.Message) error
+
+// Middleware wraps the handlers of the consumer
+type Middleware func(next Handler) Handler
+
+// TypedHandler returns the handler of the messages decoded with decode, the decoding errors
+// are returned as ErrInvalidMessage
+func TypedHandler[T any](
+	decode func(ctx context.Context, msg kafka.Message) (T, error),
+	handle func(ctx context.Context, msg kafka.Message, value T) error,
+) Handler {
+	return func(ctx context.Context, msg kafka.Message) error {
+		value, err := decode(ctx, msg)
+		if err != nil {
+			return errors.Wrapf(ErrInvalidMessage, "%s: %s", msg.Topic, err)
+		}
+		return handle(ctx, msg, value)
+	}
+}
+
+// JSONHandler returns the handler of the messages with a json value
+func JSONHandler[T any](handle func(ctx context.Context, msg kafka.Message, value *T) error) Handler {
+	return TypedHandler(func(_ context.Context, msg kafka.Message) (*T, error) {
+		var value T
+		if err := json.Unmarshal(msg.Value, &value); err != nil {
+			return nil, err
+		}
+		return &value, nil
+	}, handle)
+}
+
+// Dead letter headers, they tell where the failed message was consumed from and why it failed
+const (
+	DeadLetterTopicHeader     = "dead-letter-topic"
+	DeadLetterPartitionHeader = "dead-letter-partition"
+	DeadLetterOffsetHeader    = "dead-letter-offset"
+	DeadLetterErrorHeader     = "dead-letter-error"
+)
+
+// Publisher publishes messages, the dead letters of the consumer are published with it
+type Publisher interface {
+	PublishMessage(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// messageReader fetches and commits the messages of the consumer group, it is implemented by
+// the kafka reader
+type messageReader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// Consumer dispatches the messages of a consumer group to the handlers of their topic.
+// A message is committed once its handler returned, a failed message is retried and then
+// published to the dead letter topic before it is committed so that it does not block its
+// partition. A single worker handles the messages, so they are handled and committed in the
+// order of their partition
+type Consumer struct {
+	group           ConsumerGroup
+	deadLetters     Publisher
+	deadLetterTopic string
+	handlers        map[string]Handler
+	middlewares     []Middleware
+	log             logger.Logger
+}
+
+// NewConsumer kafka consumer constructor, the failed messages are published to the dead
+// letter topic with the publisher
+func NewConsumer(
+	group ConsumerGroup, deadLetters Publisher, deadLetterTopic string, log logger.Logger,
+) *Consumer {
+	return &Consumer{group: group, deadLetters: deadLetters, deadLetterTopic: deadLetterTopic,
+		handlers: map[string]Handler{}, log: log}
+}
+
+// Use adds middlewares to the handlers, the first middleware is the outermost one
+func (c *Consumer) Use(middlewares ...Middleware) {
+	c.middlewares = append(c.middlewares, middlewares...)
+}
+
+// Handle registers the handler of a topic
+func (c *Consumer) Handle(topic string, handler Handler) {
+	c.handlers[topic] = handler
+}
+
+// Topics returns the topics with a handler
+func (c *Consumer) Topics() []string {
+	topics := make([]string, 0, len(c.handlers))
+	for topic := range c.handlers {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+// Run consumes the topics until the context is done, the worker finishes and commits the
+// message it is handling before it returns
+func (c *Consumer) Run(ctx context.Context) {
+	if len(c.handlers) == 0 {
+		c.log.Warn("Kafka consumer has no handlers")
+		return
+	}
+
+	for topic, handler := range c.handlers {
+		for i := len(c.middlewares) - 1; i >= 0; i-- {
+			handler = c.middlewares[i](handler)
+		}
+		c.handlers[topic] = handler
+	}
+
+	// The workers of a group share its reader, a single worker commits the offsets in order
+	c.group.ConsumeTopic(ctx, c.Topics(), consumerPoolSize, c.ProcessMessages)
+}
+
+// ProcessMessages fetches, handles and commits the messages until the context is done,
+// it implements MessageProcessor
+func (c *Consumer) ProcessMessages(
+	ctx context.Context, r *kafka.Reader, wg *sync.WaitGroup, workerID int,
+) {
+	defer wg.Done()
+	c.consume(ctx, r, workerID)
+}
+
+func (c *Consumer) consume(ctx context.Context, r messageReader, workerID int) {
+	for {
+		msg, err := r.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, io.EOF) {
+				return
+			}
+			c.log.Errorf("Kafka consumer worker %d FetchMessage: %s", workerID, err)
+			if !sleep(ctx, fetchRetryBackoff) {
+				return
+			}
+			continue
+		}
+
+		if !c.process(ctx, r, msg, workerID) {
+			return
+		}
+	}
+}
+
+// process handles and commits a message, it returns false when the consumer stopped before
+// the message was handled or dead lettered, the message is then fetched again by the group
+func (c *Consumer) process(
+	ctx context.Context, r messageReader, msg kafka.Message, workerID int,
+) bool {
+	// The fetched message is handled and committed even when the consumer is stopping,
+	// only the retries are interrupted
+	msgCtx := context.WithoutCancel(ctx)
+	if err := c.handle(ctx, msgCtx, msg); err != nil {
+		if ctx.Err() != nil {
+			c.log.Warnf("Kafka consumer worker %d stopped before handling message %s/%d/%d: %s",
+				workerID, msg.Topic, msg.Partition, msg.Offset, err)
+			return false
+		}
+		if !c.deadLetter(ctx, msg, err, workerID) {
+			return false
+		}
+	}
+
+	if err := r.CommitMessages(msgCtx, msg); err != nil {
+		c.log.Errorf("Kafka consumer worker %d CommitMessages: %s", workerID, err)
+	}
+	return true
+}
+
+// handle runs the handler of the message, the failures are retried with a linear backoff
+// except the invalid messages. The retries stop when ctx is done
+func (c *Consumer) handle(ctx, msgCtx context.Context, msg kafka.Message) error {
+	handler, ok := c.handlers[msg.Topic]
+	if !ok {
+		return errors.Errorf("no handler for topic %s", msg.Topic)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := handler(msgCtx, msg)
+		if err == nil || errors.Is(err, ErrInvalidMessage) || attempt == handlerMaxAttempts {
+			return err
+		}
+		if !sleep(ctx, time.Duration(attempt)*handlerRetryBackoff) {
+			return err
+		}
+	}
+}
+
+// deadLetter publishes the failed message to the dead letter topic, the publishing is
+// retried until it succeeds or ctx is done. It returns whether the message was published
+func (c *Consumer) deadLetter(
+	ctx context.Context, msg kafka.Message, handlerErr error, workerID int,
+) bool {
+	c.log.Errorf("Kafka consumer worker %d failed message %s/%d/%d: %s", workerID,
+		msg.Topic, msg.Partition, msg.Offset, handlerErr)
+
+	headers := append(make([]kafka.Header, 0, len(msg.Headers)+4), msg.Headers...)
+	headers = append(headers,
+		kafka.Header{Key: DeadLetterTopicHeader, Value: []byte(msg.Topic)},
+		kafka.Header{Key: DeadLetterPartitionHeader,
+			Value: []byte(strconv.Itoa(msg.Partition))},
+		kafka.Header{Key: DeadLetterOffsetHeader,
+			Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+		kafka.Header{Key: DeadLetterErrorHeader, Value: []byte(handlerErr.Error())},
+	)
+	deadLetter := kafka.Message{Topic: c.deadLetterTopic, Key: msg.Key, Value: msg.Value,
+		Headers: headers}
+
+	for {
+		err := c.deadLetters.PublishMessage(context.WithoutCancel(ctx), deadLetter)
+		if err == nil {
+			return true
+		}
+		c.log.Errorf("Kafka consumer worker %d PublishMessage dead letter %s/%d/%d: %s",
+			workerID, msg.Topic, msg.Partition, msg.Offset, err)
+		if !sleep(ctx, deadLetterRetryBackoff) {
+			return false
+		}
+	}
+}
+
+// sleep waits for the duration, it returns false when ctx is done before
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -17,11 +17,11 @@ type MessageProcessor interface {
 // Worker kafka consumer worker fetch and process messages from reader
 type Worker func(ctx context.Context, r *kafka.Reader, wg *sync.WaitGroup, workerID int)
 
+// ConsumerGroup consumes the topics of a kafka consumer group with a pool of workers
 type ConsumerGroup interface {
-	ConsumeTopic(ctx context.Context, cancel context.CancelFunc, groupID, topic string,
-		poolSize int, worker Worker)
-	GetNewKafkaReader(kafkaURL []string, topic, groupID string) *kafka.Reader
-	GetNewKafkaWriter(topic string) *kafka.Writer
+	ConsumeTopic(ctx context.Context, groupTopics []string, poolSize int, worker Worker)
+	GetNewKafkaReader(kafkaURL []string, groupTopics []string, groupID string) *kafka.Reader
+	GetNewKafkaWriter() *kafka.Writer
 }
 
 type consumerGroup struct {
@@ -31,11 +31,11 @@ type consumerGroup struct {
 }
 
 // NewConsumerGroup kafka consumer group constructor
-func NewConsumerGroup(brokers []string, groupID string, log logger.Logger) *consumerGroup {
+func NewConsumerGroup(brokers []string, groupID string, log logger.Logger) ConsumerGroup {
 	return &consumerGroup{Brokers: brokers, GroupID: groupID, log: log}
 }
 
-// GetNewKafkaReader create new kafka reader
+// GetNewKafkaReader create new kafka reader, the offsets are committed explicitly
 func (c *consumerGroup) GetNewKafkaReader(kafkaURL []string, groupTopics []string,
 	groupID string) *kafka.Reader {
 	return kafka.NewReader(kafka.ReaderConfig{
@@ -50,6 +50,7 @@ func (c *consumerGroup) GetNewKafkaReader(kafkaURL []string, groupTopics []strin
 		PartitionWatchInterval: partitionWatchInterval,
 		MaxAttempts:            maxAttempts,
 		MaxWait:                maxWait,
+		ErrorLogger:            kafka.LoggerFunc(c.log.Errorf),
 		Dialer: &kafka.Dialer{
 			Timeout: dialTimeout,
 		},
@@ -71,7 +72,8 @@ func (c *consumerGroup) GetNewKafkaWriter() *kafka.Writer {
 	return w
 }
 
-// ConsumeTopic start consumer group with given worker and pool size
+// ConsumeTopic start consumer group with given worker and pool size, it returns once the
+// workers returned and closes the reader
 func (c *consumerGroup) ConsumeTopic(ctx context.Context, groupTopics []string,
 	poolSize int, worker Worker) {
 	r := c.GetNewKafkaReader(c.Brokers, groupTopics, c.GroupID)
@@ -86,7 +88,7 @@ func (c *consumerGroup) ConsumeTopic(ctx context.Context, groupTopics []string,
 		groupTopics, poolSize)
 
 	wg := &sync.WaitGroup{}
-	for i := 0; i <= poolSize; i++ {
+	for i := 0; i < poolSize; i++ {
 		wg.Add(1)
 		go worker(ctx, r, wg, i)
 	}
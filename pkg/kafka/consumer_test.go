@@ -0,0 +1,391 @@
+package kafka
+
+import (
+	"companies-service/config"
+	"companies-service/pkg/logger"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLogger() logger.Logger {
+	apiLogger := logger.NewApiLogger(&config.Config{
+		Logger: config.Logger{Level: "error", Encoding: "console"},
+	})
+	apiLogger.InitLogger()
+	return apiLogger
+}
+
+// fakeReader fetches its messages and then blocks until the context is done
+type fakeReader struct {
+	mu       sync.Mutex
+	messages []kafka.Message
+	commits  []kafka.Message
+}
+
+func (r *fakeReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	r.mu.Lock()
+	if len(r.messages) > 0 {
+		msg := r.messages[0]
+		r.messages = r.messages[1:]
+		r.mu.Unlock()
+		return msg, nil
+	}
+	r.mu.Unlock()
+
+	<-ctx.Done()
+	return kafka.Message{}, ctx.Err()
+}
+
+func (r *fakeReader) CommitMessages(_ context.Context, msgs ...kafka.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.commits = append(r.commits, msgs...)
+	return nil
+}
+
+func (r *fakeReader) committed() []kafka.Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]kafka.Message(nil), r.commits...)
+}
+
+// fakePublisher records the published messages, publish may fail them
+type fakePublisher struct {
+	mu       sync.Mutex
+	messages []kafka.Message
+	publish  func(msg kafka.Message) error
+}
+
+func (p *fakePublisher) PublishMessage(_ context.Context, msgs ...kafka.Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, msg := range msgs {
+		if p.publish != nil {
+			if err := p.publish(msg); err != nil {
+				return err
+			}
+		}
+		p.messages = append(p.messages, msg)
+	}
+	return nil
+}
+
+func (p *fakePublisher) published() []kafka.Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]kafka.Message(nil), p.messages...)
+}
+
+// fakeGroup records the topics and pool size of the consumer
+type fakeGroup struct {
+	topics   []string
+	poolSize int
+}
+
+func (g *fakeGroup) ConsumeTopic(
+	_ context.Context, groupTopics []string, poolSize int, _ Worker,
+) {
+	g.topics = groupTopics
+	g.poolSize = poolSize
+}
+
+func (g *fakeGroup) GetNewKafkaReader([]string, []string, string) *kafka.Reader {
+	return nil
+}
+
+func (g *fakeGroup) GetNewKafkaWriter() *kafka.Writer {
+	return nil
+}
+
+// consumeAll consumes the messages until the reader committed count messages
+func consumeAll(t *testing.T, c *Consumer, reader *fakeReader, count int) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.consume(ctx, reader, 0)
+	}()
+
+	require.Eventually(t, func() bool { return len(reader.committed()) >= count },
+		5*time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+}
+
+func TestConsumer_Run(t *testing.T) {
+	t.Parallel()
+
+	group := &fakeGroup{}
+	consumer := NewConsumer(group, &fakePublisher{}, "dead_letters", newTestLogger())
+
+	var calls []string
+	middleware := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, msg kafka.Message) error {
+				calls = append(calls, name+" before")
+				err := next(ctx, msg)
+				calls = append(calls, name+" after")
+				return err
+			}
+		}
+	}
+	consumer.Use(middleware("outer"), middleware("inner"))
+	consumer.Handle("company_updated", func(context.Context, kafka.Message) error {
+		calls = append(calls, "handler")
+		return nil
+	})
+	consumer.Handle("company_created", func(context.Context, kafka.Message) error {
+		return nil
+	})
+
+	consumer.Run(context.Background())
+	assert.Equal(t, []string{"company_created", "company_updated"}, group.topics)
+	assert.Equal(t, 1, group.poolSize)
+
+	reader := &fakeReader{messages: []kafka.Message{{Topic: "company_updated"}}}
+	consumeAll(t, consumer, reader, 1)
+	assert.Equal(t, []string{"outer before", "inner before", "handler", "inner after",
+		"outer after"}, calls)
+}
+
+func TestConsumer_RunWithoutHandlers(t *testing.T) {
+	t.Parallel()
+
+	group := &fakeGroup{}
+	NewConsumer(group, &fakePublisher{}, "dead_letters", newTestLogger()).
+		Run(context.Background())
+	assert.Nil(t, group.topics)
+}
+
+func TestConsumer_Consume(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Handled messages committed in order", func(t *testing.T) {
+		t.Parallel()
+
+		publisher := &fakePublisher{}
+		consumer := NewConsumer(nil, publisher, "dead_letters", newTestLogger())
+		var handled []int64
+		consumer.Handle("company_updated", func(_ context.Context, msg kafka.Message) error {
+			handled = append(handled, msg.Offset)
+			return nil
+		})
+
+		reader := &fakeReader{messages: []kafka.Message{
+			{Topic: "company_updated", Offset: 1},
+			{Topic: "company_updated", Offset: 2},
+			{Topic: "company_updated", Offset: 3},
+		}}
+		consumeAll(t, consumer, reader, 3)
+
+		assert.Equal(t, []int64{1, 2, 3}, handled)
+		commits := reader.committed()
+		require.Len(t, commits, 3)
+		for i, msg := range commits {
+			assert.Equal(t, int64(i+1), msg.Offset)
+		}
+		assert.Empty(t, publisher.published())
+	})
+
+	t.Run("Failed message retried then dead lettered", func(t *testing.T) {
+		t.Parallel()
+
+		publisher := &fakePublisher{}
+		consumer := NewConsumer(nil, publisher, "dead_letters", newTestLogger())
+		attempts := 0
+		consumer.Handle("company_updated", func(context.Context, kafka.Message) error {
+			attempts++
+			return errors.New("handler error")
+		})
+
+		msg := kafka.Message{Topic: "company_updated", Partition: 2, Offset: 7,
+			Key: []byte("key"), Value: []byte("value"),
+			Headers: []kafka.Header{{Key: "X-Request-ID", Value: []byte("request")}}}
+		reader := &fakeReader{messages: []kafka.Message{msg}}
+		consumeAll(t, consumer, reader, 1)
+
+		assert.Equal(t, handlerMaxAttempts, attempts)
+
+		deadLetters := publisher.published()
+		require.Len(t, deadLetters, 1)
+		deadLetter := deadLetters[0]
+		assert.Equal(t, "dead_letters", deadLetter.Topic)
+		assert.Equal(t, msg.Key, deadLetter.Key)
+		assert.Equal(t, msg.Value, deadLetter.Value)
+		assert.Equal(t, "request", headerValue(deadLetter.Headers, "X-Request-ID"))
+		assert.Equal(t, "company_updated", headerValue(deadLetter.Headers, DeadLetterTopicHeader))
+		assert.Equal(t, "2", headerValue(deadLetter.Headers, DeadLetterPartitionHeader))
+		assert.Equal(t, "7", headerValue(deadLetter.Headers, DeadLetterOffsetHeader))
+		assert.Equal(t, "handler error", headerValue(deadLetter.Headers, DeadLetterErrorHeader))
+
+		assert.Equal(t, []kafka.Message{msg}, reader.committed())
+	})
+
+	t.Run("Invalid message not retried", func(t *testing.T) {
+		t.Parallel()
+
+		publisher := &fakePublisher{}
+		consumer := NewConsumer(nil, publisher, "dead_letters", newTestLogger())
+		attempts := 0
+		consumer.Handle("company_updated", func(context.Context, kafka.Message) error {
+			attempts++
+			return errors.Wrap(ErrInvalidMessage, "bad json")
+		})
+
+		reader := &fakeReader{messages: []kafka.Message{{Topic: "company_updated"}}}
+		consumeAll(t, consumer, reader, 1)
+
+		assert.Equal(t, 1, attempts)
+		assert.Len(t, publisher.published(), 1)
+	})
+
+	t.Run("Retried message succeeds", func(t *testing.T) {
+		t.Parallel()
+
+		publisher := &fakePublisher{}
+		consumer := NewConsumer(nil, publisher, "dead_letters", newTestLogger())
+		attempts := 0
+		consumer.Handle("company_updated", func(context.Context, kafka.Message) error {
+			attempts++
+			if attempts == 1 {
+				return errors.New("transient error")
+			}
+			return nil
+		})
+
+		reader := &fakeReader{messages: []kafka.Message{{Topic: "company_updated"}}}
+		consumeAll(t, consumer, reader, 1)
+
+		assert.Equal(t, 2, attempts)
+		assert.Empty(t, publisher.published())
+	})
+
+	t.Run("Topic without handler dead lettered", func(t *testing.T) {
+		t.Parallel()
+
+		publisher := &fakePublisher{}
+		consumer := NewConsumer(nil, publisher, "dead_letters", newTestLogger())
+
+		reader := &fakeReader{messages: []kafka.Message{{Topic: "company_renamed"}}}
+		consumeAll(t, consumer, reader, 1)
+
+		assert.Len(t, publisher.published(), 1)
+	})
+
+	t.Run("Not committed when the dead letter fails", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		publisher := &fakePublisher{publish: func(kafka.Message) error {
+			cancel()
+			return errors.New("kafka is down")
+		}}
+		consumer := NewConsumer(nil, publisher, "dead_letters", newTestLogger())
+		consumer.Handle("company_updated", func(context.Context, kafka.Message) error {
+			return errors.Wrap(ErrInvalidMessage, "bad json")
+		})
+
+		reader := &fakeReader{messages: []kafka.Message{
+			{Topic: "company_updated", Offset: 1},
+			{Topic: "company_updated", Offset: 2},
+		}}
+		consumer.consume(ctx, reader, 0)
+
+		assert.Empty(t, reader.committed())
+		assert.Empty(t, publisher.published())
+	})
+
+	t.Run("Retries interrupted by the shutdown", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		publisher := &fakePublisher{}
+		consumer := NewConsumer(nil, publisher, "dead_letters", newTestLogger())
+		attempts := 0
+		consumer.Handle("company_updated", func(ctx context.Context, _ kafka.Message) error {
+			attempts++
+			cancel()
+			// the handler context is not canceled by the shutdown
+			assert.NoError(t, ctx.Err())
+			return errors.New("handler error")
+		})
+
+		reader := &fakeReader{messages: []kafka.Message{{Topic: "company_updated"}}}
+		start := time.Now()
+		consumer.consume(ctx, reader, 0)
+
+		assert.Less(t, time.Since(start), handlerRetryBackoff)
+		assert.Equal(t, 1, attempts)
+		assert.Empty(t, reader.committed())
+		assert.Empty(t, publisher.published())
+	})
+}
+
+func TestConsumer_ProcessMessages(t *testing.T) {
+	t.Parallel()
+
+	reader := kafka.NewReader(kafka.ReaderConfig{Brokers: []string{"127.0.0.1:1"},
+		Topic: "company_updated"})
+	defer reader.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	NewConsumer(nil, &fakePublisher{}, "dead_letters", newTestLogger()).
+		ProcessMessages(ctx, reader, wg, 0)
+	wg.Wait()
+}
+
+func TestTypedHandler(t *testing.T) {
+	t.Parallel()
+
+	decode := func(_ context.Context, msg kafka.Message) (string, error) {
+		if len(msg.Value) == 0 {
+			return "", errors.New("empty value")
+		}
+		return string(msg.Value), nil
+	}
+	var handled string
+	handler := TypedHandler(decode, func(_ context.Context, _ kafka.Message, value string) error {
+		handled = value
+		return nil
+	})
+
+	require.NoError(t, handler(context.Background(), kafka.Message{Value: []byte("company")}))
+	assert.Equal(t, "company", handled)
+
+	err := handler(context.Background(), kafka.Message{Topic: "company_updated"})
+	assert.ErrorIs(t, err, ErrInvalidMessage)
+	assert.ErrorContains(t, err, "empty value")
+}
+
+func TestJSONHandler(t *testing.T) {
+	t.Parallel()
+
+	type company struct {
+		CompanyName string `json:"company_name"`
+	}
+	handlerErr := errors.New("handler error")
+	handler := JSONHandler(func(_ context.Context, _ kafka.Message, value *company) error {
+		if value.CompanyName == "" {
+			return handlerErr
+		}
+		return nil
+	})
+
+	ctx := context.Background()
+	assert.NoError(t, handler(ctx, kafka.Message{Value: []byte(`{"company_name": "Apple"}`)}))
+	assert.ErrorIs(t, handler(ctx, kafka.Message{Value: []byte(`{}`)}), handlerErr)
+	assert.ErrorIs(t, handler(ctx, kafka.Message{Value: []byte(`{`)}), ErrInvalidMessage)
+}
@@ -0,0 +1,114 @@
+package kafka
+
+import (
+	"companies-service/pkg/logger"
+	"companies-service/pkg/metric"
+	"companies-service/pkg/tracing"
+	"context"
+	"runtime/debug"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// TracingMiddleware continues the trace of the producer of the message in a consumer span
+// and stores the request id of the message in the context
+func TracingMiddleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg kafka.Message) error {
+			ctx, span := tracing.StartKafkaConsumerTracerSpan(ctx, msg.Headers,
+				"kafkaConsumer."+msg.Topic)
+			defer span.End()
+
+			span.SetAttributes(
+				attribute.String("messaging.destination.name", msg.Topic),
+				attribute.Int("messaging.kafka.destination.partition", msg.Partition),
+				attribute.Int64("messaging.kafka.message.offset", msg.Offset),
+			)
+
+			if requestID := headerValue(msg.Headers, tracing.RequestIDHeader); requestID != "" {
+				ctx = tracing.ContextWithRequestID(ctx, requestID)
+			}
+
+			err := next(ctx, msg)
+			if err != nil {
+				tracing.RecordError(span, err)
+			}
+			return err
+		}
+	}
+}
+
+// LoggingMiddleware stores a child logger of the message in the context and logs the
+// failed messages, must be used after the tracing middleware
+func LoggingMiddleware(log logger.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg kafka.Message) error {
+			msgLogger := log.With(
+				"request_id", tracing.RequestIDFromContext(ctx),
+				"trace_id", tracing.TraceIDFromContext(ctx),
+				"span_id", tracing.SpanIDFromContext(ctx),
+				"topic", msg.Topic,
+				"partition", msg.Partition,
+				"offset", msg.Offset,
+			)
+
+			start := time.Now()
+			err := next(logger.NewContext(ctx, msgLogger), msg)
+			if err != nil {
+				msgLogger.Errorw("Kafka message failed", "duration", time.Since(start),
+					"error", err)
+				return err
+			}
+			msgLogger.Debugf("Kafka message handled in %s", time.Since(start))
+			return nil
+		}
+	}
+}
+
+// MetricsMiddleware observes the handling latency of the messages by topic and result
+func MetricsMiddleware(metrics metric.ConsumerMetrics) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg kafka.Message) error {
+			start := time.Now()
+			err := next(ctx, msg)
+
+			if metrics != nil {
+				result := metric.ConsumeSuccess
+				if err != nil {
+					result = metric.ConsumeFailure
+				}
+				metrics.ObserveKafkaConsumed(msg.Topic, result, time.Since(start).Seconds())
+			}
+			return err
+		}
+	}
+}
+
+// RecoveryMiddleware turns the panics of the handlers into errors, the stack is logged
+func RecoveryMiddleware(log logger.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg kafka.Message) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					logger.FromContext(ctx, log).Errorw("Kafka handler panic recovered",
+						"panic", r, "stack", string(debug.Stack()))
+					err = errors.Errorf("kafka handler panic: %v", r)
+				}
+			}()
+			return next(ctx, msg)
+		}
+	}
+}
+
+// headerValue returns the value of the message header, empty when it is missing
+func headerValue(headers []kafka.Header, key string) string {
+	for _, header := range headers {
+		if header.Key == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
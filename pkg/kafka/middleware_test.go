@@ -0,0 +1,84 @@
+package kafka
+
+import (
+	"companies-service/pkg/logger"
+	"companies-service/pkg/tracing"
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConsumerMetrics records the observed results by topic
+type fakeConsumerMetrics struct {
+	results map[string][]string
+}
+
+func (m *fakeConsumerMetrics) ObserveKafkaConsumed(topic, result string, _ float64) {
+	m.results[topic] = append(m.results[topic], result)
+}
+
+func TestRecoveryMiddleware(t *testing.T) {
+	t.Parallel()
+
+	handler := RecoveryMiddleware(newTestLogger())(func(context.Context, kafka.Message) error {
+		panic("handler failure")
+	})
+
+	var err error
+	require.NotPanics(t, func() {
+		err = handler(context.Background(), kafka.Message{Topic: "company_updated"})
+	})
+	assert.ErrorContains(t, err, "handler failure")
+
+	handlerErr := errors.New("handler error")
+	handler = RecoveryMiddleware(newTestLogger())(func(context.Context, kafka.Message) error {
+		return handlerErr
+	})
+	assert.ErrorIs(t, handler(context.Background(), kafka.Message{}), handlerErr)
+}
+
+func TestMetricsMiddleware(t *testing.T) {
+	t.Parallel()
+
+	metrics := &fakeConsumerMetrics{results: map[string][]string{}}
+	handlerErr := errors.New("handler error")
+	handler := MetricsMiddleware(metrics)(func(_ context.Context, msg kafka.Message) error {
+		if len(msg.Value) == 0 {
+			return handlerErr
+		}
+		return nil
+	})
+
+	ctx := context.Background()
+	assert.NoError(t, handler(ctx, kafka.Message{Topic: "company_updated", Value: []byte("v")}))
+	assert.ErrorIs(t, handler(ctx, kafka.Message{Topic: "company_updated"}), handlerErr)
+	assert.Equal(t, []string{"success", "failure"}, metrics.results["company_updated"])
+
+	// The metrics are optional
+	assert.NoError(t, MetricsMiddleware(nil)(func(context.Context, kafka.Message) error {
+		return nil
+	})(ctx, kafka.Message{}))
+}
+
+func TestTracingAndLoggingMiddleware(t *testing.T) {
+	t.Parallel()
+
+	var requestID string
+	var msgLogger logger.Logger
+	handler := TracingMiddleware()(LoggingMiddleware(newTestLogger())(
+		func(ctx context.Context, _ kafka.Message) error {
+			requestID = tracing.RequestIDFromContext(ctx)
+			msgLogger = logger.FromContext(ctx, nil)
+			return nil
+		}))
+
+	err := handler(context.Background(), kafka.Message{Topic: "company_updated",
+		Headers: []kafka.Header{{Key: tracing.RequestIDHeader, Value: []byte("request")}}})
+	require.NoError(t, err)
+	assert.Equal(t, "request", requestID)
+	assert.NotNil(t, msgLogger)
+}
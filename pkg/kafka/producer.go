@@ -2,7 +2,10 @@ package kafka
 
 import (
 	"companies-service/pkg/logger"
+	"companies-service/pkg/metric"
+	"companies-service/pkg/tracing"
 	"context"
+	"errors"
 
 	"github.com/segmentio/kafka-go"
 )
@@ -16,16 +19,56 @@ type producer struct {
 	log     logger.Logger
 	brokers []string
 	w       *kafka.Writer
+	metrics metric.BusinessMetrics
 }
 
-// NewProducer create new kafka producer
-func NewProducer(log logger.Logger, brokers []string) *producer {
+// NewProducer create new kafka producer, the published messages are counted by topic
+func NewProducer(log logger.Logger, brokers []string, metrics metric.BusinessMetrics) *producer {
 	return &producer{log: log, brokers: brokers,
-		w: NewWriter(brokers, kafka.LoggerFunc(log.Errorf))}
+		w: NewWriter(brokers, kafka.LoggerFunc(log.Errorf)), metrics: metrics}
 }
 
+// PublishMessage writes the messages, they carry the request id of the context
 func (p *producer) PublishMessage(ctx context.Context, msgs ...kafka.Message) error {
-	return p.w.WriteMessages(ctx, msgs...)
+	for i := range msgs {
+		tracing.SetKafkaRequestIDHeader(ctx, &msgs[i])
+	}
+
+	err := p.w.WriteMessages(ctx, msgs...)
+	p.countPublished(msgs, err)
+	return err
+}
+
+// Stats returns the writer stats since the last call
+func (p *producer) Stats() kafka.WriterStats {
+	return p.w.Stats()
+}
+
+// countPublished counts the messages by topic, the failed ones of a partial write are told
+// apart by the write errors
+func (p *producer) countPublished(msgs []kafka.Message, err error) {
+	if p.metrics == nil {
+		return
+	}
+
+	var writeErrors kafka.WriteErrors
+	partial := errors.As(err, &writeErrors) && len(writeErrors) == len(msgs)
+	for i, msg := range msgs {
+		topic := msg.Topic
+		if topic == "" {
+			topic = p.w.Topic
+		}
+
+		failed := err != nil
+		if partial {
+			failed = writeErrors[i] != nil
+		}
+		if failed {
+			p.metrics.IncKafkaPublished(topic, metric.PublishFailure, 1)
+		} else {
+			p.metrics.IncKafkaPublished(topic, metric.PublishSuccess, 1)
+		}
+	}
 }
 
 func (p *producer) Close() error {
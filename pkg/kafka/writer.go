@@ -5,11 +5,12 @@ import (
 	"github.com/segmentio/kafka-go/compress"
 )
 
-// NewWriter create new configured kafka writer
+// NewWriter create new configured kafka writer, the messages are partitioned by key
+// like the java clients do so that the messages of a key keep their order
 func NewWriter(brokers []string, errLogger kafka.Logger) *kafka.Writer {
 	w := &kafka.Writer{
 		Addr:         kafka.TCP(brokers...),
-		Balancer:     &kafka.LeastBytes{},
+		Balancer:     &kafka.Murmur2Balancer{},
 		RequiredAcks: writerRequiredAcks,
 		MaxAttempts:  writerMaxAttempts,
 		ErrorLogger:  errLogger,
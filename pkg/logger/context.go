@@ -0,0 +1,28 @@
+package logger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+type loggerCtxKey struct{}
+
+// NewContext returns a copy of the context carrying the request scoped logger
+func NewContext(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, loggerCtxKey{}, l)
+}
+
+// FromContext returns the logger of the context, the fallback when there is none.
+// A nil fallback is replaced with a logger discarding everything.
+func FromContext(ctx context.Context, fallback Logger) Logger {
+	if l, ok := ctx.Value(loggerCtxKey{}).(Logger); ok {
+		return l
+	}
+	if fallback != nil {
+		return fallback
+	}
+	return nopLogger
+}
+
+var nopLogger Logger = &apiLogger{sugarLogger: zap.NewNop().Sugar(), level: zap.NewAtomicLevel()}
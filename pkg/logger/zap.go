@@ -2,6 +2,7 @@ package logger
 
 import (
 	"companies-service/config"
+	"fmt"
 	"os"
 
 	"go.uber.org/zap"
@@ -15,25 +16,32 @@ type Logger interface {
 	Debugf(template string, args ...interface{})
 	Info(args ...interface{})
 	Infof(template string, args ...interface{})
+	Infow(msg string, keysAndValues ...interface{})
 	Warn(args ...interface{})
 	Warnf(template string, args ...interface{})
+	Warnw(msg string, keysAndValues ...interface{})
 	Error(args ...interface{})
 	Errorf(template string, args ...interface{})
+	Errorw(msg string, keysAndValues ...interface{})
 	DPanic(args ...interface{})
 	DPanicf(template string, args ...interface{})
 	Fatal(args ...interface{})
 	Fatalf(template string, args ...interface{})
+	With(keysAndValues ...interface{}) Logger
+	Level() string
+	SetLevel(level string) error
 }
 
 // Logger
 type apiLogger struct {
 	cfg         *config.Config
 	sugarLogger *zap.SugaredLogger
+	level       zap.AtomicLevel
 }
 
 // App Logger constructor
 func NewApiLogger(cfg *config.Config) *apiLogger {
-	return &apiLogger{cfg: cfg}
+	return &apiLogger{cfg: cfg, level: zap.NewAtomicLevel()}
 }
 
 // For mapping config logger to app logger levels
@@ -83,7 +91,8 @@ func (l *apiLogger) InitLogger() {
 	}
 
 	encoderCfg.EncodeTime = zapcore.ISO8601TimeEncoder
-	core := zapcore.NewCore(encoder, logWriter, zap.NewAtomicLevelAt(logLevel))
+	l.level.SetLevel(logLevel)
+	core := zapcore.NewCore(encoder, logWriter, l.level)
 	logger := zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))
 
 	l.sugarLogger = logger.Sugar()
@@ -94,6 +103,11 @@ func (l *apiLogger) InitLogger() {
 
 // Logger methods
 
+// With returns a child logger adding the key value pairs to every line
+func (l *apiLogger) With(keysAndValues ...interface{}) Logger {
+	return &apiLogger{cfg: l.cfg, sugarLogger: l.sugarLogger.With(keysAndValues...), level: l.level}
+}
+
 func (l *apiLogger) Debug(args ...interface{}) {
 	l.sugarLogger.Debug(args...)
 }
@@ -110,6 +124,10 @@ func (l *apiLogger) Infof(template string, args ...interface{}) {
 	l.sugarLogger.Infof(template, args...)
 }
 
+func (l *apiLogger) Infow(msg string, keysAndValues ...interface{}) {
+	l.sugarLogger.Infow(msg, keysAndValues...)
+}
+
 func (l *apiLogger) Warn(args ...interface{}) {
 	l.sugarLogger.Warn(args...)
 }
@@ -118,6 +136,10 @@ func (l *apiLogger) Warnf(template string, args ...interface{}) {
 	l.sugarLogger.Warnf(template, args...)
 }
 
+func (l *apiLogger) Warnw(msg string, keysAndValues ...interface{}) {
+	l.sugarLogger.Warnw(msg, keysAndValues...)
+}
+
 func (l *apiLogger) Error(args ...interface{}) {
 	l.sugarLogger.Error(args...)
 }
@@ -126,6 +148,10 @@ func (l *apiLogger) Errorf(template string, args ...interface{}) {
 	l.sugarLogger.Errorf(template, args...)
 }
 
+func (l *apiLogger) Errorw(msg string, keysAndValues ...interface{}) {
+	l.sugarLogger.Errorw(msg, keysAndValues...)
+}
+
 func (l *apiLogger) DPanic(args ...interface{}) {
 	l.sugarLogger.DPanic(args...)
 }
@@ -149,3 +175,19 @@ func (l *apiLogger) Fatal(args ...interface{}) {
 func (l *apiLogger) Fatalf(template string, args ...interface{}) {
 	l.sugarLogger.Fatalf(template, args...)
 }
+
+// Level returns the current logger level
+func (l *apiLogger) Level() string {
+	return l.level.Level().String()
+}
+
+// SetLevel changes the logger level at runtime
+func (l *apiLogger) SetLevel(level string) error {
+	logLevel, exist := loggerLevelMap[level]
+	if !exist {
+		return fmt.Errorf("unknown logger level: %s", level)
+	}
+
+	l.level.SetLevel(logLevel)
+	return nil
+}
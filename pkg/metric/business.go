@@ -0,0 +1,117 @@
+package metric
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Company events
+const (
+	CompanyCreated = "created"
+	CompanyUpdated = "updated"
+	CompanyDeleted = "deleted"
+)
+
+// Registration and login outcomes
+const (
+	OutcomeSuccess         = "success"
+	OutcomeConflict        = "conflict"
+	OutcomeUserNotFound    = "user_not_found"
+	OutcomeInvalidPassword = "invalid_password"
+	OutcomeError           = "error"
+)
+
+// Kafka publish results
+const (
+	PublishSuccess = "success"
+	PublishFailure = "failure"
+)
+
+// Business Metrics interface
+type BusinessMetrics interface {
+	IncCompanies(event, companyType string)
+	IncRegistrations(outcome string)
+	IncLogins(outcome string)
+	IncKafkaPublished(topic, result string, messages int)
+}
+
+// Prometheus Business Metrics struct
+type PrometheusBusinessMetrics struct {
+	Companies      *prometheus.CounterVec
+	Registrations  *prometheus.CounterVec
+	Logins         *prometheus.CounterVec
+	KafkaPublished *prometheus.CounterVec
+}
+
+// Create business metrics with name
+func CreateBusinessMetrics(reg prometheus.Registerer, name string) (BusinessMetrics, error) {
+	var metr PrometheusBusinessMetrics
+	metr.Companies = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: name + "_companies_total",
+			Help: "Companies created, updated and deleted by company type",
+		},
+		[]string{"event", "company_type"},
+	)
+
+	if err := reg.Register(metr.Companies); err != nil {
+		return nil, err
+	}
+
+	metr.Registrations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: name + "_registrations_total",
+			Help: "User registrations by outcome",
+		},
+		[]string{"outcome"},
+	)
+
+	if err := reg.Register(metr.Registrations); err != nil {
+		return nil, err
+	}
+
+	metr.Logins = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: name + "_logins_total",
+			Help: "Login attempts by outcome",
+		},
+		[]string{"outcome"},
+	)
+
+	if err := reg.Register(metr.Logins); err != nil {
+		return nil, err
+	}
+
+	metr.KafkaPublished = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: name + "_kafka_published_messages_total",
+			Help: "Kafka messages published by topic and result",
+		},
+		[]string{"topic", "result"},
+	)
+
+	if err := reg.Register(metr.KafkaPublished); err != nil {
+		return nil, err
+	}
+
+	return &metr, nil
+}
+
+// IncCompanies
+func (metr *PrometheusBusinessMetrics) IncCompanies(event, companyType string) {
+	metr.Companies.WithLabelValues(event, companyType).Inc()
+}
+
+// IncRegistrations
+func (metr *PrometheusBusinessMetrics) IncRegistrations(outcome string) {
+	metr.Registrations.WithLabelValues(outcome).Inc()
+}
+
+// IncLogins
+func (metr *PrometheusBusinessMetrics) IncLogins(outcome string) {
+	metr.Logins.WithLabelValues(outcome).Inc()
+}
+
+// IncKafkaPublished
+func (metr *PrometheusBusinessMetrics) IncKafkaPublished(topic, result string, messages int) {
+	metr.KafkaPublished.WithLabelValues(topic, result).Add(float64(messages))
+}
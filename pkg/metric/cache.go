@@ -0,0 +1,64 @@
+package metric
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Cache results
+const (
+	cacheHit         = "hit"
+	cacheMiss        = "miss"
+	cacheNegativeHit = "negative_hit"
+	cacheError       = "error"
+)
+
+// Cache Metrics interface
+type CacheMetrics interface {
+	IncCacheHits(cache string)
+	IncCacheMisses(cache string)
+	IncCacheNegativeHits(cache string)
+	IncCacheErrors(cache string)
+}
+
+// Prometheus Cache Metrics struct
+type PrometheusCacheMetrics struct {
+	Requests *prometheus.CounterVec
+}
+
+// Create cache metrics with name
+func CreateCacheMetrics(reg prometheus.Registerer, name string) (CacheMetrics, error) {
+	var metr PrometheusCacheMetrics
+	metr.Requests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: name + "_cache_requests_total",
+			Help: "Cache lookups by cache and result",
+		},
+		[]string{"cache", "result"},
+	)
+
+	if err := reg.Register(metr.Requests); err != nil {
+		return nil, err
+	}
+
+	return &metr, nil
+}
+
+// IncCacheHits
+func (metr *PrometheusCacheMetrics) IncCacheHits(cache string) {
+	metr.Requests.WithLabelValues(cache, cacheHit).Inc()
+}
+
+// IncCacheMisses
+func (metr *PrometheusCacheMetrics) IncCacheMisses(cache string) {
+	metr.Requests.WithLabelValues(cache, cacheMiss).Inc()
+}
+
+// IncCacheNegativeHits
+func (metr *PrometheusCacheMetrics) IncCacheNegativeHits(cache string) {
+	metr.Requests.WithLabelValues(cache, cacheNegativeHit).Inc()
+}
+
+// IncCacheErrors
+func (metr *PrometheusCacheMetrics) IncCacheErrors(cache string) {
+	metr.Requests.WithLabelValues(cache, cacheError).Inc()
+}
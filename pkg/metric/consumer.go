@@ -0,0 +1,50 @@
+package metric
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Kafka consume results
+const (
+	ConsumeSuccess = "success"
+	ConsumeFailure = "failure"
+)
+
+// consumerBuckets are the latency buckets of the kafka message handlers, in seconds
+var consumerBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Consumer Metrics interface
+type ConsumerMetrics interface {
+	ObserveKafkaConsumed(topic, result string, observeTime float64)
+}
+
+// Prometheus Consumer Metrics struct
+type PrometheusConsumerMetrics struct {
+	Consumed *prometheus.HistogramVec
+}
+
+// Create kafka consumer metrics with name
+func CreateConsumerMetrics(reg prometheus.Registerer, name string) (ConsumerMetrics, error) {
+	var metr PrometheusConsumerMetrics
+	metr.Consumed = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    name + "_kafka_consumed_message_duration_seconds",
+			Help:    "Kafka message handling latency by topic and result",
+			Buckets: consumerBuckets,
+		},
+		[]string{"topic", "result"},
+	)
+
+	if err := reg.Register(metr.Consumed); err != nil {
+		return nil, err
+	}
+
+	return &metr, nil
+}
+
+// ObserveKafkaConsumed
+func (metr *PrometheusConsumerMetrics) ObserveKafkaConsumed(
+	topic, result string, observeTime float64,
+) {
+	metr.Consumed.WithLabelValues(topic, result).Observe(observeTime)
+}
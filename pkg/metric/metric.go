@@ -1,19 +1,26 @@
 package metric
 
 import (
-	"log"
 	"strconv"
 
-	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/collectors"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-// App Metrics interface
+var (
+	// responseTimeBuckets are the latency buckets of the http requests, in seconds
+	responseTimeBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+	// responseSizeBuckets are the body size buckets of the http responses, from 100B to 1.6MB
+	responseSizeBuckets = prometheus.ExponentialBuckets(100, 4, 8)
+)
+
+// App Metrics interface, the routes are the templates of the router so the series stay bounded
 type Metrics interface {
-	IncHits(status int, method, path string)
-	ObserveResponseTime(status int, method, path string, observeTime float64)
+	IncHits(status int, method, route string)
+	ObserveResponseTime(status int, method, route string, observeTime float64)
+	ObserveResponseSize(status int, method, route string, size int)
+	IncInFlight()
+	DecInFlight()
+	IncRateLimited(method, route, key string)
 }
 
 // Prometheus Metrics struct
@@ -21,66 +28,114 @@ type PrometheusMetrics struct {
 	HitsTotal prometheus.Counter
 	Hits      *prometheus.CounterVec
 	Times     *prometheus.HistogramVec
+	Sizes     *prometheus.HistogramVec
+	InFlight  prometheus.Gauge
+	Limited   *prometheus.CounterVec
 }
 
-// Create metrics with address and name
-func CreateMetrics(address string, name string) (Metrics, error) {
+// Create metrics with name on the registry served by the admin server
+func CreateMetrics(reg prometheus.Registerer, name string) (Metrics, error) {
 	var metr PrometheusMetrics
 	metr.HitsTotal = prometheus.NewCounter(prometheus.CounterOpts{
 		Name: name + "_hits_total",
+		Help: "Http requests served",
 	})
 
-	if err := prometheus.Register(metr.HitsTotal); err != nil {
+	if err := reg.Register(metr.HitsTotal); err != nil {
 		return nil, err
 	}
 
 	metr.Hits = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: name + "_hits",
+			Help: "Http requests served by status, method and route",
 		},
-		[]string{"status", "method", "path"},
+		[]string{"status", "method", "route"},
 	)
 
-	if err := prometheus.Register(metr.Hits); err != nil {
+	if err := reg.Register(metr.Hits); err != nil {
 		return nil, err
 	}
 
 	metr.Times = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
-			Name: name + "_times",
+			Name:    name + "_times",
+			Help:    "Http request latency in seconds by status, method and route",
+			Buckets: responseTimeBuckets,
 		},
-		[]string{"status", "method", "path"},
+		[]string{"status", "method", "route"},
 	)
 
-	if err := prometheus.Register(metr.Times); err != nil {
+	if err := reg.Register(metr.Times); err != nil {
 		return nil, err
 	}
 
-	if err := prometheus.Register(collectors.NewBuildInfoCollector()); err != nil {
+	metr.Sizes = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    name + "_response_size_bytes",
+			Help:    "Http response body size in bytes by status, method and route",
+			Buckets: responseSizeBuckets,
+		},
+		[]string{"status", "method", "route"},
+	)
+
+	if err := reg.Register(metr.Sizes); err != nil {
 		return nil, err
 	}
 
-	go func() {
-		router := gin.Default()
-		router.GET("/metrics", gin.WrapH(promhttp.Handler()))
-		log.Printf("Metrics server is running on port: %s", address)
-		if err := router.Run(address); err != nil {
-			log.Fatal(err)
-		}
-	}()
+	metr.InFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: name + "_in_flight_requests",
+		Help: "Http requests being served",
+	})
+
+	if err := reg.Register(metr.InFlight); err != nil {
+		return nil, err
+	}
+
+	metr.Limited = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: name + "_rate_limited_total",
+			Help: "Requests rejected by the rate limiter",
+		},
+		[]string{"method", "route", "key"},
+	)
+
+	if err := reg.Register(metr.Limited); err != nil {
+		return nil, err
+	}
 
 	return &metr, nil
 }
 
 // IncHits
-func (metr *PrometheusMetrics) IncHits(status int, method, path string) {
+func (metr *PrometheusMetrics) IncHits(status int, method, route string) {
 	metr.HitsTotal.Inc()
-	metr.Hits.WithLabelValues(strconv.Itoa(status), method, path).Inc()
+	metr.Hits.WithLabelValues(strconv.Itoa(status), method, route).Inc()
 }
 
 // Observer response time
 func (metr *PrometheusMetrics) ObserveResponseTime(
-	status int, method, path string, observeTime float64,
+	status int, method, route string, observeTime float64,
 ) {
-	metr.Times.WithLabelValues(strconv.Itoa(status), method, path).Observe(observeTime)
+	metr.Times.WithLabelValues(strconv.Itoa(status), method, route).Observe(observeTime)
+}
+
+// Observer response size
+func (metr *PrometheusMetrics) ObserveResponseSize(status int, method, route string, size int) {
+	metr.Sizes.WithLabelValues(strconv.Itoa(status), method, route).Observe(float64(size))
+}
+
+// IncInFlight
+func (metr *PrometheusMetrics) IncInFlight() {
+	metr.InFlight.Inc()
+}
+
+// DecInFlight
+func (metr *PrometheusMetrics) DecInFlight() {
+	metr.InFlight.Dec()
+}
+
+// IncRateLimited
+func (metr *PrometheusMetrics) IncRateLimited(method, route, key string) {
+	metr.Limited.WithLabelValues(method, route, key).Inc()
 }
@@ -0,0 +1,74 @@
+package metric_test
+
+import (
+	"companies-service/pkg/db/redis"
+	"companies-service/pkg/kafka"
+	"companies-service/pkg/metric"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	goredis "github.com/redis/go-redis/v9"
+	kafkago "github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// registerServiceMetrics registers the metrics and the collectors of the service as on startup
+func registerServiceMetrics(t *testing.T, reg *prometheus.Registry, name string) {
+	t.Helper()
+
+	_, err := metric.CreateMetrics(reg, name)
+	require.NoError(t, err)
+	_, err = metric.CreateBusinessMetrics(reg, name)
+	require.NoError(t, err)
+	_, err = metric.CreateCacheMetrics(reg, name)
+	require.NoError(t, err)
+	_, err = metric.CreateStoreMetrics(reg, name)
+	require.NoError(t, err)
+	_, err = metric.CreateConsumerMetrics(reg, name)
+	require.NoError(t, err)
+
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	mr := miniredis.RunT(t)
+	redisClient := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = redisClient.Close() })
+
+	require.NoError(t, reg.Register(collectors.NewDBStatsCollector(db, "company_db")))
+	require.NoError(t, reg.Register(redis.NewPoolStatsCollector(name, redisClient)))
+	require.NoError(t, reg.Register(kafka.NewWriterStatsCollector(name,
+		func() kafkago.WriterStats { return kafkago.WriterStats{} })))
+}
+
+func TestNewRegistry(t *testing.T) {
+	t.Parallel()
+
+	// The registries are independent, as for the tests and the instances of a process
+	first := metric.NewRegistry()
+	second := metric.NewRegistry()
+	registerServiceMetrics(t, first, "api")
+	registerServiceMetrics(t, second, "api")
+
+	for _, reg := range []*prometheus.Registry{first, second} {
+		families, err := reg.Gather()
+		require.NoError(t, err)
+
+		names := make(map[string]bool, len(families))
+		for _, family := range families {
+			names[family.GetName()] = true
+		}
+		assert.True(t, names["go_goroutines"])
+		assert.True(t, names["go_build_info"])
+		assert.True(t, names["go_sql_open_connections"])
+		assert.True(t, names["api_kafka_writer_writes_total"])
+	}
+
+	// The metrics of a registry are registered once
+	_, err := metric.CreateMetrics(first, "api")
+	assert.Error(t, err)
+}
@@ -0,0 +1,70 @@
+package metric
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Store call results
+const (
+	StoreSuccess = "success"
+	StoreError   = "error"
+)
+
+// storeBuckets are the latency buckets of the database and redis calls, in seconds
+var storeBuckets = []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5}
+
+// Store Metrics interface
+type StoreMetrics interface {
+	ObserveQuery(query, operation, status string, observeTime float64)
+	ObserveRedisCommand(command, status string, observeTime float64)
+}
+
+// Prometheus Store Metrics struct
+type PrometheusStoreMetrics struct {
+	Queries       *prometheus.HistogramVec
+	RedisCommands *prometheus.HistogramVec
+}
+
+// Create postgres and redis metrics with name
+func CreateStoreMetrics(reg prometheus.Registerer, name string) (StoreMetrics, error) {
+	var metr PrometheusStoreMetrics
+	metr.Queries = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    name + "_db_query_duration_seconds",
+			Help:    "Postgres query latency by query name, operation and status",
+			Buckets: storeBuckets,
+		},
+		[]string{"query", "operation", "status"},
+	)
+
+	if err := reg.Register(metr.Queries); err != nil {
+		return nil, err
+	}
+
+	metr.RedisCommands = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    name + "_redis_command_duration_seconds",
+			Help:    "Redis command latency by command and status",
+			Buckets: storeBuckets,
+		},
+		[]string{"command", "status"},
+	)
+
+	if err := reg.Register(metr.RedisCommands); err != nil {
+		return nil, err
+	}
+
+	return &metr, nil
+}
+
+// ObserveQuery
+func (metr *PrometheusStoreMetrics) ObserveQuery(
+	query, operation, status string, observeTime float64,
+) {
+	metr.Queries.WithLabelValues(query, operation, status).Observe(observeTime)
+}
+
+// ObserveRedisCommand
+func (metr *PrometheusStoreMetrics) ObserveRedisCommand(command, status string, observeTime float64) {
+	metr.RedisCommands.WithLabelValues(command, status).Observe(observeTime)
+}
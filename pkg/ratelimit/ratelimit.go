@@ -0,0 +1,136 @@
+package ratelimit
+
+import (
+	"companies-service/pkg/tracing"
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+const keyPrefix = "api-ratelimit"
+
+// tokenBucketScript refills the bucket by the elapsed time and takes a token if available.
+// It returns whether the request is allowed, the remaining tokens,
+// the seconds until a token is available and the seconds until the bucket is full.
+var tokenBucketScript = redis.NewScript(`
+local key = KEYS[1]
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+
+local time = redis.call("TIME")
+local now = tonumber(time[1]) + tonumber(time[2]) / 1000000
+
+local bucket = redis.call("HMGET", key, "tokens", "ts")
+local tokens = tonumber(bucket[1]) or burst
+local ts = tonumber(bucket[2]) or now
+
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate)
+
+local allowed = 0
+local retry_after = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry_after = (1 - tokens) / rate
+end
+
+redis.call("HSET", key, "tokens", tostring(tokens), "ts", tostring(now))
+redis.call("EXPIRE", key, math.ceil(burst / rate) + 1)
+
+return {allowed, tostring(tokens), tostring(retry_after), tostring((burst - tokens) / rate)}
+`)
+
+// Limit of a token bucket, Requests per Period with bursts up to Burst requests
+type Limit struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+// Result of a rate limited request
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration
+	ResetAfter time.Duration
+}
+
+// Limiter interface
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (*Result, error)
+}
+
+// Redis token bucket limiter, shared by all instances
+type redisLimiter struct {
+	redisClient *redis.Client
+}
+
+// Redis Limiter constructor
+func NewRedisLimiter(redisClient *redis.Client) Limiter {
+	return &redisLimiter{redisClient: redisClient}
+}
+
+// Allow takes a token from the bucket of the key
+func (l *redisLimiter) Allow(ctx context.Context, key string, limit Limit) (*Result, error) {
+	ctx, span := tracing.StartSpan(ctx, "redisLimiter.Allow")
+	defer span.End()
+
+	if limit.Requests <= 0 || limit.Period <= 0 {
+		return nil, errors.New("redisLimiter.Allow: invalid limit")
+	}
+
+	burst := limit.Burst
+	if burst <= 0 {
+		burst = limit.Requests
+	}
+	rate := float64(limit.Requests) / limit.Period.Seconds()
+
+	values, err := tokenBucketScript.Run(ctx, l.redisClient,
+		[]string{keyPrefix + ":" + key}, rate, burst).Slice()
+	if err != nil {
+		return nil, errors.Wrap(err, "redisLimiter.Allow.tokenBucketScript.Run")
+	}
+	if len(values) != 4 {
+		return nil, errors.Errorf("redisLimiter.Allow: unexpected script result %v", values)
+	}
+
+	allowed, _ := values[0].(int64)
+	remaining, err := parseFloat(values[1])
+	if err != nil {
+		return nil, errors.Wrap(err, "redisLimiter.Allow.remaining")
+	}
+	retryAfter, err := parseFloat(values[2])
+	if err != nil {
+		return nil, errors.Wrap(err, "redisLimiter.Allow.retryAfter")
+	}
+	resetAfter, err := parseFloat(values[3])
+	if err != nil {
+		return nil, errors.Wrap(err, "redisLimiter.Allow.resetAfter")
+	}
+
+	return &Result{
+		Allowed:    allowed == 1,
+		Limit:      burst,
+		Remaining:  int(math.Floor(remaining)),
+		RetryAfter: seconds(retryAfter),
+		ResetAfter: seconds(resetAfter),
+	}, nil
+}
+
+func parseFloat(value interface{}) (float64, error) {
+	str, ok := value.(string)
+	if !ok {
+		return 0, errors.Errorf("not a string: %v", value)
+	}
+	return strconv.ParseFloat(str, 64)
+}
+
+func seconds(value float64) time.Duration {
+	return time.Duration(value * float64(time.Second))
+}
@@ -0,0 +1,134 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLimiter(t *testing.T) (Limiter, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	// The script reads the time of redis, it is frozen by the tests
+	mr.SetTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { _ = redisClient.Close() })
+
+	return NewRedisLimiter(redisClient), mr
+}
+
+func TestRedisLimiter_Allow(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	// A token every 6 seconds with bursts up to 3 requests
+	limit := Limit{Requests: 10, Period: time.Minute, Burst: 3}
+
+	t.Run("Burst", func(t *testing.T) {
+		t.Parallel()
+
+		limiter, mr := newTestLimiter(t)
+
+		for remaining := 2; remaining >= 0; remaining-- {
+			result, err := limiter.Allow(ctx, "burst", limit)
+			require.NoError(t, err)
+			assert.True(t, result.Allowed)
+			assert.Equal(t, 3, result.Limit)
+			assert.Equal(t, remaining, result.Remaining)
+			assert.Zero(t, result.RetryAfter)
+			assert.Equal(t, time.Duration(3-remaining)*6*time.Second, result.ResetAfter)
+		}
+
+		result, err := limiter.Allow(ctx, "burst", limit)
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, 0, result.Remaining)
+		assert.Equal(t, 6*time.Second, result.RetryAfter)
+		assert.Equal(t, 18*time.Second, result.ResetAfter)
+
+		// The bucket expires once it would be full again
+		assert.Equal(t, 19*time.Second, mr.TTL(keyPrefix+":burst"))
+	})
+
+	t.Run("Refill", func(t *testing.T) {
+		t.Parallel()
+
+		limiter, mr := newTestLimiter(t)
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		for i := 0; i < 3; i++ {
+			result, err := limiter.Allow(ctx, "refill", limit)
+			require.NoError(t, err)
+			require.True(t, result.Allowed)
+		}
+
+		mr.SetTime(now.Add(3 * time.Second))
+		result, err := limiter.Allow(ctx, "refill", limit)
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, 3*time.Second, result.RetryAfter)
+
+		mr.SetTime(now.Add(6 * time.Second))
+		result, err = limiter.Allow(ctx, "refill", limit)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 0, result.Remaining)
+
+		// The bucket never refills over the burst
+		mr.SetTime(now.Add(time.Hour))
+		result, err = limiter.Allow(ctx, "refill", limit)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 2, result.Remaining)
+	})
+
+	t.Run("Keys limited separately", func(t *testing.T) {
+		t.Parallel()
+
+		limiter, _ := newTestLimiter(t)
+		single := Limit{Requests: 1, Period: time.Minute}
+
+		result, err := limiter.Allow(ctx, "first", single)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		// The burst defaults to the requests
+		assert.Equal(t, 1, result.Limit)
+
+		result, err = limiter.Allow(ctx, "first", single)
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+
+		result, err = limiter.Allow(ctx, "second", single)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+	})
+
+	t.Run("Invalid limit", func(t *testing.T) {
+		t.Parallel()
+
+		limiter, _ := newTestLimiter(t)
+
+		_, err := limiter.Allow(ctx, "invalid", Limit{Requests: 0, Period: time.Minute})
+		assert.Error(t, err)
+		_, err = limiter.Allow(ctx, "invalid", Limit{Requests: 1})
+		assert.Error(t, err)
+	})
+
+	t.Run("Redis down", func(t *testing.T) {
+		t.Parallel()
+
+		limiter, mr := newTestLimiter(t)
+		mr.Close()
+
+		result, err := limiter.Allow(ctx, "down", limit)
+		assert.Error(t, err)
+		assert.Nil(t, result)
+	})
+}
@@ -0,0 +1,381 @@
+package schemaregistry
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/bufbuild/protocompile"
+	"github.com/linkedin/goavro/v2"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const protobufSchemaFile = "schema.proto"
+
+// CheckCompatibility checks the backward compatibility of the schema with the previous
+// schema of its subject, that is the consumers of the schema can read the messages written
+// with the previous one. It returns ErrIncompatibleSchema with the first breaking change
+func CheckCompatibility(schemaType, schema, previous string) error {
+	switch normalizeType(schemaType) {
+	case TypeAvro:
+		return checkAvroCompatibility(schema, previous)
+	case TypeProtobuf:
+		return checkProtobufCompatibility(schema, previous)
+	default:
+		return errors.Errorf("schemaregistry: unsupported schema type %q", schemaType)
+	}
+}
+
+// CompileProtobuf compiles a protobuf schema, it may import the well known types
+func CompileProtobuf(ctx context.Context, schema string) (protoreflect.FileDescriptor, error) {
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+			Accessor: protocompile.SourceAccessorFromMap(
+				map[string]string{protobufSchemaFile: schema}),
+		}),
+	}
+
+	files, err := compiler.Compile(ctx, protobufSchemaFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "schemaregistry.CompileProtobuf")
+	}
+	return files[0], nil
+}
+
+// checkProtobufCompatibility checks that the messages of the previous schema still exist and
+// that their field numbers keep their kind and cardinality, removed fields are allowed
+func checkProtobufCompatibility(schema, previous string) error {
+	ctx := context.Background()
+	file, err := CompileProtobuf(ctx, schema)
+	if err != nil {
+		return err
+	}
+	previousFile, err := CompileProtobuf(ctx, previous)
+	if err != nil {
+		return errors.Wrap(err, "previous schema")
+	}
+
+	return checkProtobufMessages(file, previousFile.Messages())
+}
+
+func checkProtobufMessages(
+	file protoreflect.FileDescriptor, previousMessages protoreflect.MessageDescriptors,
+) error {
+	for i := 0; i < previousMessages.Len(); i++ {
+		previousMessage := previousMessages.Get(i)
+
+		message := findProtobufMessage(file, previousMessage.FullName())
+		if message == nil {
+			return errors.Wrapf(ErrIncompatibleSchema, "message %s removed",
+				previousMessage.FullName())
+		}
+
+		previousFields := previousMessage.Fields()
+		for j := 0; j < previousFields.Len(); j++ {
+			previousField := previousFields.Get(j)
+			field := message.Fields().ByNumber(previousField.Number())
+			if field == nil {
+				continue
+			}
+
+			if field.Kind() != previousField.Kind() ||
+				field.Cardinality() != previousField.Cardinality() ||
+				field.IsMap() != previousField.IsMap() {
+				return errors.Wrapf(ErrIncompatibleSchema, "field %d of %s changed its type",
+					previousField.Number(), previousMessage.FullName())
+			}
+			if previousField.Message() != nil &&
+				field.Message().FullName() != previousField.Message().FullName() {
+				return errors.Wrapf(ErrIncompatibleSchema, "field %d of %s changed its message",
+					previousField.Number(), previousMessage.FullName())
+			}
+		}
+
+		if err := checkProtobufMessages(file, previousMessage.Messages()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func findProtobufMessage(
+	file protoreflect.FileDescriptor, name protoreflect.FullName,
+) protoreflect.MessageDescriptor {
+	relative := strings.TrimPrefix(string(name), string(file.Package())+".")
+	if file.Package() == "" {
+		relative = string(name)
+	}
+
+	messages := file.Messages()
+	var message protoreflect.MessageDescriptor
+	for _, part := range strings.Split(relative, ".") {
+		message = messages.ByName(protoreflect.Name(part))
+		if message == nil {
+			return nil
+		}
+		messages = message.Messages()
+	}
+	return message
+}
+
+// avro primitive promotions allowed by the schema resolution, by writer type
+var avroPromotions = map[string][]string{
+	"int":    {"long", "float", "double"},
+	"long":   {"float", "double"},
+	"float":  {"double"},
+	"string": {"bytes"},
+	"bytes":  {"string"},
+}
+
+// avroSchema resolves the named types of an avro schema
+type avroSchema struct {
+	names map[string]map[string]interface{}
+}
+
+// checkAvroCompatibility checks that the schema can read the data of the previous schema with
+// the avro schema resolution rules
+func checkAvroCompatibility(schema, previous string) error {
+	if _, err := goavro.NewCodec(schema); err != nil {
+		return errors.Wrap(err, "schemaregistry.checkAvroCompatibility.NewCodec")
+	}
+	if _, err := goavro.NewCodec(previous); err != nil {
+		return errors.Wrap(err, "previous schema")
+	}
+
+	var readerType, writerType interface{}
+	if err := json.Unmarshal([]byte(schema), &readerType); err != nil {
+		return errors.Wrap(err, "schemaregistry.checkAvroCompatibility.Unmarshal")
+	}
+	if err := json.Unmarshal([]byte(previous), &writerType); err != nil {
+		return errors.Wrap(err, "previous schema")
+	}
+
+	reader := &avroSchema{names: map[string]map[string]interface{}{}}
+	reader.collectNames(readerType, "")
+	writer := &avroSchema{names: map[string]map[string]interface{}{}}
+	writer.collectNames(writerType, "")
+
+	return checkAvroType(reader, readerType, writer, writerType, "", map[string]bool{})
+}
+
+// collectNames registers the named types of the schema by full name
+func (s *avroSchema) collectNames(schemaType interface{}, namespace string) {
+	switch t := schemaType.(type) {
+	case []interface{}:
+		for _, branch := range t {
+			s.collectNames(branch, namespace)
+		}
+	case map[string]interface{}:
+		typeName, _ := t["type"].(string)
+		switch typeName {
+		case "record", "error", "enum", "fixed":
+			fullName := avroFullName(t, namespace)
+			s.names[fullName] = t
+			if _, ok := s.names[avroShortName(fullName)]; !ok {
+				s.names[avroShortName(fullName)] = t
+			}
+			namespace = fullName[:max(strings.LastIndex(fullName, "."), 0)]
+			if fields, ok := t["fields"].([]interface{}); ok {
+				for _, field := range fields {
+					if fieldMap, ok := field.(map[string]interface{}); ok {
+						s.collectNames(fieldMap["type"], namespace)
+					}
+				}
+			}
+		case "array":
+			s.collectNames(t["items"], namespace)
+		case "map":
+			s.collectNames(t["values"], namespace)
+		default:
+			s.collectNames(t["type"], namespace)
+		}
+	}
+}
+
+// resolve returns the type of a named type reference, the primitives are returned as is
+func (s *avroSchema) resolve(schemaType interface{}) interface{} {
+	name, ok := schemaType.(string)
+	if !ok {
+		return schemaType
+	}
+	if named, ok := s.names[name]; ok {
+		return named
+	}
+	return name
+}
+
+func checkAvroType(
+	reader *avroSchema,
+	readerType interface{},
+	writer *avroSchema,
+	writerType interface{},
+	path string,
+	visited map[string]bool,
+) error {
+	readerType = reader.resolve(readerType)
+	writerType = writer.resolve(writerType)
+
+	// every branch of a writer union must be readable
+	if writerBranches, ok := writerType.([]interface{}); ok {
+		for _, branch := range writerBranches {
+			if err := checkAvroType(reader, readerType, writer, branch, path,
+				visited); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// a reader union reads the writer type with its first matching branch
+	if readerBranches, ok := readerType.([]interface{}); ok {
+		for _, branch := range readerBranches {
+			if checkAvroType(reader, branch, writer, writerType, path, visited) == nil {
+				return nil
+			}
+		}
+		return errors.Wrapf(ErrIncompatibleSchema, "%s: no union branch reads %s",
+			avroPath(path), avroTypeName(writerType))
+	}
+
+	readerName, writerName := avroTypeName(readerType), avroTypeName(writerType)
+	if readerName != writerName {
+		for _, promoted := range avroPromotions[writerName] {
+			if promoted == readerName {
+				return nil
+			}
+		}
+		return errors.Wrapf(ErrIncompatibleSchema, "%s: %s can not read %s", avroPath(path),
+			readerName, writerName)
+	}
+
+	readerMap, _ := readerType.(map[string]interface{})
+	writerMap, _ := writerType.(map[string]interface{})
+	switch readerName {
+	case "array":
+		return checkAvroType(reader, readerMap["items"], writer, writerMap["items"],
+			path+"[]", visited)
+	case "map":
+		return checkAvroType(reader, readerMap["values"], writer, writerMap["values"],
+			path+"{}", visited)
+	case "fixed":
+		if readerMap["size"] != writerMap["size"] {
+			return errors.Wrapf(ErrIncompatibleSchema, "%s: fixed size changed", avroPath(path))
+		}
+	case "enum":
+		return checkAvroEnum(readerMap, writerMap, path)
+	case "record", "error":
+		return checkAvroRecord(reader, readerMap, writer, writerMap, path, visited)
+	}
+	return nil
+}
+
+func checkAvroEnum(reader, writer map[string]interface{}, path string) error {
+	if _, ok := reader["default"]; ok {
+		return nil
+	}
+
+	symbols := map[interface{}]bool{}
+	for _, symbol := range reader["symbols"].([]interface{}) {
+		symbols[symbol] = true
+	}
+	for _, symbol := range writer["symbols"].([]interface{}) {
+		if !symbols[symbol] {
+			return errors.Wrapf(ErrIncompatibleSchema, "%s: enum symbol %v removed",
+				avroPath(path), symbol)
+		}
+	}
+	return nil
+}
+
+func checkAvroRecord(
+	reader *avroSchema,
+	readerRecord map[string]interface{},
+	writer *avroSchema,
+	writerRecord map[string]interface{},
+	path string,
+	visited map[string]bool,
+) error {
+	readerName, writerName := avroFullName(readerRecord, ""), avroFullName(writerRecord, "")
+	if avroShortName(readerName) != avroShortName(writerName) {
+		return errors.Wrapf(ErrIncompatibleSchema, "%s: record %s can not read %s",
+			avroPath(path), readerName, writerName)
+	}
+
+	// recursive records are checked once
+	key := readerName + "|" + writerName
+	if visited[key] {
+		return nil
+	}
+	visited[key] = true
+
+	writerFields := map[string]map[string]interface{}{}
+	for _, field := range writerRecord["fields"].([]interface{}) {
+		fieldMap := field.(map[string]interface{})
+		writerFields[fieldMap["name"].(string)] = fieldMap
+	}
+
+	for _, field := range readerRecord["fields"].([]interface{}) {
+		readerField := field.(map[string]interface{})
+		name := readerField["name"].(string)
+
+		writerField, ok := writerFields[name]
+		if !ok {
+			if _, hasDefault := readerField["default"]; !hasDefault {
+				return errors.Wrapf(ErrIncompatibleSchema, "%s: field %s added without default",
+					avroPath(path), name)
+			}
+			continue
+		}
+
+		if err := checkAvroType(reader, readerField["type"], writer, writerField["type"],
+			path+"."+name, visited); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// avroTypeName returns the primitive or complex type name of an avro type
+func avroTypeName(schemaType interface{}) string {
+	switch t := schemaType.(type) {
+	case string:
+		return t
+	case map[string]interface{}:
+		typeName, _ := t["type"].(string)
+		switch typeName {
+		case "record", "error", "enum", "fixed", "array", "map":
+			return typeName
+		}
+		// a primitive with attributes such as a logical type
+		return avroTypeName(t["type"])
+	case []interface{}:
+		return "union"
+	}
+	return "unknown"
+}
+
+func avroFullName(named map[string]interface{}, namespace string) string {
+	name, _ := named["name"].(string)
+	if strings.Contains(name, ".") {
+		return name
+	}
+	if ns, ok := named["namespace"].(string); ok {
+		namespace = ns
+	}
+	if namespace == "" {
+		return name
+	}
+	return namespace + "." + name
+}
+
+func avroShortName(fullName string) string {
+	return fullName[strings.LastIndex(fullName, ".")+1:]
+}
+
+func avroPath(path string) string {
+	if path == "" {
+		return "schema"
+	}
+	return strings.TrimPrefix(path, ".")
+}
@@ -0,0 +1,252 @@
+package schemaregistry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const protobufCompany = `syntax = "proto3";
+package companies.v1;
+
+message Company {
+  string company_id = 1;
+  string company_name = 2;
+  int32 amount_of_employees = 3;
+  Address address = 4;
+
+  message Address {
+    string city = 1;
+  }
+}
+`
+
+const avroCompany = `{
+  "type": "record",
+  "name": "Company",
+  "namespace": "companies.v1",
+  "fields": [
+    {"name": "company_id", "type": "string"},
+    {"name": "amount_of_employees", "type": "int"},
+    {"name": "company_type", "type": {"type": "enum", "name": "CompanyType",
+      "symbols": ["Corporations", "NonProfit"]}},
+    {"name": "parent", "type": ["null", "Company"], "default": null}
+  ]
+}`
+
+func TestCheckCompatibility_Protobuf(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		schema     string
+		compatible bool
+	}{
+		{name: "Same schema", schema: protobufCompany, compatible: true},
+		{
+			name: "Field added",
+			schema: `syntax = "proto3";
+package companies.v1;
+message Company {
+  string company_id = 1;
+  string company_name = 2;
+  int32 amount_of_employees = 3;
+  Address address = 4;
+  bool registered = 5;
+  message Address { string city = 1; }
+}`,
+			compatible: true,
+		},
+		{
+			name: "Field removed",
+			schema: `syntax = "proto3";
+package companies.v1;
+message Company {
+  string company_id = 1;
+  Address address = 4;
+  message Address { string city = 1; }
+}`,
+			compatible: true,
+		},
+		{
+			name: "Field type changed",
+			schema: `syntax = "proto3";
+package companies.v1;
+message Company {
+  string company_id = 1;
+  string company_name = 2;
+  string amount_of_employees = 3;
+  Address address = 4;
+  message Address { string city = 1; }
+}`,
+			compatible: false,
+		},
+		{
+			name: "Field made repeated",
+			schema: `syntax = "proto3";
+package companies.v1;
+message Company {
+  string company_id = 1;
+  repeated string company_name = 2;
+  int32 amount_of_employees = 3;
+  Address address = 4;
+  message Address { string city = 1; }
+}`,
+			compatible: false,
+		},
+		{
+			name: "Field message changed",
+			schema: `syntax = "proto3";
+package companies.v1;
+message Company {
+  string company_id = 1;
+  string company_name = 2;
+  int32 amount_of_employees = 3;
+  Location address = 4;
+  message Address { string city = 1; }
+  message Location { string city = 1; }
+}`,
+			compatible: false,
+		},
+		{
+			name: "Nested message removed",
+			schema: `syntax = "proto3";
+package companies.v1;
+message Company {
+  string company_id = 1;
+  string company_name = 2;
+  int32 amount_of_employees = 3;
+}`,
+			compatible: false,
+		},
+		{
+			name: "Package changed",
+			schema: `syntax = "proto3";
+package companies.v2;
+message Company {
+  string company_id = 1;
+}`,
+			compatible: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckCompatibility(TypeProtobuf, tt.schema, protobufCompany)
+			if tt.compatible {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrIncompatibleSchema)
+		})
+	}
+
+	t.Run("Invalid schema", func(t *testing.T) {
+		err := CheckCompatibility(TypeProtobuf, "message {", protobufCompany)
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, ErrIncompatibleSchema)
+	})
+}
+
+func TestCheckCompatibility_Avro(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		schema     string
+		compatible bool
+	}{
+		{name: "Same schema", schema: avroCompany, compatible: true},
+		{
+			name: "Field added with default",
+			schema: `{"type": "record", "name": "Company", "namespace": "companies.v1",
+  "fields": [
+    {"name": "company_id", "type": "string"},
+    {"name": "amount_of_employees", "type": "int"},
+    {"name": "company_type", "type": {"type": "enum", "name": "CompanyType",
+      "symbols": ["Corporations", "NonProfit"]}},
+    {"name": "parent", "type": ["null", "Company"], "default": null},
+    {"name": "registered", "type": "boolean", "default": false}
+  ]}`,
+			compatible: true,
+		},
+		{
+			name: "Field added without default",
+			schema: `{"type": "record", "name": "Company", "namespace": "companies.v1",
+  "fields": [
+    {"name": "company_id", "type": "string"},
+    {"name": "registered", "type": "boolean"}
+  ]}`,
+			compatible: false,
+		},
+		{
+			name: "Field removed",
+			schema: `{"type": "record", "name": "Company", "namespace": "companies.v1",
+  "fields": [{"name": "company_id", "type": "string"}]}`,
+			compatible: true,
+		},
+		{
+			name: "Int promoted to long",
+			schema: `{"type": "record", "name": "Company", "namespace": "companies.v1",
+  "fields": [{"name": "amount_of_employees", "type": "long"}]}`,
+			compatible: true,
+		},
+		{
+			name: "Int changed to string",
+			schema: `{"type": "record", "name": "Company", "namespace": "companies.v1",
+  "fields": [{"name": "amount_of_employees", "type": "string"}]}`,
+			compatible: false,
+		},
+		{
+			name: "Field made nullable",
+			schema: `{"type": "record", "name": "Company", "namespace": "companies.v1",
+  "fields": [{"name": "company_id", "type": ["null", "string"]}]}`,
+			compatible: true,
+		},
+		{
+			name: "Enum symbol removed",
+			schema: `{"type": "record", "name": "Company", "namespace": "companies.v1",
+  "fields": [{"name": "company_type", "type": {"type": "enum", "name": "CompanyType",
+    "symbols": ["Corporations"]}}]}`,
+			compatible: false,
+		},
+		{
+			name: "Enum symbol removed with default",
+			schema: `{"type": "record", "name": "Company", "namespace": "companies.v1",
+  "fields": [{"name": "company_type", "type": {"type": "enum", "name": "CompanyType",
+    "symbols": ["Corporations", "Other"], "default": "Other"}}]}`,
+			compatible: true,
+		},
+		{
+			name: "Record renamed",
+			schema: `{"type": "record", "name": "Organization", "namespace": "companies.v1",
+  "fields": [{"name": "company_id", "type": "string"}]}`,
+			compatible: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckCompatibility(TypeAvro, tt.schema, avroCompany)
+			if tt.compatible {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrIncompatibleSchema)
+		})
+	}
+
+	t.Run("Nullable field made required", func(t *testing.T) {
+		err := CheckCompatibility("",
+			`{"type": "record", "name": "Company", "fields": [{"name": "id", "type": "string"}]}`,
+			`{"type": "record", "name": "Company", "fields": [{"name": "id", "type": ["null", "string"]}]}`)
+		assert.ErrorIs(t, err, ErrIncompatibleSchema)
+	})
+
+	t.Run("Invalid schema", func(t *testing.T) {
+		err := CheckCompatibility(TypeAvro, `{"type": "record"}`, avroCompany)
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, ErrIncompatibleSchema)
+	})
+}
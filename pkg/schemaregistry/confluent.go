@@ -0,0 +1,183 @@
+package schemaregistry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const confluentContentType = "application/vnd.schemaregistry.v1+json"
+
+// confluentRegistry is the client of the Confluent schema registry REST API
+type confluentRegistry struct {
+	url      string
+	username string
+	password string
+	client   *http.Client
+
+	mu  sync.RWMutex
+	ids map[int]*Schema
+}
+
+// NewConfluentRegistry returns the client of the Confluent schema registry of the url, the
+// schemas read by id are cached since they never change
+func NewConfluentRegistry(
+	registryURL, username, password string, timeout time.Duration,
+) SchemaRegistry {
+	return &confluentRegistry{
+		url:      strings.TrimSuffix(registryURL, "/"),
+		username: username,
+		password: password,
+		client:   &http.Client{Timeout: timeout},
+		ids:      map[int]*Schema{},
+	}
+}
+
+type confluentSchemaRequest struct {
+	Schema     string `json:"schema"`
+	SchemaType string `json:"schemaType,omitempty"`
+}
+
+type confluentError struct {
+	ErrorCode int    `json:"error_code"`
+	Message   string `json:"message"`
+}
+
+// Register implements SchemaRegistry
+func (r *confluentRegistry) Register(
+	ctx context.Context, subject, schemaType, schema string,
+) (*Schema, error) {
+	var response struct {
+		ID int `json:"id"`
+	}
+	path := fmt.Sprintf("/subjects/%s/versions", url.PathEscape(subject))
+	if err := r.do(ctx, http.MethodPost, path, newConfluentSchemaRequest(schemaType, schema),
+		&response); err != nil {
+		return nil, errors.Wrap(err, "confluentRegistry.Register")
+	}
+
+	return &Schema{ID: response.ID, Subject: subject, Type: normalizeType(schemaType),
+		Schema: schema}, nil
+}
+
+// Latest implements SchemaRegistry
+func (r *confluentRegistry) Latest(ctx context.Context, subject string) (*Schema, error) {
+	var schema Schema
+	path := fmt.Sprintf("/subjects/%s/versions/latest", url.PathEscape(subject))
+	if err := r.do(ctx, http.MethodGet, path, nil, &schema); err != nil {
+		return nil, errors.Wrap(err, "confluentRegistry.Latest")
+	}
+
+	schema.Type = normalizeType(schema.Type)
+	return &schema, nil
+}
+
+// ByID implements SchemaRegistry
+func (r *confluentRegistry) ByID(ctx context.Context, id int) (*Schema, error) {
+	r.mu.RLock()
+	schema, ok := r.ids[id]
+	r.mu.RUnlock()
+	if ok {
+		return schema, nil
+	}
+
+	schema = &Schema{}
+	if err := r.do(ctx, http.MethodGet, fmt.Sprintf("/schemas/ids/%d", id), nil,
+		schema); err != nil {
+		return nil, errors.Wrap(err, "confluentRegistry.ByID")
+	}
+	schema.ID = id
+	schema.Type = normalizeType(schema.Type)
+
+	r.mu.Lock()
+	r.ids[id] = schema
+	r.mu.Unlock()
+
+	return schema, nil
+}
+
+// Compatible implements SchemaRegistry
+func (r *confluentRegistry) Compatible(
+	ctx context.Context, subject, schemaType, schema string,
+) (bool, error) {
+	var response struct {
+		IsCompatible bool `json:"is_compatible"`
+	}
+	path := fmt.Sprintf("/compatibility/subjects/%s/versions/latest", url.PathEscape(subject))
+	err := r.do(ctx, http.MethodPost, path, newConfluentSchemaRequest(schemaType, schema),
+		&response)
+	if errors.Is(err, ErrNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "confluentRegistry.Compatible")
+	}
+
+	return response.IsCompatible, nil
+}
+
+func newConfluentSchemaRequest(schemaType, schema string) *confluentSchemaRequest {
+	request := &confluentSchemaRequest{Schema: schema}
+	// the avro type is the default of the registry
+	if normalizeType(schemaType) != TypeAvro {
+		request.SchemaType = schemaType
+	}
+	return request
+}
+
+// do sends a request to the registry and decodes its response, the unknown subjects and
+// schemas are returned as ErrNotFound and the rejected schemas as ErrIncompatibleSchema
+func (r *confluentRegistry) do(
+	ctx context.Context, method, path string, body interface{}, response interface{},
+) error {
+	var requestBody io.Reader
+	if body != nil {
+		bodyBytes, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		requestBody = bytes.NewReader(bodyBytes)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, r.url+path, requestBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", confluentContentType)
+	if body != nil {
+		req.Header.Set("Content-Type", confluentContentType)
+	}
+	if r.username != "" {
+		req.SetBasicAuth(r.username, r.password)
+	}
+
+	res, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		var registryErr confluentError
+		_ = json.NewDecoder(res.Body).Decode(&registryErr)
+		switch res.StatusCode {
+		case http.StatusNotFound:
+			return errors.Wrap(ErrNotFound, registryErr.Message)
+		case http.StatusConflict:
+			return errors.Wrap(ErrIncompatibleSchema, registryErr.Message)
+		}
+		return errors.Errorf("schema registry %s %s: status %d, error code %d: %s", method,
+			path, res.StatusCode, registryErr.ErrorCode, registryErr.Message)
+	}
+
+	return json.NewDecoder(res.Body).Decode(response)
+}
@@ -0,0 +1,102 @@
+package schemaregistry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfluentRegistry(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	subject := ValueSubject("company_created")
+	var byIDRequests atomic.Int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /subjects/company_created-value/versions",
+		func(w http.ResponseWriter, r *http.Request) {
+			var request confluentSchemaRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+			assert.Equal(t, TypeProtobuf, request.SchemaType)
+			assert.Equal(t, confluentContentType, r.Header.Get("Content-Type"))
+
+			username, password, ok := r.BasicAuth()
+			assert.True(t, ok)
+			assert.Equal(t, "user", username)
+			assert.Equal(t, "secret", password)
+
+			_, _ = w.Write([]byte(`{"id": 42}`))
+		})
+	mux.HandleFunc("GET /subjects/company_created-value/versions/latest",
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"id": 42, "subject": "company_created-value",
+				"version": 3, "schema": "{\"type\": \"string\"}"}`))
+		})
+	mux.HandleFunc("GET /schemas/ids/42", func(w http.ResponseWriter, r *http.Request) {
+		byIDRequests.Add(1)
+		_, _ = w.Write([]byte(`{"schemaType": "PROTOBUF", "schema": "syntax = \"proto3\";"}`))
+	})
+	mux.HandleFunc("POST /compatibility/subjects/company_created-value/versions/latest",
+		func(w http.ResponseWriter, r *http.Request) {
+			var request confluentSchemaRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+			assert.Empty(t, request.SchemaType)
+
+			_, _ = w.Write([]byte(`{"is_compatible": false}`))
+		})
+	mux.HandleFunc("POST /compatibility/subjects/company_deleted-value/versions/latest",
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error_code": 40401, "message": "Subject not found"}`))
+		})
+	mux.HandleFunc("POST /subjects/company_updated-value/versions",
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"error_code": 409, "message": "incompatible"}`))
+		})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	registry := NewConfluentRegistry(server.URL+"/", "user", "secret", time.Second)
+
+	registered, err := registry.Register(ctx, subject, TypeProtobuf, protobufCompany)
+	require.NoError(t, err)
+	assert.Equal(t, 42, registered.ID)
+
+	latest, err := registry.Latest(ctx, subject)
+	require.NoError(t, err)
+	assert.Equal(t, 3, latest.Version)
+	assert.Equal(t, TypeAvro, latest.Type)
+
+	for i := 0; i < 2; i++ {
+		schema, err := registry.ByID(ctx, 42)
+		require.NoError(t, err)
+		assert.Equal(t, 42, schema.ID)
+		assert.Equal(t, TypeProtobuf, schema.Type)
+	}
+	assert.Equal(t, int32(1), byIDRequests.Load())
+
+	compatible, err := registry.Compatible(ctx, subject, TypeAvro, avroCompany)
+	require.NoError(t, err)
+	assert.False(t, compatible)
+
+	compatible, err = registry.Compatible(ctx, ValueSubject("company_deleted"), TypeAvro,
+		avroCompany)
+	require.NoError(t, err)
+	assert.True(t, compatible)
+
+	_, err = registry.Register(ctx, ValueSubject("company_updated"), TypeAvro, avroCompany)
+	assert.ErrorIs(t, err, ErrIncompatibleSchema)
+
+	_, err = registry.ByID(ctx, 7)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,178 @@
+package schemaregistry
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// fileRegistry is a schema registry stored in a json file, for local development and tests.
+// It checks the backward compatibility of the new versions like the Confluent registry
+type fileRegistry struct {
+	path string
+
+	mu      sync.RWMutex
+	schemas []*Schema
+}
+
+// NewFileRegistry returns the registry stored in the file, the file is created on the first
+// registration
+func NewFileRegistry(path string) (SchemaRegistry, error) {
+	registry := &fileRegistry{path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return registry, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "schemaregistry.NewFileRegistry.ReadFile")
+	}
+
+	var file struct {
+		Schemas []*Schema `json:"schemas"`
+	}
+	if err = json.Unmarshal(data, &file); err != nil {
+		return nil, errors.Wrap(err, "schemaregistry.NewFileRegistry.Unmarshal")
+	}
+	registry.schemas = file.Schemas
+
+	return registry, nil
+}
+
+// Register implements SchemaRegistry
+func (r *fileRegistry) Register(
+	_ context.Context, subject, schemaType, schema string,
+) (*Schema, error) {
+	schemaType = normalizeType(schemaType)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	latest := r.latest(subject)
+	if latest != nil {
+		if latest.Type == schemaType && latest.Schema == schema {
+			return latest, nil
+		}
+		if err := r.checkCompatibility(latest, schemaType, schema); err != nil {
+			return nil, err
+		}
+	}
+
+	registered := &Schema{ID: r.schemaID(schemaType, schema), Subject: subject, Version: 1,
+		Type: schemaType, Schema: schema}
+	if latest != nil {
+		registered.Version = latest.Version + 1
+	}
+
+	r.schemas = append(r.schemas, registered)
+	if err := r.save(); err != nil {
+		r.schemas = r.schemas[:len(r.schemas)-1]
+		return nil, err
+	}
+
+	return registered, nil
+}
+
+// Latest implements SchemaRegistry
+func (r *fileRegistry) Latest(_ context.Context, subject string) (*Schema, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	latest := r.latest(subject)
+	if latest == nil {
+		return nil, errors.Wrap(ErrNotFound, subject)
+	}
+	return latest, nil
+}
+
+// ByID implements SchemaRegistry
+func (r *fileRegistry) ByID(_ context.Context, id int) (*Schema, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, schema := range r.schemas {
+		if schema.ID == id {
+			return schema, nil
+		}
+	}
+	return nil, errors.Wrapf(ErrNotFound, "id %d", id)
+}
+
+// Compatible implements SchemaRegistry
+func (r *fileRegistry) Compatible(
+	_ context.Context, subject, schemaType, schema string,
+) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	latest := r.latest(subject)
+	if latest == nil {
+		return true, nil
+	}
+
+	err := r.checkCompatibility(latest, normalizeType(schemaType), schema)
+	if errors.Is(err, ErrIncompatibleSchema) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *fileRegistry) checkCompatibility(latest *Schema, schemaType, schema string) error {
+	if latest.Type != schemaType {
+		return errors.Wrapf(ErrIncompatibleSchema, "schema type changed from %s to %s",
+			latest.Type, schemaType)
+	}
+	return CheckCompatibility(schemaType, schema, latest.Schema)
+}
+
+func (r *fileRegistry) latest(subject string) *Schema {
+	var latest *Schema
+	for _, schema := range r.schemas {
+		if schema.Subject == subject && (latest == nil || schema.Version > latest.Version) {
+			latest = schema
+		}
+	}
+	return latest
+}
+
+// schemaID returns the id of the schema, the same schema has the same id in all the subjects
+func (r *fileRegistry) schemaID(schemaType, schema string) int {
+	maxID := 0
+	for _, registered := range r.schemas {
+		if registered.Type == schemaType && registered.Schema == schema {
+			return registered.ID
+		}
+		maxID = max(maxID, registered.ID)
+	}
+	return maxID + 1
+}
+
+// save writes the registry to a temporary file renamed over the registry file
+func (r *fileRegistry) save() error {
+	data, err := json.MarshalIndent(struct {
+		Schemas []*Schema `json:"schemas"`
+	}{Schemas: r.schemas}, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "fileRegistry.save.Marshal")
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".*")
+	if err != nil {
+		return errors.Wrap(err, "fileRegistry.save.CreateTemp")
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "fileRegistry.save.Write")
+	}
+	if err = tmp.Close(); err != nil {
+		return errors.Wrap(err, "fileRegistry.save.Close")
+	}
+
+	return errors.Wrap(os.Rename(tmp.Name(), r.path), "fileRegistry.save.Rename")
+}
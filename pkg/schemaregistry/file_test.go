@@ -0,0 +1,94 @@
+package schemaregistry
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileRegistry(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "schemas.json")
+	subject := ValueSubject("company_created")
+
+	registry, err := NewFileRegistry(path)
+	require.NoError(t, err)
+
+	_, err = registry.Latest(ctx, subject)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = os.Stat(path)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	compatible, err := registry.Compatible(ctx, subject, TypeAvro, avroCompany)
+	require.NoError(t, err)
+	assert.True(t, compatible)
+
+	first, err := registry.Register(ctx, subject, TypeAvro, avroCompany)
+	require.NoError(t, err)
+	assert.Equal(t, 1, first.ID)
+	assert.Equal(t, 1, first.Version)
+
+	again, err := registry.Register(ctx, subject, "", avroCompany)
+	require.NoError(t, err)
+	assert.Equal(t, first, again)
+
+	evolved := `{"type": "record", "name": "Company", "namespace": "companies.v1",
+  "fields": [{"name": "company_id", "type": "string"}]}`
+	second, err := registry.Register(ctx, subject, TypeAvro, evolved)
+	require.NoError(t, err)
+	assert.Equal(t, 2, second.ID)
+	assert.Equal(t, 2, second.Version)
+
+	// the same schema has the same id in the other subjects
+	other, err := registry.Register(ctx, ValueSubject("company_updated"), TypeAvro,
+		avroCompany)
+	require.NoError(t, err)
+	assert.Equal(t, 1, other.ID)
+	assert.Equal(t, 1, other.Version)
+
+	breaking := `{"type": "record", "name": "Company", "namespace": "companies.v1",
+  "fields": [{"name": "registered", "type": "boolean"}]}`
+	compatible, err = registry.Compatible(ctx, subject, TypeAvro, breaking)
+	require.NoError(t, err)
+	assert.False(t, compatible)
+	_, err = registry.Register(ctx, subject, TypeAvro, breaking)
+	assert.ErrorIs(t, err, ErrIncompatibleSchema)
+
+	_, err = registry.Register(ctx, subject, TypeProtobuf, protobufCompany)
+	assert.ErrorIs(t, err, ErrIncompatibleSchema)
+
+	t.Run("Persisted", func(t *testing.T) {
+		reopened, err := NewFileRegistry(path)
+		require.NoError(t, err)
+
+		latest, err := reopened.Latest(ctx, subject)
+		require.NoError(t, err)
+		assert.Equal(t, second, latest)
+
+		byID, err := reopened.ByID(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, avroCompany, byID.Schema)
+
+		_, err = reopened.ByID(ctx, 3)
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		third, err := reopened.Register(ctx, ValueSubject("company_deleted"), TypeProtobuf,
+			protobufCompany)
+		require.NoError(t, err)
+		assert.Equal(t, 3, third.ID)
+	})
+
+	t.Run("Invalid file", func(t *testing.T) {
+		invalidPath := filepath.Join(t.TempDir(), "schemas.json")
+		require.NoError(t, os.WriteFile(invalidPath, []byte("{"), 0o600))
+
+		_, err := NewFileRegistry(invalidPath)
+		assert.Error(t, err)
+	})
+}
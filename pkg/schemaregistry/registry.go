@@ -0,0 +1,59 @@
+// Package schemaregistry registers and looks up the schemas of the binary kafka events, with
+// a client of the Confluent schema registry and a file-backed registry for local use.
+package schemaregistry
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// Schema types
+const (
+	TypeAvro     = "AVRO"
+	TypeProtobuf = "PROTOBUF"
+)
+
+var (
+	// ErrNotFound is returned when the subject or the schema id is not registered
+	ErrNotFound = errors.New("schemaregistry: schema not found")
+	// ErrIncompatibleSchema is returned when a schema breaks the latest schema of its subject
+	ErrIncompatibleSchema = errors.New("schemaregistry: incompatible schema")
+)
+
+// Schema is a registered schema of a subject
+type Schema struct {
+	ID      int    `json:"id"`
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
+	Type    string `json:"schemaType"`
+	Schema  string `json:"schema"`
+}
+
+// SchemaRegistry registers the schemas by subject, a subject is the topic name followed by
+// -value like the Confluent topic name strategy
+type SchemaRegistry interface {
+	// Register registers the schema as the latest version of the subject, registering the
+	// latest schema again returns it
+	Register(ctx context.Context, subject, schemaType, schema string) (*Schema, error)
+	// Latest returns the latest schema of the subject
+	Latest(ctx context.Context, subject string) (*Schema, error)
+	// ByID returns the schema of the id written in the messages
+	ByID(ctx context.Context, id int) (*Schema, error)
+	// Compatible reports whether the consumers of the schema can read the messages written
+	// with the latest schema of the subject, any schema is compatible with a new subject
+	Compatible(ctx context.Context, subject, schemaType, schema string) (bool, error)
+}
+
+// ValueSubject returns the subject of the message values of the topic
+func ValueSubject(topic string) string {
+	return topic + "-value"
+}
+
+// normalizeType returns the schema type, the Confluent registry omits the avro type
+func normalizeType(schemaType string) string {
+	if schemaType == "" {
+		return TypeAvro
+	}
+	return schemaType
+}
@@ -0,0 +1,69 @@
+package schemaregistry
+
+import (
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+)
+
+// Confluent wire format, a magic byte and the big endian schema id precede the payload
+const (
+	magicByte  = 0
+	headerSize = 5
+)
+
+// ErrInvalidWireFormat is returned when a message is not in the Confluent wire format
+var ErrInvalidWireFormat = errors.New("schemaregistry: invalid wire format")
+
+// AppendHeader appends the wire format header of the schema id to dst
+func AppendHeader(dst []byte, id int) []byte {
+	dst = append(dst, magicByte)
+	return binary.BigEndian.AppendUint32(dst, uint32(id))
+}
+
+// ParseHeader returns the schema id of the message and its payload
+func ParseHeader(message []byte) (int, []byte, error) {
+	if len(message) < headerSize || message[0] != magicByte {
+		return 0, nil, ErrInvalidWireFormat
+	}
+	return int(binary.BigEndian.Uint32(message[1:headerSize])), message[headerSize:], nil
+}
+
+// AppendMessageIndexes appends the indexes of the protobuf message in its schema to dst,
+// the first message of the schema is written as a single zero
+func AppendMessageIndexes(dst []byte, indexes []int) []byte {
+	if len(indexes) == 1 && indexes[0] == 0 {
+		return append(dst, 0)
+	}
+
+	dst = binary.AppendVarint(dst, int64(len(indexes)))
+	for _, index := range indexes {
+		dst = binary.AppendVarint(dst, int64(index))
+	}
+	return dst
+}
+
+// ReadMessageIndexes returns the indexes of the protobuf message in its schema and the
+// protobuf payload
+func ReadMessageIndexes(payload []byte) ([]int, []byte, error) {
+	count, n := binary.Varint(payload)
+	if n <= 0 || count < 0 || count > int64(len(payload)) {
+		return nil, nil, ErrInvalidWireFormat
+	}
+	payload = payload[n:]
+
+	if count == 0 {
+		return []int{0}, payload, nil
+	}
+
+	indexes := make([]int, 0, count)
+	for i := int64(0); i < count; i++ {
+		index, n := binary.Varint(payload)
+		if n <= 0 || index < 0 {
+			return nil, nil, ErrInvalidWireFormat
+		}
+		indexes = append(indexes, int(index))
+		payload = payload[n:]
+	}
+	return indexes, payload, nil
+}
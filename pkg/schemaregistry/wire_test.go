@@ -0,0 +1,63 @@
+package schemaregistry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeader(t *testing.T) {
+	t.Parallel()
+
+	message := append(AppendHeader(nil, 258), "payload"...)
+	assert.Equal(t, []byte{0, 0, 0, 1, 2}, message[:headerSize])
+
+	id, payload, err := ParseHeader(message)
+	require.NoError(t, err)
+	assert.Equal(t, 258, id)
+	assert.Equal(t, "payload", string(payload))
+
+	_, _, err = ParseHeader([]byte{0, 0, 1})
+	assert.ErrorIs(t, err, ErrInvalidWireFormat)
+	_, _, err = ParseHeader([]byte(`{"id": 1}`))
+	assert.ErrorIs(t, err, ErrInvalidWireFormat)
+}
+
+func TestMessageIndexes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		indexes []int
+		encoded []byte
+	}{
+		{name: "First message", indexes: []int{0}, encoded: []byte{0}},
+		{name: "Second message", indexes: []int{1}, encoded: []byte{2, 2}},
+		{name: "Nested message", indexes: []int{1, 0, 2}, encoded: []byte{6, 2, 0, 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := AppendMessageIndexes(nil, tt.indexes)
+			assert.Equal(t, tt.encoded, payload)
+
+			message := append(AppendHeader(nil, 7), append(payload, "data"...)...)
+			id, payload, err := ParseHeader(message)
+			require.NoError(t, err)
+			assert.Equal(t, 7, id)
+
+			indexes, data, err := ReadMessageIndexes(payload)
+			require.NoError(t, err)
+			assert.Equal(t, tt.indexes, indexes)
+			assert.Equal(t, "data", string(data))
+		})
+	}
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, payload := range [][]byte{nil, {1}, {4, 2}, {2, 1}} {
+			_, _, err := ReadMessageIndexes(payload)
+			assert.ErrorIs(t, err, ErrInvalidWireFormat, "payload %v", payload)
+		}
+	})
+}
@@ -0,0 +1,132 @@
+package tlsconfig
+
+import (
+	"companies-service/pkg/logger"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Reloader keeps the server certificate and the client CAs loaded from disk
+// and reloads them when the files change, without restarting the server
+type Reloader struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+	mu           sync.RWMutex
+	cert         *tls.Certificate
+	clientCAs    *x509.CertPool
+	modTime      time.Time
+	logger       logger.Logger
+}
+
+// Reloader constructor, it fails when the files can not be loaded
+func NewReloader(certFile, keyFile, clientCAFile string, logger logger.Logger) (*Reloader, error) {
+	r := &Reloader{certFile: certFile, keyFile: keyFile, clientCAFile: clientCAFile, logger: logger}
+
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return nil, err
+	}
+	if err = r.load(modTime); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Run checks the files for changes every interval until the context is done.
+// A failed reload keeps serving the previous certificates.
+func (r *Reloader) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			modTime, err := r.latestModTime()
+			if err != nil {
+				r.logger.Errorf("Reloader.latestModTime: %s", err)
+				continue
+			}
+
+			r.mu.RLock()
+			changed := modTime.After(r.modTime)
+			r.mu.RUnlock()
+			if !changed {
+				continue
+			}
+
+			if err = r.load(modTime); err != nil {
+				r.logger.Errorf("Reloader.load: %s", err)
+				continue
+			}
+			r.logger.Infof("TLS certificates reloaded from %s", r.certFile)
+		}
+	}
+}
+
+// GetCertificate returns the current server certificate
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.cert, nil
+}
+
+// ClientCAs returns the current client CAs, nil when mTLS is not configured
+func (r *Reloader) ClientCAs() *x509.CertPool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.clientCAs
+}
+
+func (r *Reloader) load(modTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return errors.Wrap(err, "tls.LoadX509KeyPair")
+	}
+
+	var clientCAs *x509.CertPool
+	if r.clientCAFile != "" {
+		if clientCAs, err = loadCertPool(r.clientCAFile); err != nil {
+			return errors.Wrap(err, "loadCertPool")
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.modTime = modTime
+
+	return nil
+}
+
+// latestModTime returns the latest modification time of the files
+func (r *Reloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, file := range []string{r.certFile, r.keyFile, r.clientCAFile} {
+		if file == "" {
+			continue
+		}
+
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, errors.Wrap(err, "os.Stat")
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
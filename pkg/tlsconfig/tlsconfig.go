@@ -0,0 +1,106 @@
+package tlsconfig
+
+import (
+	"companies-service/config"
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+var clientAuthTypes = map[string]tls.ClientAuthType{
+	"":                   tls.NoClientCert,
+	"none":               tls.NoClientCert,
+	"request":            tls.RequestClientCert,
+	"verify_if_given":    tls.VerifyClientCertIfGiven,
+	"require_and_verify": tls.RequireAndVerifyClientCert,
+}
+
+// NewServerConfig returns the tls config of the api server, the certificates
+// and the client CAs are read from the reloader on every handshake
+func NewServerConfig(cfg config.TLS, reloader *Reloader) (*tls.Config, error) {
+	minVersion, ok := tlsVersions[cfg.MinVersion]
+	if !ok {
+		if cfg.MinVersion != "" {
+			return nil, errors.Errorf("unsupported tls min version: %s", cfg.MinVersion)
+		}
+		minVersion = tls.VersionTLS12
+	}
+
+	cipherSuites, err := parseCipherSuites(cfg.CipherSuites)
+	if err != nil {
+		return nil, err
+	}
+
+	clientAuth, ok := clientAuthTypes[strings.ToLower(cfg.ClientAuth)]
+	if !ok {
+		return nil, errors.Errorf("unsupported tls client auth: %s", cfg.ClientAuth)
+	}
+	if clientAuth >= tls.VerifyClientCertIfGiven && cfg.ClientCAFile == "" {
+		return nil, errors.New("tls client auth requires the client CA file")
+	}
+
+	baseConfig := &tls.Config{
+		MinVersion:     minVersion,
+		CipherSuites:   cipherSuites,
+		ClientAuth:     clientAuth,
+		GetCertificate: reloader.GetCertificate,
+		NextProtos:     []string{"h2", "http/1.1"},
+	}
+
+	return &tls.Config{
+		MinVersion:     minVersion,
+		GetCertificate: reloader.GetCertificate,
+		// The client CAs may be reloaded, every handshake gets the current ones
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			tlsCfg := baseConfig.Clone()
+			tlsCfg.ClientCAs = reloader.ClientCAs()
+			return tlsCfg, nil
+		},
+	}, nil
+}
+
+// parseCipherSuites returns the ids of the cipher suites by their names,
+// only the secure suites are accepted. They apply to TLS 1.2, TLS 1.3 suites are not configurable.
+func parseCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	supported := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		supported[suite.Name] = suite.ID
+	}
+
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := supported[name]
+		if !ok {
+			return nil, errors.Errorf("unsupported tls cipher suite: %s", name)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+func loadCertPool(caFile string) (*x509.CertPool, error) {
+	caBytes, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "os.ReadFile")
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caBytes) {
+		return nil, errors.Errorf("no certificates found in %s", caFile)
+	}
+
+	return pool, nil
+}
@@ -0,0 +1,242 @@
+package tlsconfig
+
+import (
+	"companies-service/config"
+	"companies-service/pkg/logger"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLogger() logger.Logger {
+	apiLogger := logger.NewApiLogger(&config.Config{
+		Logger: config.Logger{Level: "fatal", Encoding: "console"},
+	})
+	apiLogger.InitLogger()
+	return apiLogger
+}
+
+// testCert is a generated certificate and its key
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// newTestCert generates a certificate of localhost signed by the parent, or self-signed
+// when the parent is nil
+func newTestCert(t *testing.T, serial int64, isCA bool, parent *testCert) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth,
+			x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return &testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+// writeCert writes the certificate and its key modified at the modification time
+func writeCert(t *testing.T, cert *testCert, certFile, keyFile string, modTime time.Time) {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(certFile, cert.certPEM, 0o600))
+	require.NoError(t, os.WriteFile(keyFile, cert.keyPEM, 0o600))
+	require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+	require.NoError(t, os.Chtimes(keyFile, modTime, modTime))
+}
+
+// handshake returns the error of a tls handshake of the client with the server config
+func handshake(serverConfig, clientConfig *tls.Config) error {
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close() // nolint: errcheck
+	defer clientConn.Close() // nolint: errcheck
+
+	serverErr := make(chan error, 1)
+	go func() {
+		server := tls.Server(serverConn, serverConfig)
+		err := server.Handshake()
+		// The client reads the alert of a failed handshake before the pipe is closed
+		_ = server.Close()
+		serverErr <- err
+	}()
+
+	clientErr := tls.Client(clientConn, clientConfig).Handshake()
+	_ = clientConn.Close()
+	if err := <-serverErr; err != nil {
+		return err
+	}
+	return clientErr
+}
+
+func TestNewServerConfig(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "server.crt")
+	keyFile := filepath.Join(dir, "server.key")
+	caFile := filepath.Join(dir, "ca.crt")
+
+	ca := newTestCert(t, 1, true, nil)
+	serverCert := newTestCert(t, 2, false, ca)
+	clientCert := newTestCert(t, 3, false, ca)
+	writeCert(t, serverCert, certFile, keyFile, time.Now())
+	require.NoError(t, os.WriteFile(caFile, ca.certPEM, 0o600))
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	t.Run("Invalid config", func(t *testing.T) {
+		t.Parallel()
+
+		reloader, err := NewReloader(certFile, keyFile, "", newTestLogger())
+		require.NoError(t, err)
+
+		for _, cfg := range []config.TLS{
+			{MinVersion: "1.1"},
+			{CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}},
+			{ClientAuth: "always"},
+			{ClientAuth: "require_and_verify"},
+		} {
+			_, err = NewServerConfig(cfg, reloader)
+			assert.Error(t, err, cfg)
+		}
+	})
+
+	t.Run("Min version", func(t *testing.T) {
+		t.Parallel()
+
+		reloader, err := NewReloader(certFile, keyFile, "", newTestLogger())
+		require.NoError(t, err)
+
+		tests := []struct {
+			minVersion    string
+			clientVersion uint16
+			wantErr       bool
+		}{
+			{minVersion: "", clientVersion: tls.VersionTLS11, wantErr: true},
+			{minVersion: "", clientVersion: tls.VersionTLS12},
+			{minVersion: "1.2", clientVersion: tls.VersionTLS12},
+			{minVersion: "1.3", clientVersion: tls.VersionTLS12, wantErr: true},
+			{minVersion: "1.3", clientVersion: tls.VersionTLS13},
+		}
+		for _, tt := range tests {
+			serverConfig, err := NewServerConfig(config.TLS{MinVersion: tt.minVersion}, reloader)
+			require.NoError(t, err)
+
+			err = handshake(serverConfig, &tls.Config{RootCAs: roots, ServerName: "localhost",
+				MinVersion: tls.VersionTLS10, MaxVersion: tt.clientVersion})
+			if tt.wantErr {
+				assert.Error(t, err, tt)
+			} else {
+				assert.NoError(t, err, tt)
+			}
+		}
+	})
+
+	t.Run("Client auth", func(t *testing.T) {
+		t.Parallel()
+
+		reloader, err := NewReloader(certFile, keyFile, caFile, newTestLogger())
+		require.NoError(t, err)
+		serverConfig, err := NewServerConfig(config.TLS{ClientAuth: "require_and_verify",
+			ClientCAFile: caFile}, reloader)
+		require.NoError(t, err)
+
+		clientConfig := &tls.Config{RootCAs: roots, ServerName: "localhost"}
+		assert.Error(t, handshake(serverConfig, clientConfig))
+
+		certificate, err := tls.X509KeyPair(clientCert.certPEM, clientCert.keyPEM)
+		require.NoError(t, err)
+		clientConfig.Certificates = []tls.Certificate{certificate}
+		assert.NoError(t, handshake(serverConfig, clientConfig))
+	})
+}
+
+func TestReloader_Run(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "server.crt")
+	keyFile := filepath.Join(dir, "server.key")
+	start := time.Now().Add(-time.Minute)
+
+	writeCert(t, newTestCert(t, 1, true, nil), certFile, keyFile, start)
+
+	_, err := NewReloader(filepath.Join(dir, "missing.crt"), keyFile, "", newTestLogger())
+	require.Error(t, err)
+
+	reloader, err := NewReloader(certFile, keyFile, "", newTestLogger())
+	require.NoError(t, err)
+
+	serial := func() int64 {
+		cert, err := reloader.GetCertificate(nil)
+		require.NoError(t, err)
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		require.NoError(t, err)
+		return leaf.SerialNumber.Int64()
+	}
+	require.Equal(t, int64(1), serial())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		reloader.Run(ctx, 10*time.Millisecond)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	writeCert(t, newTestCert(t, 2, true, nil), certFile, keyFile, start.Add(time.Second))
+	assert.Eventually(t, func() bool { return serial() == 2 }, 5*time.Second, 10*time.Millisecond)
+
+	// A failed reload keeps serving the previous certificate
+	require.NoError(t, os.WriteFile(certFile, []byte("invalid"), 0o600))
+	require.NoError(t, os.Chtimes(certFile, start.Add(2*time.Second), start.Add(2*time.Second)))
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int64(2), serial())
+}
@@ -3,67 +3,58 @@ package tracing
 import (
 	"context"
 
-	"github.com/opentracing/opentracing-go"
-	"github.com/opentracing/opentracing-go/ext"
 	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 )
 
-func StartKafkaConsumerTracerSpan(
-	ctx context.Context, headers []kafka.Header, operationName string,
-) (context.Context, opentracing.Span) {
-	carrierFromKafkaHeaders := TextMapCarrierFromKafkaMessageHeaders(headers)
-
-	spanCtx, err := opentracing.GlobalTracer().Extract(opentracing.TextMap, carrierFromKafkaHeaders)
-	if err != nil {
-		serverSpan := opentracing.GlobalTracer().StartSpan(operationName)
-		ctx = opentracing.ContextWithSpan(ctx, serverSpan)
-		return ctx, serverSpan
-	}
-
-	serverSpan := opentracing.GlobalTracer().StartSpan(operationName, ext.RPCServerOption(spanCtx))
-	ctx = opentracing.ContextWithSpan(ctx, serverSpan)
-
-	return ctx, serverSpan
+// KafkaHeadersCarrier adapts the kafka message headers to the otel propagators
+type KafkaHeadersCarrier struct {
+	Headers *[]kafka.Header
 }
 
-func TextMapCarrierToKafkaMessageHeaders(textMap opentracing.TextMapCarrier) []kafka.Header {
-	headers := make([]kafka.Header, 0, len(textMap))
-
-	if err := textMap.ForeachKey(func(key, val string) error {
-		headers = append(headers, kafka.Header{
-			Key:   key,
-			Value: []byte(val),
-		})
-		return nil
-	}); err != nil {
-		return headers
+// Get returns the value of the header
+func (c KafkaHeadersCarrier) Get(key string) string {
+	for _, header := range *c.Headers {
+		if header.Key == key {
+			return string(header.Value)
+		}
 	}
-
-	return headers
+	return ""
 }
 
-func TextMapCarrierFromKafkaMessageHeaders(headers []kafka.Header) opentracing.TextMapCarrier {
-	textMap := make(map[string]string, len(headers))
-	for _, header := range headers {
-		textMap[header.Key] = string(header.Value)
+// Set replaces the value of the header
+func (c KafkaHeadersCarrier) Set(key, value string) {
+	for i, header := range *c.Headers {
+		if header.Key == key {
+			(*c.Headers)[i].Value = []byte(value)
+			return
+		}
 	}
-	return opentracing.TextMapCarrier(textMap)
+	*c.Headers = append(*c.Headers, kafka.Header{Key: key, Value: []byte(value)})
 }
 
-func InjectTextMapCarrier(spanCtx opentracing.SpanContext) (opentracing.TextMapCarrier, error) {
-	m := make(opentracing.TextMapCarrier)
-	if err := opentracing.GlobalTracer().Inject(spanCtx, opentracing.TextMap, m); err != nil {
-		return nil, err
+// Keys returns the header keys
+func (c KafkaHeadersCarrier) Keys() []string {
+	keys := make([]string, 0, len(*c.Headers))
+	for _, header := range *c.Headers {
+		keys = append(keys, header.Key)
 	}
-	return m, nil
+	return keys
 }
 
-func GetKafkaTracingHeadersFromSpanCtx(spanCtx opentracing.SpanContext) []kafka.Header {
-	textMapCarrier, err := InjectTextMapCarrier(spanCtx)
-	if err != nil {
-		return []kafka.Header{}
-	}
+// StartKafkaConsumerTracerSpan starts the consumer span of a message,
+// continuing the trace of the producer
+func StartKafkaConsumerTracerSpan(
+	ctx context.Context, headers []kafka.Header, operationName string,
+) (context.Context, trace.Span) {
+	ctx = otel.GetTextMapPropagator().Extract(ctx, KafkaHeadersCarrier{Headers: &headers})
+	return StartSpan(ctx, operationName, trace.WithSpanKind(trace.SpanKindConsumer))
+}
 
-	kafkaMessageHeaders := TextMapCarrierToKafkaMessageHeaders(textMapCarrier)
-	return kafkaMessageHeaders
+// GetKafkaTracingHeaders returns the W3C trace context headers of the span of the context
+func GetKafkaTracingHeaders(ctx context.Context) []kafka.Header {
+	headers := []kafka.Header{}
+	otel.GetTextMapPropagator().Inject(ctx, KafkaHeadersCarrier{Headers: &headers})
+	return headers
 }
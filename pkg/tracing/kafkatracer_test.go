@@ -0,0 +1,80 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// useTestTracerProvider sets a global tracer provider that records the ended spans
+// and the W3C trace context propagation until the test ends
+func useTestTracerProvider(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	return recorder
+}
+
+func TestKafkaTracingHeaders(t *testing.T) {
+	// The test sets the global tracer provider, it does not run in parallel
+	recorder := useTestTracerProvider(t)
+
+	ctx, producerSpan := StartSpan(context.Background(), "kafkaProducer.PublishMessage")
+	headers := GetKafkaTracingHeaders(ctx)
+	producerSpan.End()
+
+	require.Len(t, headers, 1)
+	assert.Equal(t, "traceparent", headers[0].Key)
+
+	// The request id and the other headers of the message are kept
+	headers = append([]kafka.Header{{Key: RequestIDHeader, Value: []byte("request")}},
+		headers...)
+	_, consumerSpan := StartKafkaConsumerTracerSpan(context.Background(), headers,
+		"companiesConsumer.process")
+	consumerSpan.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	consumer := spans[1]
+	assert.Equal(t, trace.SpanKindConsumer, consumer.SpanKind())
+	assert.Equal(t, producerSpan.SpanContext().TraceID(), consumer.SpanContext().TraceID())
+	assert.Equal(t, producerSpan.SpanContext().SpanID(), consumer.Parent().SpanID())
+	assert.True(t, consumer.Parent().IsRemote())
+
+	// A message without trace context starts a new trace
+	_, rootSpan := StartKafkaConsumerTracerSpan(context.Background(), nil, "process")
+	rootSpan.End()
+	assert.NotEqual(t, producerSpan.SpanContext().TraceID(), rootSpan.SpanContext().TraceID())
+}
+
+func TestKafkaHeadersCarrier(t *testing.T) {
+	t.Parallel()
+
+	headers := []kafka.Header{{Key: "traceparent", Value: []byte("old")}}
+	carrier := KafkaHeadersCarrier{Headers: &headers}
+
+	carrier.Set("traceparent", "new")
+	carrier.Set("tracestate", "vendor=1")
+
+	assert.Equal(t, "new", carrier.Get("traceparent"))
+	assert.Equal(t, "vendor=1", carrier.Get("tracestate"))
+	assert.Equal(t, "", carrier.Get("baggage"))
+	assert.Equal(t, []string{"traceparent", "tracestate"}, carrier.Keys())
+	assert.Len(t, headers, 2)
+}
@@ -0,0 +1,121 @@
+package tracing
+
+import (
+	"companies-service/config"
+	"context"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// Exporters of the tracing config
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+	ExporterNone   = "none"
+)
+
+// otlpEndpointEnv is the standard variable of the otlp endpoint, it overrides the config
+const otlpEndpointEnv = "OTEL_EXPORTER_OTLP_ENDPOINT"
+
+// defaultSampleRatio samples all the new traces when the config has no ratio
+const defaultSampleRatio = 1.0
+
+// NewTracerProvider creates the tracer provider of the config and sets it as the global one,
+// with the W3C trace context and baggage propagation. The provider must be shut down
+// to flush the pending spans.
+func NewTracerProvider(ctx context.Context, cfg *config.Config) (*sdktrace.TracerProvider, error) {
+	ratio, err := sampleRatio(cfg.Tracing.SampleRatio)
+	if err != nil {
+		return nil, err
+	}
+
+	exporter, err := newExporter(ctx, cfg.Tracing)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(cfg.Tracing.ServiceName),
+		semconv.ServiceVersion(cfg.Server.AppVersion),
+		semconv.DeploymentEnvironmentName(cfg.Server.Mode),
+	))
+	if err != nil {
+		return nil, errors.Wrap(err, "resource.Merge")
+	}
+
+	options := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		// The decision of the caller is kept, the new traces are sampled with the ratio
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	}
+	if exporter != nil {
+		options = append(options, sdktrace.WithBatcher(exporter))
+	}
+
+	tracerProvider := sdktrace.NewTracerProvider(options...)
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{}))
+
+	return tracerProvider, nil
+}
+
+// sampleRatio returns the ratio of the new traces sampled, an unset ratio samples them all
+func sampleRatio(ratio float64) (float64, error) {
+	if ratio == 0 {
+		return defaultSampleRatio, nil
+	}
+	if ratio < 0 || ratio > 1 {
+		return 0, errors.Errorf("tracing: the sample ratio %v is not in (0, 1]", ratio)
+	}
+	return ratio, nil
+}
+
+// newExporter returns the span exporter of the config, nil when the spans are not exported
+func newExporter(ctx context.Context, cfg config.Tracing) (sdktrace.SpanExporter, error) {
+	switch cfg.Exporter {
+	case ExporterOTLP:
+		options := []otlptracegrpc.Option{}
+		if cfg.Endpoint != "" && os.Getenv(otlpEndpointEnv) == "" {
+			options = append(options, otlptracegrpc.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			options = append(options, otlptracegrpc.WithInsecure())
+		}
+		exporter, err := otlptracegrpc.New(ctx, options...)
+		if err != nil {
+			return nil, errors.Wrap(err, "otlptracegrpc.New")
+		}
+		return exporter, nil
+	case ExporterStdout:
+		return newStdoutExporter(os.Stdout)
+	case ExporterFile:
+		file, err := os.OpenFile(cfg.FilePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, errors.Wrap(err, "os.OpenFile")
+		}
+		return newStdoutExporter(file)
+	case ExporterNone, "":
+		return nil, nil
+	default:
+		return nil, errors.Errorf("tracing: unknown exporter %q", cfg.Exporter)
+	}
+}
+
+func newStdoutExporter(w io.Writer) (sdktrace.SpanExporter, error) {
+	exporter, err := stdouttrace.New(stdouttrace.WithWriter(w))
+	if err != nil {
+		return nil, errors.Wrap(err, "stdouttrace.New")
+	}
+	return exporter, nil
+}
@@ -0,0 +1,72 @@
+package tracing
+
+import (
+	"companies-service/config"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSampleRatio(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		ratio   float64
+		want    float64
+		wantErr bool
+	}{
+		{name: "Unset", ratio: 0, want: defaultSampleRatio},
+		{name: "Ratio", ratio: 0.25, want: 0.25},
+		{name: "All", ratio: 1, want: 1},
+		{name: "Negative", ratio: -0.5, wantErr: true},
+		{name: "Above one", ratio: 1.5, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ratio, err := sampleRatio(tt.ratio)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, ratio)
+		})
+	}
+}
+
+func TestNewTracerProvider(t *testing.T) {
+	// The provider is set as the global one, the test does not run in parallel
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	ctx := context.Background()
+
+	_, err := NewTracerProvider(ctx, &config.Config{Tracing: config.Tracing{Exporter: "jaeger"}})
+	assert.Error(t, err)
+	_, err = NewTracerProvider(ctx, &config.Config{Tracing: config.Tracing{Exporter: ExporterNone,
+		SampleRatio: 2}})
+	assert.Error(t, err)
+
+	// The new traces are sampled without a sample ratio
+	tracerProvider, err := NewTracerProvider(ctx, &config.Config{
+		Tracing: config.Tracing{Exporter: ExporterNone}})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = tracerProvider.Shutdown(ctx) })
+
+	_, span := StartSpan(ctx, "companiesService.Create")
+	defer span.End()
+	assert.True(t, span.SpanContext().IsSampled())
+
+	// The W3C trace context is propagated
+	headers := GetKafkaTracingHeaders(trace.ContextWithSpan(ctx, span))
+	assert.NotEmpty(t, KafkaHeadersCarrier{Headers: &headers}.Get("traceparent"))
+}
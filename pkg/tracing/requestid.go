@@ -0,0 +1,39 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// RequestIDHeader is the header of the request id in the http requests and the kafka messages
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDCtxKey struct{}
+
+// ContextWithRequestID returns a copy of the context carrying the request id
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDCtxKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request id of the context, empty when there is none
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDCtxKey{}).(string)
+	return requestID
+}
+
+// SetKafkaRequestIDHeader adds the request id of the context to the message headers,
+// the request id already set on the message is kept
+func SetKafkaRequestIDHeader(ctx context.Context, msg *kafka.Message) {
+	requestID := RequestIDFromContext(ctx)
+	if requestID == "" {
+		return
+	}
+
+	for _, header := range msg.Headers {
+		if header.Key == RequestIDHeader {
+			return
+		}
+	}
+	msg.Headers = append(msg.Headers, kafka.Header{Key: RequestIDHeader, Value: []byte(requestID)})
+}
@@ -0,0 +1,50 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetKafkaRequestIDHeader(t *testing.T) {
+	t.Parallel()
+
+	ctx := ContextWithRequestID(context.Background(), "request")
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		headers []kafka.Header
+		want    []kafka.Header
+	}{
+		{
+			name:    "Added",
+			ctx:     ctx,
+			headers: []kafka.Header{{Key: "traceparent", Value: []byte("parent")}},
+			want: []kafka.Header{
+				{Key: "traceparent", Value: []byte("parent")},
+				{Key: RequestIDHeader, Value: []byte("request")},
+			},
+		},
+		{
+			name:    "Kept",
+			ctx:     ctx,
+			headers: []kafka.Header{{Key: RequestIDHeader, Value: []byte("consumed")}},
+			want:    []kafka.Header{{Key: RequestIDHeader, Value: []byte("consumed")}},
+		},
+		{
+			name: "Without request id",
+			ctx:  context.Background(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := kafka.Message{Topic: "companies", Headers: tt.headers}
+			SetKafkaRequestIDHeader(tt.ctx, &msg)
+			assert.Equal(t, tt.want, msg.Headers)
+		})
+	}
+}